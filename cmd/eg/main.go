@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"mind-zero-five/internal/db"
 	"mind-zero-five/pkg/actor"
@@ -21,21 +23,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Checking a proof needs nothing but the proof, so it works offline.
+	if len(os.Args) > 3 && os.Args[1] == "event" && os.Args[2] == "proof" && os.Args[3] == "verify" {
+		verifyProof(os.Args[4:])
+		return
+	}
+
 	ctx := context.Background()
-	pool, err := db.Connect(ctx)
+	stores, err := db.Open(ctx)
 	if err != nil {
 		fatal("connect: %v", err)
 	}
-	defer pool.Close()
+	defer stores.Close()
 
-	events := eventgraph.NewPgStore(pool)
-	tasks := task.NewPgStore(pool)
-	auth := authority.NewPgStore(pool)
-	actors := actor.NewPgStore(pool)
+	events := stores.Events
+	tasks := stores.Tasks
+	auth := stores.Auth
+	actors := stores.Actors
 
 	switch os.Args[1] {
 	case "event":
-		handleEvent(ctx, events, os.Args[2:])
+		handleEvent(ctx, events, actors, os.Args[2:])
+	case "conversation":
+		handleConversation(ctx, events, os.Args[2:])
 	case "task":
 		handleTask(ctx, tasks, os.Args[2:])
 	case "authority":
@@ -46,17 +56,32 @@ func main() {
 		handlePolicy(ctx, auth, os.Args[2:])
 	case "status":
 		handleStatus(ctx, events, tasks, auth)
+	case "migrate":
+		handleMigrate(ctx, stores, os.Args[2:])
 	case "init":
-		handleInit(ctx, events, tasks, auth, actors)
+		handleInit(ctx, stores)
+	case "export":
+		handleExport(ctx, stores, os.Args[2:])
+	case "import":
+		handleImport(ctx, stores, os.Args[2:])
 	default:
 		usage()
 		os.Exit(1)
 	}
 }
 
-func handleEvent(ctx context.Context, store eventgraph.EventStore, args []string) {
+func handleEvent(ctx context.Context, store eventgraph.EventStore, actors actor.Store, args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: eg event <create|list|get|ancestors|descendants|search|types|sources|verify> [--format=short for list/search]")
+		fmt.Fprintln(os.Stderr, "Usage: eg event <create|list|get|ancestors|descendants|path|common|graph|search|types|sources|verify|audit-causes|redact|proof|root|archive|restore> [--format=short for list/search/path/common]")
+		fmt.Fprintln(os.Stderr, "       eg event list [--type=a,mind.*] [--source=a,b] [--conversation=c] [--since=T] [--until=T]")
+		fmt.Fprintln(os.Stderr, "                     [--content=path=value]... [--cursor=C] [--asc] [--limit=N]")
+		fmt.Fprintln(os.Stderr, "       eg event graph <id> [--format=dot|graphml|jsonld|json] [--depth=N] [--direction=ancestors|descendants|both]")
+		fmt.Fprintln(os.Stderr, "       eg event verify [--since | --from=<id> --to=<id>]")
+		fmt.Fprintln(os.Stderr, "       eg event proof <id> [--tree-size=N] | --old=N [--new=N] | verify <file|-> [--root=<hash>]")
+		fmt.Fprintln(os.Stderr, "       eg event redact <id> --reason=R [--source=S] [--requested-by=NAME]   (S defaults to eg, NAME to S)")
+		fmt.Fprintln(os.Stderr, "       eg event root [--tree-size=N]")
+		fmt.Fprintln(os.Stderr, "       eg event archive [--older-than=720h] [--segment-size=N] [--dir=D]")
+		fmt.Fprintln(os.Stderr, "       eg event restore [segment] [--dir=D]   (--dir defaults to $EVENT_ARCHIVE_DIR)")
 		os.Exit(1)
 	}
 
@@ -82,7 +107,11 @@ func handleEvent(ctx context.Context, store eventgraph.EventStore, args []string
 			causes = strings.Split(c, ",")
 		}
 		conversationID := flags["conversation"]
-		e, err := store.Append(ctx, eventType, source, content, causes, conversationID)
+		signer, err := sourceSigner(ctx, actors, source)
+		if err != nil {
+			fatal("%v", err)
+		}
+		e, err := store.Append(ctx, eventType, source, content, causes, conversationID, signer)
 		if err != nil {
 			fatal("create event: %v", err)
 		}
@@ -90,27 +119,21 @@ func handleEvent(ctx context.Context, store eventgraph.EventStore, args []string
 
 	case "list":
 		flags := parseFlags(args[1:])
-		limit := intFlag(flags, "limit", 20)
 		short := flags["format"] == "short"
-		var events []eventgraph.Event
-		var err error
-		if t := flags["type"]; t != "" {
-			events, err = store.ByType(ctx, t, limit)
-		} else if s := flags["source"]; s != "" {
-			events, err = store.BySource(ctx, s, limit)
-		} else if c := flags["conversation"]; c != "" {
-			events, err = store.ByConversation(ctx, c, limit)
-		} else {
-			events, err = store.Recent(ctx, limit)
+		q, err := eventQuery(args[1:], flags)
+		if err != nil {
+			fatal("list events: %v", err)
 		}
+		events, err := store.Query(ctx, q)
 		if err != nil {
 			fatal("list events: %v", err)
 		}
 		if short {
-			printShortEvents(events)
+			printShortEvents(events.Items)
 		} else {
-			printJSON(events)
+			printJSON(events.Items)
 		}
+		printCursors(events.Next, events.Prev)
 
 	case "get":
 		if len(args) < 2 {
@@ -146,28 +169,95 @@ func handleEvent(ctx context.Context, store eventgraph.EventStore, args []string
 		}
 		printJSON(events)
 
-	case "search":
+	case "path":
+		if len(args) < 3 {
+			fatal("Usage: eg event path <from-id> <to-id> [--format=short]")
+		}
+		flags := parseFlags(args[3:])
+		path, err := store.PathBetween(ctx, args[1], args[2])
+		if err != nil {
+			fatal("path: %v", err)
+		}
+		if len(path) == 0 {
+			fmt.Fprintf(os.Stderr, "%s does not descend from %s\n", args[2], args[1])
+		}
+		if flags["format"] == "short" {
+			printShortEvents(path)
+		} else {
+			printJSON(path)
+		}
+
+	case "common":
+		var ids []string
+		for _, a := range args[1:] {
+			if !strings.HasPrefix(a, "--") {
+				ids = append(ids, a)
+			}
+		}
+		if len(ids) < 2 {
+			fatal("Usage: eg event common <id> <id>... [--format=short]")
+		}
+		flags := parseFlags(args[1:])
+		events, err := store.CommonAncestors(ctx, ids...)
+		if err != nil {
+			fatal("common: %v", err)
+		}
+		if flags["format"] == "short" {
+			printShortEvents(events)
+		} else {
+			printJSON(events)
+		}
+
+	case "graph":
 		if len(args) < 2 {
-			fatal("Usage: eg event search <query> [--limit=N] [--format=short]")
+			fatal("Usage: eg event graph <id> [--format=dot|graphml|jsonld|json] [--depth=N] [--direction=ancestors|descendants|both]")
 		}
 		flags := parseFlags(args[2:])
+		format := flags["format"]
+		if format == "" {
+			format = "dot"
+		}
+		if eventgraph.GraphContentType(format) == "" {
+			fatal("graph: --format must be dot, graphml, jsonld or json")
+		}
+		g, err := eventgraph.Subgraph(ctx, store, args[1], intFlag(flags, "depth", 10), flags["direction"])
+		if err != nil {
+			fatal("graph: %v", err)
+		}
+		if err := g.Export(os.Stdout, format); err != nil {
+			fatal("graph: %v", err)
+		}
+
+	case "search":
+		if len(args) < 2 {
+			fatal(`Usage: eg event search <words, "phrases", prefix*, -excluded> [--limit=N] [--format=short]`)
+		}
+		flags := parseFlags(args[1:])
 		limit := intFlag(flags, "limit", 20)
-		events, err := store.Search(ctx, args[1], limit)
+		var words []string
+		for _, a := range args[1:] {
+			if !strings.HasPrefix(a, "--") {
+				words = append(words, a)
+			}
+		}
+		results, err := store.Search(ctx, strings.Join(words, " "), limit)
 		if err != nil {
 			fatal("search: %v", err)
 		}
 		if flags["format"] == "short" {
-			printShortEvents(events)
+			for _, r := range results {
+				fmt.Printf("%5.2f  %-35s  %s\n", r.Rank, truncStr(r.Event.Type, 35), truncStr(r.Snippet, 80))
+			}
 		} else {
-			printJSON(events)
+			printJSON(results)
 		}
 
 	case "types":
-		types, err := store.DistinctTypes(ctx)
+		seen, err := store.DistinctTypes(ctx)
 		if err != nil {
 			fatal("types: %v", err)
 		}
-		printJSON(types)
+		printJSON(eventgraph.DefaultRegistry.Describe(seen))
 
 	case "sources":
 		sources, err := store.DistinctSources(ctx)
@@ -177,20 +267,298 @@ func handleEvent(ctx context.Context, store eventgraph.EventStore, args []string
 		printJSON(sources)
 
 	case "verify":
-		err := store.VerifyChain(ctx)
+		flags := parseFlags(args[1:])
+		var report *eventgraph.VerifyReport
+		var err error
+		_, since := flags["since"]
+		if since {
+			cp, cerr := store.LatestCheckpoint(ctx)
+			if cerr != nil {
+				fatal("latest checkpoint: %v", cerr)
+			}
+			report, err = store.VerifySince(ctx, cp, actors)
+		} else {
+			report, err = store.VerifyRange(ctx, flags["from"], flags["to"], actors)
+		}
 		if err != nil {
-			fatal("chain verification failed: %v", err)
+			fatal("verify: %v", err)
+		}
+		printJSON(report)
+		if !report.OK() {
+			fatal("chain verification failed: %v", report.Err())
+		}
+		// A clean pass up to the head becomes the starting point for the next
+		// --since run.
+		if report.AtHead && (report.Checked > 0 || report.Since != nil) {
+			if _, err := saveCheckpoint(ctx, store, actors, report); err != nil {
+				fatal("save checkpoint: %v", err)
+			}
 		}
-		fmt.Println(`{"status":"ok","message":"hash chain verified"}`)
+
+	case "audit-causes":
+		// Causes are hashed, so bad references can't be rewritten; this only
+		// finds them.
+		problems, err := store.AuditCauses(ctx)
+		if err != nil {
+			fatal("audit causes: %v", err)
+		}
+		printJSON(problems)
+		if len(problems) > 0 {
+			fatal("%d bad cause references", len(problems))
+		}
+
+	case "redact":
+		if len(args) < 2 || strings.HasPrefix(args[1], "--") {
+			fatal("Usage: eg event redact <id> --reason=R [--source=S] [--requested-by=NAME]")
+		}
+		flags := parseFlags(args[2:])
+		if flags["reason"] == "" {
+			fatal("--reason is required")
+		}
+		source := flags["source"]
+		if source == "" {
+			source = "eg"
+		}
+		signer, err := sourceSigner(ctx, actors, source)
+		if err != nil {
+			fatal("%v", err)
+		}
+		audit, err := store.Redact(ctx, args[1], eventgraph.RedactRequest{
+			Source:      source,
+			Signer:      signer,
+			Reason:      flags["reason"],
+			RequestedBy: flags["requested-by"],
+		})
+		if err != nil {
+			fatal("%v", err)
+		}
+		printJSON(audit)
+
+	case "proof":
+		flags := parseFlags(args[1:])
+		if _, ok := flags["old"]; ok {
+			p, err := store.ConsistencyProof(ctx, intFlag(flags, "old", 0), intFlag(flags, "new", 0))
+			if err != nil {
+				fatal("consistency proof: %v", err)
+			}
+			printJSON(p)
+			return
+		}
+		if len(args) < 2 || strings.HasPrefix(args[1], "--") {
+			fatal("Usage: eg event proof <id> [--tree-size=N] | --old=N [--new=N] | verify <file|-> [--root=<hash>]")
+		}
+		p, err := store.InclusionProof(ctx, args[1], intFlag(flags, "tree-size", 0))
+		if err != nil {
+			fatal("inclusion proof: %v", err)
+		}
+		printJSON(p)
+
+	case "root":
+		flags := parseFlags(args[1:])
+		head, err := store.TreeHead(ctx, intFlag(flags, "tree-size", 0))
+		if err != nil {
+			fatal("tree head: %v", err)
+		}
+		printJSON(head)
+
+	case "archive":
+		flags := parseFlags(args[1:])
+		maxAge := 30 * 24 * time.Hour
+		if v := flags["older-than"]; v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				fatal("archive: --older-than: %v", err)
+			}
+			maxAge = d
+		}
+		policy := eventgraph.RetentionPolicy{MaxAge: maxAge, SegmentSize: intFlag(flags, "segment-size", 0)}
+		segs, err := eventgraph.Archive(ctx, store, archiveDir(flags), policy)
+		if segs == nil {
+			segs = []eventgraph.Segment{}
+		}
+		printJSON(segs)
+		if err != nil {
+			fatal("archive: %v", err)
+		}
+
+	case "restore":
+		flags := parseFlags(args[1:])
+		name := ""
+		if len(args) > 1 && !strings.HasPrefix(args[1], "--") {
+			name = args[1]
+		}
+		seg, err := eventgraph.Restore(ctx, store, archiveDir(flags), name)
+		if err != nil {
+			fatal("restore: %v", err)
+		}
+		printJSON(seg)
 
 	default:
 		fatal("unknown event command: %s", args[0])
 	}
 }
 
+// archiveDir is where segment files go: --dir, or $EVENT_ARCHIVE_DIR.
+func archiveDir(flags map[string]string) string {
+	dir := flags["dir"]
+	if dir == "" {
+		dir = os.Getenv("EVENT_ARCHIVE_DIR")
+	}
+	if dir == "" {
+		fatal("no archive directory: pass --dir or set EVENT_ARCHIVE_DIR")
+	}
+	return dir
+}
+
+// verifyProof checks an inclusion or consistency proof read from a file (or
+// stdin for "-"). --root (and --old-root for consistency proofs) pin the
+// proof to a tree head obtained independently; without them the proof is
+// only checked against the roots it carries.
+func verifyProof(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		fatal("Usage: eg event proof verify <file|-> [--root=<hash>] [--old-root=<hash>]")
+	}
+	flags := parseFlags(args[1:])
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fatal("read proof: %v", err)
+	}
+	var kind struct {
+		ProofType string `json:"proof_type"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		fatal("parse proof: %v", err)
+	}
+
+	var root, oldRoot string
+	switch kind.ProofType {
+	case eventgraph.ProofInclusion:
+		var p eventgraph.InclusionProof
+		if err := json.Unmarshal(data, &p); err != nil {
+			fatal("parse proof: %v", err)
+		}
+		if err := p.Verify(); err != nil {
+			fatal("proof invalid: %v", err)
+		}
+		root = p.RootHash
+	case eventgraph.ProofConsistency:
+		var p eventgraph.ConsistencyProof
+		if err := json.Unmarshal(data, &p); err != nil {
+			fatal("parse proof: %v", err)
+		}
+		if err := p.Verify(); err != nil {
+			fatal("proof invalid: %v", err)
+		}
+		root, oldRoot = p.NewRoot, p.OldRoot
+	default:
+		fatal("unknown proof type %q", kind.ProofType)
+	}
+	if want, ok := flags["root"]; ok && want != root {
+		fatal("proof invalid: root %s, want %s", root, want)
+	}
+	if want, ok := flags["old-root"]; ok && want != oldRoot {
+		fatal("proof invalid: old root %s, want %s", oldRoot, want)
+	}
+	fmt.Printf(`{"status":"ok","proof_type":%q,"root_hash":%q}`+"\n", kind.ProofType, root)
+}
+
+// saveCheckpoint records a clean verification report as a checkpoint signed
+// by the "eg" system actor.
+func saveCheckpoint(ctx context.Context, store eventgraph.EventStore, actors actor.Store, report *eventgraph.VerifyReport) (*eventgraph.Checkpoint, error) {
+	a, err := actors.Register(ctx, "system", "eg", "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := actors.GenerateKey(ctx, a.ID); err != nil {
+		return nil, err
+	}
+	signer, err := actors.Signer(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	return store.SaveCheckpoint(ctx, report, a.Name, signer)
+}
+
+// sourceSigner returns a signer for events from source when it names an actor
+// with a key, and nil (unsigned) otherwise.
+func sourceSigner(ctx context.Context, actors actor.Store, source string) (eventgraph.Signer, error) {
+	a, err := actors.ByName(ctx, source)
+	if err != nil || a.PublicKey == "" {
+		return nil, nil
+	}
+	signer, err := actors.Signer(ctx, a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("sign as %s: %w", source, err)
+	}
+	return signer, nil
+}
+
+func handleConversation(ctx context.Context, store eventgraph.EventStore, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: eg conversation <list|create|get|title>")
+		fmt.Fprintln(os.Stderr, "       eg conversation create --title=T [--id=ID] [--source=S]")
+		fmt.Fprintln(os.Stderr, "       eg conversation title <id> --title=T")
+		fmt.Fprintln(os.Stderr, "  (its events: eg event list --conversation=<id>)")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		flags := parseFlags(args[1:])
+		convs, err := store.Conversations(ctx, intFlag(flags, "limit", 50))
+		if err != nil {
+			fatal("list conversations: %v", err)
+		}
+		printJSON(convs)
+
+	case "create":
+		flags := parseFlags(args[1:])
+		source := flags["source"]
+		if source == "" {
+			source = "mind"
+		}
+		c, err := store.CreateConversation(ctx, flags["id"], flags["title"], source)
+		if err != nil {
+			fatal("create conversation: %v", err)
+		}
+		printJSON(c)
+
+	case "get":
+		if len(args) < 2 {
+			fatal("Usage: eg conversation get <id>")
+		}
+		c, err := store.GetConversation(ctx, args[1])
+		if err != nil {
+			fatal("get conversation: %v", err)
+		}
+		printJSON(c)
+
+	case "title":
+		flags := parseFlags(args[1:])
+		if len(args) < 2 || strings.HasPrefix(args[1], "--") {
+			fatal("Usage: eg conversation title <id> --title=T")
+		}
+		c, err := store.RetitleConversation(ctx, args[1], flags["title"])
+		if err != nil {
+			fatal("retitle conversation: %v", err)
+		}
+		printJSON(c)
+
+	default:
+		fatal("unknown conversation command: %s", args[0])
+	}
+}
+
 func handleTask(ctx context.Context, store task.Store, args []string) {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: eg task <create|list|get|update|complete> [--format=short for list]")
+		fmt.Fprintln(os.Stderr, "       eg task list [--status=S] [--cursor=C] [--limit=N]")
 		os.Exit(1)
 	}
 
@@ -221,15 +589,16 @@ func handleTask(ctx context.Context, store task.Store, args []string) {
 		flags := parseFlags(args[1:])
 		status := flags["status"]
 		limit := intFlag(flags, "limit", 20)
-		tasks, err := store.List(ctx, status, limit)
+		tasks, err := store.ListPage(ctx, status, flags["cursor"], limit)
 		if err != nil {
 			fatal("list tasks: %v", err)
 		}
 		if flags["format"] == "short" {
-			printShortTasks(tasks)
+			printShortTasks(tasks.Items)
 		} else {
-			printJSON(tasks)
+			printJSON(tasks.Items)
 		}
+		printCursors(tasks.Next, tasks.Prev)
 
 	case "get":
 		if len(args) < 2 {
@@ -290,6 +659,7 @@ func handleTask(ctx context.Context, store task.Store, args []string) {
 func handleAuthority(ctx context.Context, store authority.Store, args []string) {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: eg authority <request|list|check|resolve>")
+		fmt.Fprintln(os.Stderr, "       eg authority list [--status=pending | --cursor=C] [--limit=N]")
 		os.Exit(1)
 	}
 
@@ -326,11 +696,12 @@ func handleAuthority(ctx context.Context, store authority.Store, args []string)
 			printJSON(reqs)
 		} else {
 			limit := intFlag(flags, "limit", 20)
-			reqs, err := store.Recent(ctx, limit)
+			reqs, err := store.RecentPage(ctx, flags["cursor"], limit)
 			if err != nil {
 				fatal("list recent: %v", err)
 			}
-			printJSON(reqs)
+			printJSON(reqs.Items)
+			printCursors(reqs.Next, reqs.Prev)
 		}
 
 	case "check":
@@ -384,7 +755,7 @@ func handleStatus(ctx context.Context, events eventgraph.EventStore, tasks task.
 
 func handleActor(ctx context.Context, store actor.Store, args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: eg actor <list|register|get>")
+		fmt.Fprintln(os.Stderr, "Usage: eg actor <list|register|get|keygen>")
 		os.Exit(1)
 	}
 
@@ -420,6 +791,16 @@ func handleActor(ctx context.Context, store actor.Store, args []string) {
 		}
 		printJSON(a)
 
+	case "keygen":
+		if len(args) < 2 {
+			fatal("Usage: eg actor keygen <id>")
+		}
+		a, err := store.GenerateKey(ctx, args[1])
+		if err != nil {
+			fatal("generate key: %v", err)
+		}
+		printJSON(a)
+
 	default:
 		fatal("unknown actor command: %s", args[0])
 	}
@@ -471,22 +852,124 @@ func handlePolicy(ctx context.Context, store authority.Store, args []string) {
 	}
 }
 
-func handleInit(ctx context.Context, events eventgraph.EventStore, tasks task.Store, auth authority.Store, actors actor.Store) {
-	if err := events.EnsureTable(ctx); err != nil {
-		fatal("ensure events table: %v", err)
-	}
-	if err := tasks.EnsureTable(ctx); err != nil {
-		fatal("ensure tasks table: %v", err)
+func handleMigrate(ctx context.Context, stores *db.Stores, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: eg migrate <status|up> [--format=short for status]")
+		os.Exit(1)
 	}
-	if err := auth.EnsureTable(ctx); err != nil {
-		fatal("ensure authority table: %v", err)
+
+	runner := stores.Migrations()
+	switch args[0] {
+	case "status":
+		flags := parseFlags(args[1:])
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			fatal("migration status: %v", err)
+		}
+		if flags["format"] == "short" {
+			for _, st := range statuses {
+				applied := ""
+				if st.AppliedAt != nil {
+					applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%04d  %-20s  %-8s  %s\n", st.Version, truncStr(st.Name, 20), st.State, applied)
+			}
+			return
+		}
+		printJSON(statuses)
+
+	case "up":
+		ran, err := runner.Up(ctx)
+		if err != nil {
+			fatal("migrate: %v", err)
+		}
+		applied := make([]string, len(ran))
+		for i, m := range ran {
+			applied[i] = fmt.Sprintf("%04d_%s", m.Version, m.Name)
+		}
+		printJSON(map[string]any{"status": "ok", "applied": applied})
+
+	default:
+		fatal("unknown migrate command: %s", args[0])
 	}
-	if err := actors.EnsureTable(ctx); err != nil {
-		fatal("ensure actors table: %v", err)
+}
+
+func handleInit(ctx context.Context, stores *db.Stores) {
+	if err := stores.Migrate(ctx); err != nil {
+		fatal("%v", err)
 	}
 	fmt.Println(`{"status":"ok","message":"all tables initialized"}`)
 }
 
+// handleExport writes a bundle of everything in the database to stdout, or
+// to --out. Private keys are only included with --with-keys.
+func handleExport(ctx context.Context, stores *db.Stores, args []string) {
+	flags := parseFlags(args)
+	_, withKeys := flags["with-keys"]
+	b, err := stores.Export(ctx, withKeys)
+	if err != nil {
+		fatal("%v", err)
+	}
+	out := flags["out"]
+	if out == "" {
+		printJSON(b)
+		return
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		fatal("export: %v", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		f.Close()
+		fatal("export: write %s: %v", out, err)
+	}
+	if err := f.Close(); err != nil {
+		fatal("export: write %s: %v", out, err)
+	}
+	printJSON(bundleSummary(b, out))
+}
+
+// handleImport replays a bundle from a file (or stdin for "-") into an empty
+// database.
+func handleImport(ctx context.Context, stores *db.Stores, args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		fatal("Usage: eg import <file|->")
+	}
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fatal("read bundle: %v", err)
+	}
+	var b db.Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		fatal("parse bundle: %v", err)
+	}
+	if err := stores.Import(ctx, &b); err != nil {
+		fatal("%v", err)
+	}
+	printJSON(bundleSummary(&b, args[0]))
+}
+
+func bundleSummary(b *db.Bundle, file string) map[string]any {
+	return map[string]any{
+		"status":            "ok",
+		"file":              file,
+		"actors":            len(b.Actors),
+		"policies":          len(b.Policies),
+		"approval_requests": len(b.ApprovalRequests),
+		"tasks":             len(b.Tasks),
+		"conversations":     len(b.Conversations),
+		"events":            len(b.Events),
+	}
+}
+
 // parseFlags parses --key=value and --flag style args into a map.
 func parseFlags(args []string) map[string]string {
 	flags := make(map[string]string)
@@ -504,6 +987,60 @@ func parseFlags(args []string) map[string]string {
 	return flags
 }
 
+// eventQuery builds an event query from list flags. Comma-separated types,
+// sources and conversations may each match; --content may be repeated and
+// takes path=value or path!=value; times are RFC 3339 or a duration ago.
+func eventQuery(args []string, flags map[string]string) (eventgraph.Query, error) {
+	q := eventgraph.Query{
+		Types:         listFlag(flags["type"]),
+		Sources:       listFlag(flags["source"]),
+		Conversations: listFlag(flags["conversation"]),
+		Cursor:        flags["cursor"],
+		Limit:         intFlag(flags, "limit", 20),
+	}
+	_, q.Ascending = flags["asc"]
+	var err error
+	if v := flags["since"]; v != "" {
+		if q.Since, err = eventgraph.ParseQueryTime(v); err != nil {
+			return q, err
+		}
+	}
+	if v := flags["until"]; v != "" {
+		if q.Until, err = eventgraph.ParseQueryTime(v); err != nil {
+			return q, err
+		}
+	}
+	// parseFlags keeps only the last --content, so collect them all here.
+	for _, arg := range args {
+		if v, ok := strings.CutPrefix(arg, "--content="); ok {
+			m, err := eventgraph.ParseContentMatch(v)
+			if err != nil {
+				return q, err
+			}
+			q.Content = append(q.Content, m)
+		}
+	}
+	return q, nil
+}
+
+// printCursors tells the user how to reach the neighbouring pages of a
+// listing. It writes to stderr so the listing itself stays parseable.
+func printCursors(next, prev string) {
+	if next != "" {
+		fmt.Fprintf(os.Stderr, "next page: --cursor=%s\n", next)
+	}
+	if prev != "" {
+		fmt.Fprintf(os.Stderr, "prev page: --cursor=%s\n", prev)
+	}
+}
+
+func listFlag(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
 func intFlag(flags map[string]string, key string, defaultVal int) int {
 	if v, ok := flags[key]; ok && v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -558,11 +1095,15 @@ func usage() {
 	fmt.Fprintln(os.Stderr, `Usage: eg <command>
 
 Commands:
-  event      Event operations (create, list, get, ancestors, descendants, search, types, sources, verify)
-  task       Task operations (create, list, get, update, complete)
-  authority  Authority operations (request, list, check, resolve)
-  actor      Actor operations (list, register, get)
-  policy     Policy operations (list, create, match)
-  status     Show system summary
-  init       Initialize database tables`)
+  event         Event operations (create, list, get, ancestors, descendants, path, common, graph, search, types, sources, verify, audit-causes, redact, proof, root, archive, restore)
+  conversation  Conversation operations (list, create, get, title)
+  task          Task operations (create, list, get, update, complete)
+  authority     Authority operations (request, list, check, resolve)
+  actor         Actor operations (list, register, get, keygen)
+  policy        Policy operations (list, create, match)
+  status        Show system summary
+  migrate       Schema migrations (status, up)
+  init          Initialize database tables (same as migrate up)
+  export        Write everything to a bundle (export [--out=file] [--with-keys])
+  import        Replay a bundle into an empty database (import <file|->)`)
 }
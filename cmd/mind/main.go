@@ -6,54 +6,56 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"mind-zero-five/internal/db"
-	"mind-zero-five/pkg/actor"
-	"mind-zero-five/pkg/authority"
-	"mind-zero-five/pkg/eventgraph"
 	"mind-zero-five/pkg/mind"
-	"mind-zero-five/pkg/task"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	pool, err := db.Connect(ctx)
+	stores, err := db.Open(ctx)
 	if err != nil {
 		log.Fatalf("connect: %v", err)
 	}
-	defer pool.Close()
+	defer stores.Close()
 
-	events := eventgraph.NewPgStore(pool)
-	tasks := task.NewPgStore(pool)
-	auth := authority.NewPgStore(pool)
-	actors := actor.NewPgStore(pool)
+	tasks := stores.Tasks
+	auth := stores.Auth
+	actors := stores.Actors
 
-	// Wait for tables to be ready (web server creates them).
-	// Retry for up to 30 seconds on startup.
-	var mindActor *actor.Actor
-	for i := 0; i < 30; i++ {
-		mindActor, err = actors.Register(ctx, "mind", "mind", "")
-		if err == nil {
-			break
-		}
-		log.Printf("mind: waiting for tables (attempt %d/30): %v", i+1, err)
-		time.Sleep(time.Second)
+	// Bring the schema up to date. The web server does the same on startup;
+	// the migration lock makes whichever starts second a no-op.
+	if err := stores.Migrate(ctx); err != nil {
+		log.Fatalf("%v", err)
 	}
+
+	mindActor, err := actors.Register(ctx, "mind", "mind", "")
 	if err != nil {
 		log.Fatalf("register mind actor: %v", err)
 	}
 	log.Printf("mind actor: %s", mindActor.ID)
 
+	// Sign everything the mind emits. The server may have generated the key
+	// already; GenerateKey keeps an existing one.
+	if _, err := actors.GenerateKey(ctx, mindActor.ID); err != nil {
+		log.Fatalf("mind key: %v", err)
+	}
+	signer, err := actors.Signer(ctx, mindActor.ID)
+	if err != nil {
+		log.Fatalf("mind key: %v", err)
+	}
+
 	repoDir := os.Getenv("MIND_REPO_DIR")
 	if repoDir == "" {
 		repoDir = "/data/source"
 	}
 
-	bus := eventgraph.NewBus(events)
-	m := mind.New(bus, tasks, auth, mindActor.ID, repoDir)
+	// On Postgres this hears task.created from the server process directly,
+	// instead of waiting for the next maintenance tick.
+	bus := stores.Bus(ctx)
+	m := mind.New(bus, tasks, auth, mindActor.ID, signer, repoDir)
 
 	// Signal handling
 	go func() {
@@ -12,38 +12,27 @@ import (
 	"mind-zero-five/internal/db"
 	"mind-zero-five/pkg/actor"
 	"mind-zero-five/pkg/authority"
-	"mind-zero-five/pkg/eventgraph"
 	"mind-zero-five/pkg/mind"
-	"mind-zero-five/pkg/task"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	pool, err := db.Connect(ctx)
+	stores, err := db.Open(ctx)
 	if err != nil {
 		log.Fatalf("connect: %v", err)
 	}
-	defer pool.Close()
+	defer stores.Close()
 
-	events := eventgraph.NewPgStore(pool)
-	tasks := task.NewPgStore(pool)
-	auth := authority.NewPgStore(pool)
-	actors := actor.NewPgStore(pool)
+	tasks := stores.Tasks
+	auth := stores.Auth
+	actors := stores.Actors
 
-	// Ensure tables exist
-	if err := events.EnsureTable(ctx); err != nil {
-		log.Fatalf("ensure events table: %v", err)
-	}
-	if err := tasks.EnsureTable(ctx); err != nil {
-		log.Fatalf("ensure tasks table: %v", err)
-	}
-	if err := auth.EnsureTable(ctx); err != nil {
-		log.Fatalf("ensure authority table: %v", err)
-	}
-	if err := actors.EnsureTable(ctx); err != nil {
-		log.Fatalf("ensure actors table: %v", err)
+	// Bring the schema up to date. The mind process does the same on startup;
+	// the migration lock makes whichever starts second a no-op.
+	if err := stores.Migrate(ctx); err != nil {
+		log.Fatalf("%v", err)
 	}
 
 	// Register core actors
@@ -55,7 +44,21 @@ func main() {
 	if err != nil {
 		log.Fatalf("register mind actor: %v", err)
 	}
-	log.Printf("actors: matt=%s mind=%s", mattActor.ID, mindActor.ID)
+	apiActor, err := actors.Register(ctx, "system", "api", "")
+	if err != nil {
+		log.Fatalf("register api actor: %v", err)
+	}
+	log.Printf("actors: matt=%s mind=%s api=%s", mattActor.ID, mindActor.ID, apiActor.ID)
+
+	// The mind and the API sign the events they emit
+	mindSigner, err := keySigner(ctx, actors, mindActor.ID)
+	if err != nil {
+		log.Fatalf("mind key: %v", err)
+	}
+	apiSigner, err := keySigner(ctx, actors, apiActor.ID)
+	if err != nil {
+		log.Fatalf("api key: %v", err)
+	}
 
 	// Seed default policies (idempotent — upserts on action)
 	if _, err := auth.CreatePolicy(ctx, "restart", mindActor.ID, authority.Notification); err != nil {
@@ -68,18 +71,19 @@ func main() {
 		log.Fatalf("seed default policy: %v", err)
 	}
 
-	// Wrap EventStore in Bus for in-process event subscription
-	bus := eventgraph.NewBus(events)
+	// Wrap EventStore in a bus for event subscription; on Postgres it also
+	// carries events appended by other processes (eg, a separate mind)
+	bus := stores.Bus(ctx)
 
 	// API server uses Bus (satisfies EventStore interface) so events flow through it
-	server := api.New(bus, tasks, auth)
+	server := api.New(bus, tasks, auth, actors, apiSigner, stores)
 
 	// Mind runs in-process, sharing the Bus for event-driven wake-ups
 	repoDir := os.Getenv("MIND_REPO_DIR")
 	if repoDir == "" {
 		repoDir = "/data/source"
 	}
-	m := mind.New(bus, tasks, auth, mindActor.ID, repoDir)
+	m := mind.New(bus, tasks, auth, mindActor.ID, mindSigner, repoDir)
 	go m.Run(ctx)
 
 	// Signal handling for graceful shutdown
@@ -101,3 +105,11 @@ func main() {
 		log.Fatalf("listen: %v", err)
 	}
 }
+
+// keySigner gives an actor a keypair if it lacks one and returns its signer.
+func keySigner(ctx context.Context, actors actor.Store, id string) (*actor.Signer, error) {
+	if _, err := actors.GenerateKey(ctx, id); err != nil {
+		return nil, err
+	}
+	return actors.Signer(ctx, id)
+}
@@ -67,10 +67,11 @@ type UI struct {
 	rejectBtn    []widget.Clickable
 
 	// Chat
-	chatList      widget.List
-	chatMessages  []ChatMessage
-	chatEditor    widget.Editor
-	chatSendBtn   widget.Clickable
+	chatList         widget.List
+	chatMessages     []ChatMessage
+	chatEditor       widget.Editor
+	chatSendBtn      widget.Clickable
+	chatConversation string // created by the first message sent
 }
 
 type Status struct {
@@ -481,31 +482,38 @@ func (ui *UI) fetchStatus() {
 	ui.status = s
 }
 
+// listPage is one page of a listing, as the API returns it.
+type listPage[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next"`
+	Prev  string `json:"prev"`
+}
+
 func (ui *UI) fetchEvents() {
-	var events []Event
+	var events listPage[Event]
 	if err := httpGetJSON(apiBase+"api/events?limit=100", &events); err != nil {
 		log.Printf("fetch events: %v", err)
 		return
 	}
-	ui.events = events
+	ui.events = events.Items
 }
 
 func (ui *UI) fetchTasks() {
-	var tasks []Task
+	var tasks listPage[Task]
 	if err := httpGetJSON(apiBase+"api/tasks?limit=100", &tasks); err != nil {
 		log.Printf("fetch tasks: %v", err)
 		return
 	}
-	ui.tasks = tasks
+	ui.tasks = tasks.Items
 }
 
 func (ui *UI) fetchAuthority() {
-	var reqs []AuthRequest
+	var reqs listPage[AuthRequest]
 	if err := httpGetJSON(apiBase+"api/authority?limit=50", &reqs); err != nil {
 		log.Printf("fetch authority: %v", err)
 		return
 	}
-	ui.authRequests = reqs
+	ui.authRequests = reqs.Items
 }
 
 func (ui *UI) createTask(subject string) {
@@ -536,9 +544,25 @@ func (ui *UI) sendChat(msg string) {
 		Content: msg,
 		Time:    time.Now(),
 	})
+	// The session's messages share one conversation, named after the first.
+	if ui.chatConversation == "" {
+		var conv struct {
+			ID string `json:"id"`
+		}
+		title := msg
+		if r := []rune(title); len(r) > 60 {
+			title = string(r[:60]) + "..."
+		}
+		body := fmt.Sprintf(`{"title":%q,"created_by":"ui"}`, title)
+		if err := httpPostJSON(apiBase+"api/conversations", body, &conv); err != nil {
+			log.Printf("start chat conversation: %v", err)
+			return
+		}
+		ui.chatConversation = conv.ID
+	}
 	// Create a signal.human event
 	body := fmt.Sprintf(`{"type":"signal.human","source":"ui","content":{"message":%q}}`, msg)
-	resp, err := http.Post(apiBase+"api/events", "application/json", strings.NewReader(body))
+	resp, err := http.Post(apiBase+"api/conversations/"+ui.chatConversation+"/events", "application/json", strings.NewReader(body))
 	if err != nil {
 		log.Printf("send chat: %v", err)
 		return
@@ -546,6 +570,22 @@ func (ui *UI) sendChat(msg string) {
 	resp.Body.Close()
 }
 
+func httpPostJSON(url, body string, v any) error {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
+
 func httpGetJSON(url string, v any) error {
 	resp, err := http.Get(url)
 	if err != nil {
@@ -2,6 +2,11 @@ package api
 
 import (
 	"net/http"
+
+	"mind-zero-five/internal/db"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/payload"
 )
 
 func (s *Server) handleAuthorityList(w http.ResponseWriter, r *http.Request) {
@@ -16,12 +21,12 @@ func (s *Server) handleAuthorityList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	limit := queryInt(r, "limit", 50)
-	reqs, err := s.auth.Recent(ctx, limit)
+	reqs, err := s.auth.RecentPage(ctx, r.URL.Query().Get("cursor"), limit)
 	if err != nil {
-		writeError(w, 500, err.Error())
+		writeError(w, pageStatus(err), err.Error())
 		return
 	}
-	writeJSON(w, 200, reqs)
+	writePage(w, r, reqs)
 }
 
 func (s *Server) handleAuthorityGet(w http.ResponseWriter, r *http.Request) {
@@ -37,17 +42,25 @@ func (s *Server) handleAuthorityGet(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleAuthorityResolve(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	approved := r.URL.Query().Get("approved") == "true"
-	req, err := s.auth.Resolve(r.Context(), id, approved)
+	// Resolve and emit authority.resolved together, so the mind is always
+	// told about a decision that took effect.
+	var req *authority.Request
+	err := s.uow.InTx(r.Context(), func(tx *db.Tx) error {
+		var err error
+		if req, err = tx.Auth.Resolve(r.Context(), id, approved); err != nil {
+			return err
+		}
+		_, err = payload.Append(r.Context(), tx.Events, "api", payload.AuthorityResolved{
+			AuthorityID: req.ID,
+			Action:      req.Action,
+			Approved:    approved,
+		}, nil, "", s.signer)
+		return err
+	})
 	if err != nil {
 		writeError(w, 500, err.Error())
 		return
 	}
-	// Emit authority.resolved event so the mind can react
-	s.events.Append(r.Context(), "authority.resolved", "api", map[string]any{
-		"authority_id": req.ID,
-		"action":       req.Action,
-		"approved":     approved,
-	}, nil, "")
 	writeJSON(w, 200, req)
 }
 
@@ -62,10 +75,17 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	pendingTasks, _ := s.tasks.PendingCount(ctx)
 	pendingAuth, _ := s.auth.PendingCount(ctx)
 
-	writeJSON(w, 200, map[string]any{
+	status := map[string]any{
 		"events":            eventCount,
 		"tasks":             taskCount,
 		"pending_tasks":     pendingTasks,
 		"pending_approvals": pendingAuth,
-	})
+	}
+	if bus, ok := s.events.(eventgraph.Subscriber); ok {
+		status["bus"] = bus.Stats()
+	}
+	if consumers, err := s.events.Consumers(ctx); err == nil {
+		status["consumers"] = consumers
+	}
+	writeJSON(w, 200, status)
 }
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"mind-zero-five/pkg/eventgraph"
+)
+
+func (s *Server) handleConversationList(w http.ResponseWriter, r *http.Request) {
+	convs, err := s.events.ConversationsPage(r.Context(), r.URL.Query().Get("cursor"), queryInt(r, "limit", 50))
+	if err != nil {
+		writeError(w, pageStatus(err), err.Error())
+		return
+	}
+	writePage(w, r, convs)
+}
+
+func (s *Server) handleConversationCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		CreatedBy string `json:"created_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = "api"
+	}
+	if !s.canActFor(w, r, req.CreatedBy) {
+		return
+	}
+	c, err := s.events.CreateConversation(r.Context(), req.ID, req.Title, req.CreatedBy)
+	if errors.Is(err, eventgraph.ErrConversationExists) {
+		writeError(w, 409, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, 201, c)
+}
+
+func (s *Server) handleConversationGet(w http.ResponseWriter, r *http.Request) {
+	c, err := s.events.GetConversation(r.Context(), r.PathValue("id"))
+	writeConversation(w, c, err)
+}
+
+// handleConversationUpdate retitles a conversation; the title is all there
+// is to change.
+func (s *Server) handleConversationUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title *string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Title == nil {
+		writeError(w, 400, "title is required")
+		return
+	}
+	c, err := s.events.RetitleConversation(r.Context(), r.PathValue("id"), *req.Title)
+	writeConversation(w, c, err)
+}
+
+// handleConversationEvents pages through a conversation's events, oldest
+// first unless order=desc. It takes the same filters as /api/events.
+func (s *Server) handleConversationEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.events.GetConversation(r.Context(), id); err != nil {
+		writeConversation(w, nil, err)
+		return
+	}
+	q, err := eventQuery(r)
+	if err != nil {
+		writeError(w, 400, err.Error())
+		return
+	}
+	q.Conversations = []string{id}
+	if r.URL.Query().Get("order") == "" {
+		q.Ascending = true
+	}
+	events, err := s.events.Query(r.Context(), q)
+	if errors.Is(err, eventgraph.ErrInvalidQuery) {
+		writeError(w, 400, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	writePage(w, r, events)
+}
+
+func writeConversation(w http.ResponseWriter, c *eventgraph.Conversation, err error) {
+	switch {
+	case errors.Is(err, eventgraph.ErrNotFound):
+		writeError(w, 404, err.Error())
+	case err != nil:
+		writeError(w, 500, err.Error())
+	default:
+		writeJSON(w, 200, c)
+	}
+}
@@ -2,51 +2,97 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"mind-zero-five/internal/db"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/payload"
 )
 
 func (s *Server) handleEventList(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	limit := queryInt(r, "limit", 50)
+	q, err := eventQuery(r)
+	if err != nil {
+		writeError(w, 400, err.Error())
+		return
+	}
+	events, err := s.events.Query(r.Context(), q)
+	if errors.Is(err, eventgraph.ErrInvalidQuery) {
+		writeError(w, 400, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	writePage(w, r, events)
+}
 
-	if t := r.URL.Query().Get("type"); t != "" {
-		events, err := s.events.ByType(ctx, t, limit)
-		if err != nil {
-			writeError(w, 500, err.Error())
-			return
+// eventQuery builds a Query from list parameters: type, source and
+// conversation (repeated or comma-separated, any may match), since and until
+// (RFC 3339 or a duration ago), content.<path>=<value> or
+// content.<path>!=<value>, cursor, order=asc|desc and limit.
+// Conversation listings read oldest first unless an order is given.
+func eventQuery(r *http.Request) (eventgraph.Query, error) {
+	params := r.URL.Query()
+	q := eventgraph.Query{
+		Types:         listParam(params["type"]),
+		Sources:       listParam(params["source"]),
+		Conversations: listParam(params["conversation"]),
+		Cursor:        params.Get("cursor"),
+		Limit:         queryInt(r, "limit", 50),
+	}
+	switch params.Get("order") {
+	case "asc":
+		q.Ascending = true
+	case "desc":
+	case "":
+		q.Ascending = len(q.Conversations) > 0
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+	var err error
+	if v := params.Get("since"); v != "" {
+		if q.Since, err = eventgraph.ParseQueryTime(v); err != nil {
+			return q, err
 		}
-		writeJSON(w, 200, events)
-		return
 	}
-	if src := r.URL.Query().Get("source"); src != "" {
-		events, err := s.events.BySource(ctx, src, limit)
-		if err != nil {
-			writeError(w, 500, err.Error())
-			return
+	if v := params.Get("until"); v != "" {
+		if q.Until, err = eventgraph.ParseQueryTime(v); err != nil {
+			return q, err
 		}
-		writeJSON(w, 200, events)
-		return
 	}
-	if conv := r.URL.Query().Get("conversation"); conv != "" {
-		events, err := s.events.ByConversation(ctx, conv, limit)
-		if err != nil {
-			writeError(w, 500, err.Error())
-			return
+	for key, values := range params {
+		path, ok := strings.CutPrefix(key, "content.")
+		if !ok {
+			continue
+		}
+		// content.x!=y arrives as key "content.x!" and value "y".
+		path, not := strings.CutSuffix(path, "!")
+		for _, v := range values {
+			q.Content = append(q.Content, eventgraph.ContentMatch{Path: path, Value: v, Not: not})
 		}
-		writeJSON(w, 200, events)
-		return
 	}
+	return q, nil
+}
 
-	events, err := s.events.Recent(ctx, limit)
-	if err != nil {
-		writeError(w, 500, err.Error())
-		return
+// listParam splits repeated and comma-separated values.
+func listParam(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
 	}
-	writeJSON(w, 200, events)
+	return out
 }
 
 func (s *Server) handleEventCreate(w http.ResponseWriter, r *http.Request) {
@@ -68,7 +114,27 @@ func (s *Server) handleEventCreate(w http.ResponseWriter, r *http.Request) {
 	if req.Source == "" {
 		req.Source = "api"
 	}
-	e, err := s.events.Append(r.Context(), req.Type, req.Source, req.Content, req.Causes, req.ConversationID)
+	// Posted to /api/conversations/{id}/events: the path names the
+	// conversation, which must exist.
+	if id := r.PathValue("id"); id != "" {
+		if _, err := s.events.GetConversation(r.Context(), id); err != nil {
+			writeConversation(w, nil, err)
+			return
+		}
+		req.ConversationID = id
+	}
+	if !s.canActFor(w, r, req.Source) {
+		return
+	}
+	var signer eventgraph.Signer
+	if req.Source == "api" {
+		signer = s.signer
+	}
+	e, err := s.events.Append(r.Context(), req.Type, req.Source, req.Content, req.Causes, req.ConversationID, signer)
+	if errors.Is(err, eventgraph.ErrSchema) || errors.Is(err, eventgraph.ErrInvalidCause) {
+		writeError(w, 400, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, 500, err.Error())
 		return
@@ -76,6 +142,124 @@ func (s *Server) handleEventCreate(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 201, e)
 }
 
+// canActFor reports whether the API may append events or create
+// conversations as source, writing the error response if not. The API signs
+// as itself. It can't sign for other actors, so it refuses any source whose
+// events must be signed (e.g. "mind").
+func (s *Server) canActFor(w http.ResponseWriter, r *http.Request, source string) bool {
+	if source == "api" {
+		return true
+	}
+	key, _, err := s.actors.PublicKey(r.Context(), source)
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return false
+	}
+	if key != nil {
+		writeError(w, 403, "source "+source+" signs its own events; the API cannot act on its behalf")
+		return false
+	}
+	return true
+}
+
+// redactAction is the authority action that approves a redaction.
+const redactAction = "event.redact"
+
+// handleEventRedact replaces an event's content with a tombstone. That can't
+// be undone, so it takes two calls. The first, with a reason and who is
+// asking, files a Required authority request and returns it. Once a human
+// approves it, the same call with its approval_id redacts the event; the
+// event.redacted event comes from the API, signed as itself, and records
+// the requester and the approval.
+func (s *Server) handleEventRedact(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason      string `json:"reason"`
+		RequestedBy string `json:"requested_by"`
+		ApprovalID  string `json:"approval_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "invalid JSON: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		writeError(w, 400, "reason is required")
+		return
+	}
+	if strings.TrimSpace(req.RequestedBy) == "" {
+		writeError(w, 400, "requested_by is required")
+		return
+	}
+	id := r.PathValue("id")
+	// The approval covers exactly this event, reason and requester.
+	description := fmt.Sprintf("Redact event %s: %s", id, req.Reason)
+	if req.ApprovalID == "" {
+		s.requestRedaction(w, r, id, description, req.RequestedBy)
+		return
+	}
+
+	approval, err := s.auth.Get(r.Context(), req.ApprovalID)
+	if err != nil {
+		writeError(w, 404, err.Error())
+		return
+	}
+	if approval.Action != redactAction || approval.Description != description || approval.Source != req.RequestedBy {
+		writeError(w, 403, "authority request "+approval.ID+" doesn't approve this redaction")
+		return
+	}
+	if approval.Status != "approved" {
+		writeError(w, 403, "authority request "+approval.ID+" is "+approval.Status)
+		return
+	}
+	audit, err := s.events.Redact(r.Context(), id, eventgraph.RedactRequest{
+		Source:      "api",
+		Signer:      s.signer,
+		Reason:      req.Reason,
+		RequestedBy: approval.Source,
+		ApprovalID:  approval.ID,
+	})
+	switch {
+	case errors.Is(err, eventgraph.ErrNotFound):
+		writeError(w, 404, err.Error())
+	case errors.Is(err, eventgraph.ErrNotRedactable):
+		writeError(w, 409, err.Error())
+	case err != nil:
+		writeError(w, 500, err.Error())
+	default:
+		writeJSON(w, 201, audit)
+	}
+}
+
+// requestRedaction files the authority request a redaction needs, with an
+// authority.requested event citing the event to redact, and returns it.
+func (s *Server) requestRedaction(w http.ResponseWriter, r *http.Request, id, description, requestedBy string) {
+	e, err := s.events.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, 404, err.Error())
+		return
+	}
+	if e.Redacted() {
+		writeError(w, 409, "event "+id+" is already redacted")
+		return
+	}
+	var approval *authority.Request
+	err = s.uow.InTx(r.Context(), func(tx *db.Tx) error {
+		var err error
+		if approval, err = tx.Auth.Create(r.Context(), redactAction, description, requestedBy, authority.Required); err != nil {
+			return err
+		}
+		_, err = payload.Append(r.Context(), tx.Events, "api", payload.AuthorityRequested{
+			AuthorityID: approval.ID,
+			Action:      redactAction,
+		}, []string{id}, "", s.signer)
+		return err
+	})
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, 202, approval)
+}
+
 func (s *Server) handleEventGet(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	e, err := s.events.Get(r.Context(), id)
@@ -108,13 +292,97 @@ func (s *Server) handleEventDescendants(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, 200, events)
 }
 
+// handleEventGraph exports the causal subgraph around an event. format is
+// json (default), dot, graphml or jsonld; direction is ancestors,
+// descendants or both (default).
+func (s *Server) handleEventGraph(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	contentType := eventgraph.GraphContentType(format)
+	if contentType == "" {
+		writeError(w, 400, "format must be json, dot, graphml or jsonld")
+		return
+	}
+	g, err := eventgraph.Subgraph(r.Context(), s.events, r.PathValue("id"), queryInt(r, "depth", 10), r.URL.Query().Get("direction"))
+	switch {
+	case errors.Is(err, eventgraph.ErrNotFound):
+		writeError(w, 404, err.Error())
+		return
+	case errors.Is(err, eventgraph.ErrInvalidQuery):
+		writeError(w, 400, err.Error())
+		return
+	case err != nil:
+		writeError(w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := g.Export(w, format); err != nil {
+		log.Printf("api: export graph %s: %v", g.Root, err)
+	}
+}
+
+// handleEventPath returns the shortest chain of causes from one event to
+// another, or [] if to doesn't descend from from.
+func (s *Server) handleEventPath(w http.ResponseWriter, r *http.Request) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeError(w, 400, "from and to are required")
+		return
+	}
+	path, err := s.events.PathBetween(r.Context(), from, to)
+	writeEvents(w, path, err)
+}
+
+// handleEventCommon returns the ancestors shared by every event in id,
+// which may be repeated or comma-separated.
+func (s *Server) handleEventCommon(w http.ResponseWriter, r *http.Request) {
+	events, err := s.events.CommonAncestors(r.Context(), listParam(r.URL.Query()["id"])...)
+	writeEvents(w, events, err)
+}
+
+// writeEvents writes the result of a causal query.
+func writeEvents(w http.ResponseWriter, events []eventgraph.Event, err error) {
+	switch {
+	case errors.Is(err, eventgraph.ErrNotFound):
+		writeError(w, 404, err.Error())
+	case errors.Is(err, eventgraph.ErrInvalidQuery):
+		writeError(w, 400, err.Error())
+	case err != nil:
+		writeError(w, 500, err.Error())
+	default:
+		writeJSON(w, 200, events)
+	}
+}
+
+// handleEventSearch ranks events against a full-text query in q; see
+// eventgraph.EventStore.Search for the syntax.
+func (s *Server) handleEventSearch(w http.ResponseWriter, r *http.Request) {
+	results, err := s.events.Search(r.Context(), r.URL.Query().Get("q"), queryInt(r, "limit", 20))
+	if errors.Is(err, eventgraph.ErrInvalidQuery) {
+		writeError(w, 400, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	if results == nil {
+		results = []eventgraph.SearchResult{}
+	}
+	writeJSON(w, 200, results)
+}
+
+// handleEventTypes lists every registered event type with its schema and
+// version, plus the unregistered types that appear in the log, by name only.
 func (s *Server) handleEventTypes(w http.ResponseWriter, r *http.Request) {
-	types, err := s.events.DistinctTypes(r.Context())
+	seen, err := s.events.DistinctTypes(r.Context())
 	if err != nil {
 		writeError(w, 500, err.Error())
 		return
 	}
-	writeJSON(w, 200, types)
+	writeJSON(w, 200, eventgraph.DefaultRegistry.Describe(seen))
 }
 
 func (s *Server) handleEventSources(w http.ResponseWriter, r *http.Request) {
@@ -126,6 +394,101 @@ func (s *Server) handleEventSources(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, sources)
 }
 
+// handleEventVerify reports on the chain without changing anything. With
+// from/to it verifies that range; otherwise it resumes from the latest
+// checkpoint, or verifies everything if full=true or there is none.
+func (s *Server) handleEventVerify(w http.ResponseWriter, r *http.Request) {
+	report, status, err := s.verify(r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, 200, report)
+}
+
+// handleEventCheckpoint verifies like handleEventVerify and, if the chain is
+// intact up to the head, records a checkpoint signed by the API.
+func (s *Server) handleEventCheckpoint(w http.ResponseWriter, r *http.Request) {
+	report, status, err := s.verify(r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !report.OK() || !report.AtHead {
+		writeJSON(w, 409, map[string]any{"error": "chain not verified to head; no checkpoint recorded", "report": report})
+		return
+	}
+	cp, err := s.events.SaveCheckpoint(r.Context(), report, "api", s.signer)
+	if err != nil {
+		writeError(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, 201, map[string]any{"checkpoint": cp, "report": report})
+}
+
+func (s *Server) verify(r *http.Request) (*eventgraph.VerifyReport, int, error) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	if from != "" || to != "" || q.Get("full") == "true" {
+		report, err := s.events.VerifyRange(ctx, from, to, s.actors)
+		if errors.Is(err, eventgraph.ErrNotFound) {
+			return nil, 404, err
+		}
+		if err != nil {
+			return nil, 500, err
+		}
+		return report, 200, nil
+	}
+	cp, err := s.events.LatestCheckpoint(ctx)
+	if err != nil {
+		return nil, 500, err
+	}
+	report, err := s.events.VerifySince(ctx, cp, s.actors)
+	if err != nil {
+		return nil, 500, err
+	}
+	return report, 200, nil
+}
+
+func (s *Server) handleTreeHead(w http.ResponseWriter, r *http.Request) {
+	head, err := s.events.TreeHead(r.Context(), queryInt(r, "tree_size", 0))
+	if err != nil {
+		writeError(w, proofStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, 200, head)
+}
+
+func (s *Server) handleInclusionProof(w http.ResponseWriter, r *http.Request) {
+	p, err := s.events.InclusionProof(r.Context(), r.PathValue("id"), queryInt(r, "tree_size", 0))
+	if err != nil {
+		writeError(w, proofStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, 200, p)
+}
+
+func (s *Server) handleConsistencyProof(w http.ResponseWriter, r *http.Request) {
+	p, err := s.events.ConsistencyProof(r.Context(), queryInt(r, "old", 0), queryInt(r, "new", 0))
+	if err != nil {
+		writeError(w, proofStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, 200, p)
+}
+
+func proofStatus(err error) int {
+	switch {
+	case errors.Is(err, eventgraph.ErrNotFound):
+		return 404
+	case errors.Is(err, eventgraph.ErrTreeSize):
+		return 400
+	default:
+		return 500
+	}
+}
+
 func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -1,31 +1,50 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"mind-zero-five/internal/db"
+	"mind-zero-five/pkg/actor"
 	"mind-zero-five/pkg/authority"
 	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/page"
 	"mind-zero-five/pkg/task"
 )
 
+// UnitOfWork runs fn with stores that share one transaction. *db.Stores
+// implements it.
+type UnitOfWork interface {
+	InTx(ctx context.Context, fn func(tx *db.Tx) error) error
+}
+
 // Server is the HTTP API server.
 type Server struct {
 	events eventgraph.EventStore
 	tasks  task.Store
 	auth   authority.Store
+	actors actor.Store
+	signer eventgraph.Signer // the "api" actor's key; signs events the API emits
+	uow    UnitOfWork        // for writes that must commit together with their event
 	mux    *http.ServeMux
 }
 
-// New creates a new Server.
-func New(events eventgraph.EventStore, tasks task.Store, auth authority.Store) *Server {
+// New creates a new Server. signer should hold the key of the "api" actor.
+func New(events eventgraph.EventStore, tasks task.Store, auth authority.Store, actors actor.Store, signer eventgraph.Signer, uow UnitOfWork) *Server {
 	s := &Server{
 		events: events,
 		tasks:  tasks,
 		auth:   auth,
+		actors: actors,
+		signer: signer,
+		uow:    uow,
 		mux:    http.NewServeMux(),
 	}
 	s.routes()
@@ -43,10 +62,28 @@ func (s *Server) routes() {
 	s.mux.HandleFunc("POST /api/events", s.handleEventCreate)
 	s.mux.HandleFunc("GET /api/events/types", s.handleEventTypes)
 	s.mux.HandleFunc("GET /api/events/sources", s.handleEventSources)
+	s.mux.HandleFunc("GET /api/events/search", s.handleEventSearch)
+	s.mux.HandleFunc("GET /api/events/path", s.handleEventPath)
+	s.mux.HandleFunc("GET /api/events/common", s.handleEventCommon)
 	s.mux.HandleFunc("GET /api/events/stream", s.handleEventStream)
+	s.mux.HandleFunc("GET /api/events/verify", s.handleEventVerify)
+	s.mux.HandleFunc("POST /api/events/verify", s.handleEventCheckpoint)
+	s.mux.HandleFunc("GET /api/events/tree", s.handleTreeHead)
+	s.mux.HandleFunc("GET /api/events/tree/consistency", s.handleConsistencyProof)
 	s.mux.HandleFunc("GET /api/events/{id}", s.handleEventGet)
 	s.mux.HandleFunc("GET /api/events/{id}/ancestors", s.handleEventAncestors)
 	s.mux.HandleFunc("GET /api/events/{id}/descendants", s.handleEventDescendants)
+	s.mux.HandleFunc("GET /api/events/{id}/graph", s.handleEventGraph)
+	s.mux.HandleFunc("GET /api/events/{id}/proof", s.handleInclusionProof)
+	s.mux.HandleFunc("POST /api/events/{id}/redact", s.handleEventRedact)
+
+	// Conversations
+	s.mux.HandleFunc("GET /api/conversations", s.handleConversationList)
+	s.mux.HandleFunc("POST /api/conversations", s.handleConversationCreate)
+	s.mux.HandleFunc("GET /api/conversations/{id}", s.handleConversationGet)
+	s.mux.HandleFunc("PATCH /api/conversations/{id}", s.handleConversationUpdate)
+	s.mux.HandleFunc("GET /api/conversations/{id}/events", s.handleConversationEvents)
+	s.mux.HandleFunc("POST /api/conversations/{id}/events", s.handleEventCreate)
 
 	// Tasks
 	s.mux.HandleFunc("GET /api/tasks", s.handleTaskList)
@@ -71,6 +108,36 @@ func (s *Server) routes() {
 	s.mux.Handle("GET /", http.FileServer(http.Dir(wasmDir)))
 }
 
+// writePage writes a page as {"items": [...], "next": ..., "prev": ...},
+// with the cursors for the pages either side omitted when there is nothing
+// that way. They also go in a Link header (RFC 8288) as the same URL with
+// its cursor parameter replaced.
+func writePage[T any](w http.ResponseWriter, r *http.Request, p *page.Page[T]) {
+	var links []string
+	for _, l := range []struct{ rel, cursor string }{{"next", p.Next}, {"prev", p.Prev}} {
+		if l.cursor == "" {
+			continue
+		}
+		u := *r.URL
+		q := u.Query()
+		q.Set("cursor", l.cursor)
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), l.rel))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	writeJSON(w, 200, p)
+}
+
+// pageStatus maps a listing error to an HTTP status: 400 for a bad cursor.
+func pageStatus(err error) int {
+	if errors.Is(err, page.ErrInvalidCursor) {
+		return 400
+	}
+	return 500
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
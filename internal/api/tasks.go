@@ -4,18 +4,20 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"mind-zero-five/internal/db"
+	"mind-zero-five/pkg/payload"
 	"mind-zero-five/pkg/task"
 )
 
 func (s *Server) handleTaskList(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 	limit := queryInt(r, "limit", 50)
-	tasks, err := s.tasks.List(r.Context(), status, limit)
+	tasks, err := s.tasks.ListPage(r.Context(), status, r.URL.Query().Get("cursor"), limit)
 	if err != nil {
-		writeError(w, 500, err.Error())
+		writeError(w, pageStatus(err), err.Error())
 		return
 	}
-	writeJSON(w, 200, tasks)
+	writePage(w, r, tasks)
 }
 
 func (s *Server) handleTaskGet(w http.ResponseWriter, r *http.Request) {
@@ -41,17 +43,25 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 	if t.Source == "" {
 		t.Source = "ui"
 	}
-	result, err := s.tasks.Create(r.Context(), &t)
+	// Create the task and its task.created event together: the event is what
+	// wakes the mind, so a task must never exist without one.
+	var result *task.Task
+	err := s.uow.InTx(r.Context(), func(tx *db.Tx) error {
+		var err error
+		if result, err = tx.Tasks.Create(r.Context(), &t); err != nil {
+			return err
+		}
+		_, err = payload.Append(r.Context(), tx.Events, "api", payload.TaskCreated{
+			TaskID:  result.ID,
+			Subject: result.Subject,
+			Source:  result.Source,
+		}, nil, "", s.signer)
+		return err
+	})
 	if err != nil {
 		writeError(w, 500, err.Error())
 		return
 	}
-	// Emit task.created event so the mind can pick it up
-	s.events.Append(r.Context(), "task.created", "api", map[string]any{
-		"task_id": result.ID,
-		"subject": result.Subject,
-		"source":  result.Source,
-	}, nil, "")
 	writeJSON(w, 201, result)
 }
 
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mind-zero-five/pkg/actor"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/page"
+	"mind-zero-five/pkg/task"
+)
+
+// What a bundle says it is. Import refuses anything else.
+const (
+	BundleFormat  = "mind-zero-five/bundle"
+	BundleVersion = 1
+)
+
+// exportPageSize is how many tasks, requests or conversations Export reads
+// at a time.
+const exportPageSize = 500
+
+// Bundle is a self-describing snapshot of a mind's history, for moving it
+// between databases or keeping it for debugging. Everything is oldest first
+// and exactly as stored: IDs, timestamps and hashes are never regenerated.
+type Bundle struct {
+	Format           string                    `json:"format"`
+	Version          int                       `json:"version"`
+	ExportedAt       time.Time                 `json:"exported_at"`
+	Actors           []BundleActor             `json:"actors"`
+	Policies         []authority.Policy        `json:"policies"`
+	ApprovalRequests []authority.Request       `json:"approval_requests"`
+	Tasks            []task.Task               `json:"tasks"`
+	Conversations    []eventgraph.Conversation `json:"conversations"`
+	Events           []eventgraph.StoredEvent  `json:"events"`
+}
+
+// BundleActor is an actor in a bundle, with its private seed if the export
+// was asked to carry keys.
+type BundleActor struct {
+	actor.Actor
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+// Export snapshots every store into a bundle. Private keys are left out
+// unless withKeys is set, and then only those in s.Keys are included;
+// without them the imported actors can't sign, but their events still
+// verify. It fails if any events are archived (restore
+// them first), and it doesn't stop the world: stop the mind first for a
+// consistent snapshot.
+func (s *Stores) Export(ctx context.Context, withKeys bool) (*Bundle, error) {
+	b := &Bundle{
+		Format:     BundleFormat,
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	actors, err := s.Actors.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+	b.Actors = make([]BundleActor, 0, len(actors))
+	for _, a := range actors {
+		ba := BundleActor{Actor: a}
+		if withKeys && a.PublicKey != "" {
+			if ba.PrivateKey, err = s.Actors.PrivateKey(ctx, a.ID); err != nil {
+				return nil, fmt.Errorf("export: %w", err)
+			}
+		}
+		b.Actors = append(b.Actors, ba)
+	}
+
+	if b.Policies, err = s.Auth.ListPolicies(ctx); err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+	b.ApprovalRequests, err = readAll(func(cursor string) (*page.Page[authority.Request], error) {
+		return s.Auth.RecentPage(ctx, cursor, exportPageSize)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export approval requests: %w", err)
+	}
+	b.Tasks, err = readAll(func(cursor string) (*page.Page[task.Task], error) {
+		return s.Tasks.ListPage(ctx, "", cursor, exportPageSize)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export tasks: %w", err)
+	}
+	b.Conversations, err = readAll(func(cursor string) (*page.Page[eventgraph.Conversation], error) {
+		return s.Events.ConversationsPage(ctx, cursor, exportPageSize)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export conversations: %w", err)
+	}
+	// They are listed by activity; a bundle holds them as created.
+	slices.SortFunc(b.Conversations, func(a, b eventgraph.Conversation) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if b.Events, err = eventgraph.ExportEvents(ctx, s.Events); err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+	if b.Policies == nil {
+		b.Policies = []authority.Policy{}
+	}
+	return b, nil
+}
+
+// readAll follows a newest-first listing to its end and returns it oldest
+// first.
+func readAll[T any](next func(cursor string) (*page.Page[T], error)) ([]T, error) {
+	all := []T{}
+	for cursor := ""; ; {
+		p, err := next(cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, p.Items...)
+		if p.Next == "" {
+			break
+		}
+		cursor = p.Next
+	}
+	slices.Reverse(all)
+	return all, nil
+}
+
+// Import replays a bundle into empty stores in one transaction. Events keep
+// their IDs, timestamps, hashes and prev_hash, and every hash link and
+// signature is checked against the bundle's own actors first; if anything
+// fails, or the stores aren't empty, nothing is written.
+func (s *Stores) Import(ctx context.Context, b *Bundle) error {
+	if b.Format != BundleFormat || b.Version != BundleVersion {
+		return fmt.Errorf("import: not a version %d %s bundle (format %q, version %d)", BundleVersion, BundleFormat, b.Format, b.Version)
+	}
+	return s.InTx(ctx, func(tx *Tx) error {
+		if err := checkEmpty(ctx, tx); err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		for i := range b.Actors {
+			if err := tx.Actors.Import(ctx, &b.Actors[i].Actor, b.Actors[i].PrivateKey); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+		}
+		for i := range b.Policies {
+			if err := tx.Auth.ImportPolicy(ctx, &b.Policies[i]); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+		}
+		for i := range b.ApprovalRequests {
+			if err := tx.Auth.ImportRequest(ctx, &b.ApprovalRequests[i]); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+		}
+		for i := range b.Tasks {
+			if err := tx.Tasks.Import(ctx, &b.Tasks[i]); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+		}
+		for i := range b.Conversations {
+			if err := tx.Events.ImportConversation(ctx, &b.Conversations[i]); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+		}
+		// Imported events aren't new, so they bypass tx.Events and OnCommit.
+		events := tx.Events.(*recordingEvents).EventStore
+		return eventgraph.ImportEvents(ctx, events, b.Events, tx.Actors)
+	})
+}
+
+// checkEmpty fails unless tx's stores hold no actors, policies, requests,
+// tasks or conversations. ImportEvents checks the events itself.
+func checkEmpty(ctx context.Context, tx *Tx) error {
+	actors, err := tx.Actors.List(ctx)
+	if err != nil {
+		return err
+	}
+	policies, err := tx.Auth.ListPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	requests, err := tx.Auth.Recent(ctx, 1)
+	if err != nil {
+		return err
+	}
+	tasks, err := tx.Tasks.Count(ctx)
+	if err != nil {
+		return err
+	}
+	conversations, err := tx.Events.Conversations(ctx, 1)
+	if err != nil {
+		return err
+	}
+	if len(actors) > 0 || len(policies) > 0 || len(requests) > 0 || tasks > 0 || len(conversations) > 0 {
+		return fmt.Errorf("the database isn't empty: it already holds actors, policies, approval requests, tasks or conversations")
+	}
+	return nil
+}
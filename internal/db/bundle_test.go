@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"mind-zero-five/pkg/actor"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/task"
+)
+
+// newSQLiteStores returns migrated stores over a private in-memory SQLite
+// database, keeping private keys in a temporary directory.
+func newSQLiteStores(t *testing.T) *Stores {
+	t.Helper()
+	ctx := context.Background()
+	sqlDB, err := ConnectSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	keys := actor.NewKeys(t.TempDir())
+	s := &Stores{
+		Events: eventgraph.NewSQLiteStore(sqlDB, nil, eventgraph.CausesStrict),
+		Tasks:  task.NewSQLiteStore(sqlDB),
+		Auth:   authority.NewSQLiteStore(sqlDB),
+		Actors: actor.NewSQLiteStore(sqlDB, keys),
+		Keys:   keys,
+		SQLite: sqlDB,
+	}
+	t.Cleanup(s.Close)
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return s
+}
+
+func TestExportAndImport(t *testing.T) {
+	ctx := context.Background()
+	src := newSQLiteStores(t)
+
+	mind, err := src.Actors.Register(ctx, "ai", "mind", "")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if mind, err = src.Actors.GenerateKey(ctx, mind.ID); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	if _, err := src.Actors.Register(ctx, "human", "matt", "matt@example.com"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	signer, err := src.Actors.Signer(ctx, mind.ID)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	if _, err := src.Auth.CreatePolicy(ctx, "deploy", mind.ID, authority.Required); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+	req, err := src.Auth.Create(ctx, "deploy", "ship it", "mind", authority.Required)
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	if _, err := src.Auth.Resolve(ctx, req.ID, true); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	tk, err := src.Tasks.Create(ctx, &task.Task{Subject: "Fix the build", Source: "matt", Metadata: map[string]any{"retry_count": 1}})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	conv, err := src.Events.CreateConversation(ctx, "task-"+tk.ID, "Fix the build", "mind")
+	if err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	if _, err := src.Events.CreateConversation(ctx, "", "Quiet", "ui"); err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	created, err := src.Events.Append(ctx, "task.created", "mind", map[string]any{"task_id": tk.ID, "subject": tk.Subject}, nil, conv.ID, signer)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := src.Events.Append(ctx, "task.claimed", "mind", map[string]any{"task_id": tk.ID}, []string{created.ID}, conv.ID, signer); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	exported, err := src.Export(ctx, true)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(exported.Actors) != 2 || len(exported.Policies) != 1 || len(exported.ApprovalRequests) != 1 ||
+		len(exported.Tasks) != 1 || len(exported.Conversations) != 2 || len(exported.Events) != 2 {
+		t.Fatalf("export = %d actors, %d policies, %d requests, %d tasks, %d conversations, %d events",
+			len(exported.Actors), len(exported.Policies), len(exported.ApprovalRequests),
+			len(exported.Tasks), len(exported.Conversations), len(exported.Events))
+	}
+	// Through JSON and back, as eg export and eg import carry it.
+	data, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+
+	dst := newSQLiteStores(t)
+	if err := dst.Import(ctx, &b); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	again, err := dst.Export(ctx, true)
+	if err != nil {
+		t.Fatalf("re-export: %v", err)
+	}
+	again.ExportedAt = exported.ExportedAt
+	want, _ := json.Marshal(exported)
+	got, _ := json.Marshal(again)
+	if string(got) != string(want) {
+		t.Errorf("re-export differs:\n got %s\nwant %s", got, want)
+	}
+
+	c, err := dst.Events.GetConversation(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("get imported conversation: %v", err)
+	}
+	if c.Title != "Fix the build" || c.CreatedBy != "mind" || c.EventCount != 2 || !c.CreatedAt.Equal(conv.CreatedAt) {
+		t.Errorf("imported conversation = %+v", c)
+	}
+	if err := dst.Events.VerifyChain(ctx, dst.Actors); err != nil {
+		t.Errorf("verify after import: %v", err)
+	}
+	// The keys came along, so the mind can carry on signing.
+	if _, err := dst.Actors.Signer(ctx, mind.ID); err != nil {
+		t.Errorf("signer after import: %v", err)
+	}
+
+	if err := dst.Import(ctx, &b); err == nil {
+		t.Error("import into a database that isn't empty succeeded")
+	}
+	onlyConv := newSQLiteStores(t)
+	if _, err := onlyConv.Events.CreateConversation(ctx, "", "Already here", "ui"); err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	if err := onlyConv.Import(ctx, &b); err == nil {
+		t.Error("import into a database holding a conversation succeeded")
+	}
+	if n, _ := onlyConv.Tasks.Count(ctx); n != 0 {
+		t.Errorf("failed import stored %d tasks", n)
+	}
+}
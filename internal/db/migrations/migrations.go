@@ -0,0 +1,230 @@
+// Package migrations holds the numbered schema migrations for every store and
+// applies them in order, recording each in a schema_migrations table.
+//
+// Migrations live in postgres/ and sqlite/ as NNNN_name.sql files. They are
+// append-only: once a migration has been applied anywhere, its file must not
+// change. Each applied migration's checksum is recorded, and Up refuses to run
+// if an applied file has been edited since.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialect selects which set of migration files applies.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// Migration is a single numbered up-migration.
+type Migration struct {
+	Version  int
+	Name     string
+	SQL      string
+	Checksum string
+}
+
+// Applied is a row from schema_migrations.
+type Applied struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migration states reported by Status.
+const (
+	StateApplied  = "applied"
+	StatePending  = "pending"
+	StateModified = "modified" // applied, but the file's checksum has changed since
+	StateUnknown  = "unknown"  // recorded in the database but no longer in the tree
+)
+
+// Status describes one migration's state in a database.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Checksum  string     `json:"checksum"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Load returns the migrations for a dialect, ordered by version.
+func Load(dialect Dialect) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, string(dialect))
+	if err != nil {
+		return nil, fmt.Errorf("read %s migrations: %w", dialect, err)
+	}
+
+	var migrations []Migration
+	seen := map[int]string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		num, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_description.sql", e.Name())
+		}
+		version, err := strconv.Atoi(num)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", e.Name(), num)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migration %s: version %d already used by %s", e.Name(), version, prev)
+		}
+		seen[version] = e.Name()
+
+		raw, err := files.ReadFile(path.Join(string(dialect), e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+		sql := normalize(raw)
+		sum := sha256.Sum256([]byte(sql))
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     name,
+			SQL:      sql,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// normalize strips carriage returns so a checkout with different line endings
+// doesn't change a migration's checksum.
+func normalize(raw []byte) string {
+	return strings.ReplaceAll(string(raw), "\r\n", "\n")
+}
+
+// driver is the database-specific half of a Runner.
+type driver interface {
+	// lock serializes migration runs across processes until unlock is called.
+	lock(ctx context.Context) (unlock func(), err error)
+	// ensureTable creates schema_migrations if it doesn't exist.
+	ensureTable(ctx context.Context) error
+	applied(ctx context.Context) ([]Applied, error)
+	// apply runs one migration and records it, atomically.
+	apply(ctx context.Context, m Migration) error
+}
+
+// Runner applies migrations to one database.
+type Runner struct {
+	dialect Dialect
+	driver  driver
+}
+
+// Status reports every known migration, applied or not, ordered by version.
+// It does not take the migration lock.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	if err := r.driver.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+	migrations, err := Load(r.dialect)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := r.driver.applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	return status(migrations, applied), nil
+}
+
+// Up applies all pending migrations in order and returns the ones it applied.
+// Concurrent callers (e.g. the server and the mind starting together) are
+// serialized by a database lock; the loser finds nothing left to do.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	migrations, err := Load(r.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.driver.lock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer unlock()
+
+	if err := r.driver.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+	applied, err := r.driver.applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+
+	var pending []Migration
+	for _, s := range status(migrations, applied) {
+		switch s.State {
+		case StateModified:
+			return nil, fmt.Errorf("migration %04d_%s was modified after being applied (checksum mismatch)", s.Version, s.Name)
+		case StateUnknown:
+			return nil, fmt.Errorf("database has migration %04d_%s which is not in this build", s.Version, s.Name)
+		}
+	}
+	done := map[int]bool{}
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+
+	var ran []Migration
+	for _, m := range pending {
+		if err := r.driver.apply(ctx, m); err != nil {
+			return ran, fmt.Errorf("apply migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+func status(migrations []Migration, applied []Applied) []Status {
+	byVersion := map[int]Applied{}
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	var out []Status
+	for _, m := range migrations {
+		s := Status{Version: m.Version, Name: m.Name, State: StatePending, Checksum: m.Checksum}
+		if a, ok := byVersion[m.Version]; ok {
+			at := a.AppliedAt
+			s.AppliedAt = &at
+			s.State = StateApplied
+			if a.Checksum != m.Checksum {
+				s.State = StateModified
+			}
+			delete(byVersion, m.Version)
+		}
+		out = append(out, s)
+	}
+	for _, a := range byVersion {
+		at := a.AppliedAt
+		out = append(out, Status{Version: a.Version, Name: a.Name, State: StateUnknown, Checksum: a.Checksum, AppliedAt: &at})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
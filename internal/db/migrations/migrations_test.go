@@ -0,0 +1,196 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestDB returns an empty in-memory SQLite database, opened the way
+// db.ConnectSQLite opens one.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func mustLoad(t *testing.T) []Migration {
+	t.Helper()
+	migrations, err := Load(SQLite)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no sqlite migrations")
+	}
+	return migrations
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+	migrations := mustLoad(t)
+	r := NewSQLite(newTestDB(t))
+
+	st, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(st) != len(migrations) {
+		t.Fatalf("status of an empty database has %d rows, want %d", len(st), len(migrations))
+	}
+	for _, s := range st {
+		if s.State != StatePending || s.AppliedAt != nil {
+			t.Errorf("migration %d before Up = %s, want pending", s.Version, s.State)
+		}
+	}
+
+	ran, err := r.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(ran) != len(migrations) {
+		t.Fatalf("up applied %d migrations, want %d", len(ran), len(migrations))
+	}
+	for i, m := range ran {
+		if m.Version != migrations[i].Version {
+			t.Errorf("up applied %d at position %d, want %d", m.Version, i, migrations[i].Version)
+		}
+	}
+	st, err = r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for i, s := range st {
+		if s.State != StateApplied || s.AppliedAt == nil || s.Checksum != migrations[i].Checksum {
+			t.Errorf("migration %d after Up = %+v, want applied", s.Version, s)
+		}
+	}
+
+	ran, err = r.Up(ctx)
+	if err != nil {
+		t.Fatalf("second up: %v", err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("second up applied %d migrations, want none", len(ran))
+	}
+}
+
+func TestUpRefusesModified(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	r := NewSQLite(db)
+	if _, err := r.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	first := mustLoad(t)[0]
+	if _, err := db.ExecContext(ctx, `UPDATE schema_migrations SET checksum = ? WHERE version = ?`, strings.Repeat("0", 64), first.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if st[0].Version != first.Version || st[0].State != StateModified {
+		t.Errorf("status[0] = %+v, want %d modified", st[0], first.Version)
+	}
+	if st[0].Checksum != first.Checksum {
+		t.Errorf("modified checksum = %s, want the file's %s", st[0].Checksum, first.Checksum)
+	}
+	if _, err := r.Up(ctx); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("up with a modified migration: err = %v, want a checksum mismatch", err)
+	}
+}
+
+func TestUpRefusesUnknown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	r := NewSQLite(db)
+	if _, err := r.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (9999, 'from_the_future', 'x', 0)`); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	last := st[len(st)-1]
+	if last.Version != 9999 || last.Name != "from_the_future" || last.State != StateUnknown || last.AppliedAt == nil {
+		t.Errorf("last status = %+v, want 9999 unknown", last)
+	}
+	if _, err := r.Up(ctx); err == nil || !strings.Contains(err.Error(), "not in this build") {
+		t.Errorf("up with an unknown migration: err = %v, want a refusal", err)
+	}
+}
+
+func TestChecksumIgnoresLineEndings(t *testing.T) {
+	for _, m := range mustLoad(t) {
+		if strings.Contains(m.SQL, "\r") {
+			t.Errorf("migration %d SQL holds a carriage return", m.Version)
+		}
+		crlf := strings.ReplaceAll(m.SQL, "\n", "\r\n")
+		sum := sha256.Sum256([]byte(normalize([]byte(crlf))))
+		if got := hex.EncodeToString(sum[:]); got != m.Checksum {
+			t.Errorf("migration %d checksum with CRLF line endings = %s, want %s", m.Version, got, m.Checksum)
+		}
+	}
+}
+
+func TestConcurrentUp(t *testing.T) {
+	ctx := context.Background()
+	migrations := mustLoad(t)
+	db := newTestDB(t)
+
+	// Two runners, as the server and the mind would each have.
+	const n = 2
+	ran := make([][]Migration, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ran[i], errs[i] = NewSQLite(db).Up(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("up %d: %v", i, err)
+		}
+	}
+	if len(ran[0])+len(ran[1]) < len(migrations) {
+		t.Errorf("up applied %d and %d migrations, want %d between them", len(ran[0]), len(ran[1]), len(migrations))
+	}
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(migrations) {
+		t.Errorf("schema_migrations has %d rows, want %d", count, len(migrations))
+	}
+	st, err := NewSQLite(db).Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, s := range st {
+		if s.State != StateApplied {
+			t.Errorf("migration %d after concurrent Up = %s, want applied", s.Version, s.State)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the pg_advisory_lock key guarding migration runs. Arbitrary, but
+// must never change: every process has to agree on it.
+const lockKey int64 = 0x6d7a355f6d696772 // "mz5_migr"
+
+// NewPostgres returns a Runner for a Postgres database.
+func NewPostgres(pool *pgxpool.Pool) *Runner {
+	return &Runner{dialect: Postgres, driver: &pgDriver{pool: pool}}
+}
+
+type pgDriver struct {
+	pool *pgxpool.Pool
+	// conn holds the advisory lock while Up runs. Session-level advisory locks
+	// belong to a connection, so migrations are applied on the same one.
+	conn *pgxpool.Conn
+}
+
+func (d *pgDriver) lock(ctx context.Context) (func(), error) {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	d.conn = conn
+	return func() {
+		// Use a fresh context: the lock must be released even if ctx was cancelled.
+		conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		conn.Release()
+		d.conn = nil
+	}, nil
+}
+
+func (d *pgDriver) ensureTable(ctx context.Context) error {
+	_, err := d.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	return err
+}
+
+func (d *pgDriver) applied(ctx context.Context) ([]Applied, error) {
+	rows, err := d.pool.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+func (d *pgDriver) apply(ctx context.Context, m Migration) error {
+	return pgx.BeginFunc(ctx, d.conn, func(tx pgx.Tx) error {
+		// No arguments, so pgx uses the simple protocol and the file may
+		// contain several statements.
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+			m.Version, m.Name, m.Checksum, time.Now())
+		return err
+	})
+}
@@ -0,0 +1,18 @@
+-- Event log. IF NOT EXISTS lets databases created by the old EnsureTable
+-- adopt this migration without changes.
+CREATE TABLE IF NOT EXISTS events (
+	id              TEXT PRIMARY KEY,
+	type            TEXT NOT NULL,
+	timestamp       TIMESTAMPTZ NOT NULL,
+	source          TEXT NOT NULL,
+	content         JSONB NOT NULL DEFAULT '{}',
+	causes          TEXT[] DEFAULT '{}',
+	conversation_id TEXT NOT NULL DEFAULT '',
+	hash            TEXT NOT NULL,
+	prev_hash       TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_events_source ON events(source);
+CREATE INDEX IF NOT EXISTS idx_events_timestamp_id ON events(timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_events_causes ON events USING GIN(causes);
+CREATE INDEX IF NOT EXISTS idx_events_conversation ON events(conversation_id) WHERE conversation_id != '';
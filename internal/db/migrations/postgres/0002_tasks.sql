@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS tasks (
+	id           TEXT PRIMARY KEY,
+	subject      TEXT NOT NULL,
+	description  TEXT NOT NULL DEFAULT '',
+	status       TEXT NOT NULL DEFAULT 'pending',
+	priority     INTEGER NOT NULL DEFAULT 0,
+	source       TEXT NOT NULL DEFAULT '',
+	assignee     TEXT NOT NULL DEFAULT '',
+	parent_id    TEXT NOT NULL DEFAULT '',
+	blocked_by   TEXT[] DEFAULT '{}',
+	metadata     JSONB NOT NULL DEFAULT '{}',
+	created_at   TIMESTAMPTZ DEFAULT NOW(),
+	updated_at   TIMESTAMPTZ DEFAULT NOW(),
+	completed_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id) WHERE parent_id != '';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS approval_requests (
+	id          TEXT PRIMARY KEY,
+	action      TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	level       TEXT NOT NULL,
+	source      TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL DEFAULT 'pending',
+	created_at  TIMESTAMPTZ DEFAULT NOW(),
+	resolved_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_approval_status ON approval_requests(status, created_at);
+
+CREATE TABLE IF NOT EXISTS authority_policies (
+	id          TEXT PRIMARY KEY,
+	action      TEXT NOT NULL,
+	approver_id TEXT NOT NULL,
+	level       TEXT NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_action ON authority_policies(action);
@@ -0,0 +1,6 @@
+-- Ed25519 public keys on actors, and a signature over each event's hash by its
+-- source actor. Events appended before signing existed keep an empty signature.
+-- Private keys are kept outside the database (see actor.Keys).
+ALTER TABLE actors ADD COLUMN public_key TEXT;
+ALTER TABLE actors ADD COLUMN key_created_at TIMESTAMPTZ;
+ALTER TABLE events ADD COLUMN signature TEXT NOT NULL DEFAULT '';
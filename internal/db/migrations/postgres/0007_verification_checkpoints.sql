@@ -0,0 +1,11 @@
+-- Signed records that the chain verified cleanly up to an event, so
+-- verification can resume from there instead of from genesis.
+CREATE TABLE verification_checkpoints (
+	id          TEXT PRIMARY KEY,
+	event_id    TEXT NOT NULL,
+	event_hash  TEXT NOT NULL,
+	verified_at TIMESTAMPTZ NOT NULL,
+	source      TEXT NOT NULL,
+	signature   TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_checkpoints_verified_at ON verification_checkpoints(verified_at);
@@ -0,0 +1,7 @@
+-- Durable positions of named event-log consumers: the last event each has
+-- acknowledged, so it resumes from there after a restart.
+CREATE TABLE consumer_offsets (
+	name     TEXT PRIMARY KEY,
+	event_id TEXT NOT NULL,
+	acked_at TIMESTAMPTZ NOT NULL
+);
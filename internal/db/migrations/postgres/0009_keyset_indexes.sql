@@ -0,0 +1,4 @@
+-- Keyset pagination reads tasks and approval requests in (created_at, id)
+-- order from a cursor.
+CREATE INDEX idx_tasks_created ON tasks(created_at, id);
+CREATE INDEX idx_approval_created ON approval_requests(created_at, id);
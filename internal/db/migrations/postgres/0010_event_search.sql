@@ -0,0 +1,8 @@
+-- Full-text search over event type, source and the string values in
+-- content (JSON keys aren't indexed). Type and source weigh more than
+-- content when ranking.
+ALTER TABLE events ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', replace(type, '.', ' ') || ' ' || source), 'A') ||
+	setweight(jsonb_to_tsvector('english', content, '["string"]'), 'B')
+) STORED;
+CREATE INDEX idx_events_search ON events USING GIN(search);
@@ -0,0 +1,22 @@
+-- Retention. Events archived to segment files (see eventgraph.Archive) leave
+-- a stub in archived_events, so the hash chain still runs from genesis.
+CREATE TABLE event_segments (
+	name            TEXT PRIMARY KEY,
+	first_id        TEXT NOT NULL,
+	last_id         TEXT NOT NULL,
+	first_timestamp TIMESTAMPTZ NOT NULL,
+	last_timestamp  TIMESTAMPTZ NOT NULL,
+	count           INTEGER NOT NULL,
+	prev_hash       TEXT NOT NULL,
+	first_hash      TEXT NOT NULL,
+	last_hash       TEXT NOT NULL,
+	sha256          TEXT NOT NULL,
+	archived_at     TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE archived_events (
+	id        TEXT PRIMARY KEY,
+	timestamp TIMESTAMPTZ NOT NULL,
+	hash      TEXT NOT NULL,
+	segment   TEXT NOT NULL REFERENCES event_segments(name)
+);
+CREATE INDEX idx_archived_events_timestamp_id ON archived_events(timestamp, id);
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// NewSQLite returns a Runner for a SQLite database. The caller registers the
+// driver.
+func NewSQLite(db *sql.DB) *Runner {
+	return &Runner{dialect: SQLite, driver: &sqliteDriver{db: db}}
+}
+
+type sqliteDriver struct {
+	db *sql.DB
+}
+
+// lock is a no-op: SQLite has no advisory locks. Instead each migration is
+// applied in a write transaction that re-checks schema_migrations first, so a
+// concurrent runner that got there first turns the apply into a no-op.
+func (d *sqliteDriver) lock(context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+func (d *sqliteDriver) ensureTable(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)`)
+	return err
+}
+
+func (d *sqliteDriver) applied(ctx context.Context) ([]Applied, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []Applied
+	for rows.Next() {
+		var a Applied
+		var at int64
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &at); err != nil {
+			return nil, err
+		}
+		a.AppliedAt = time.UnixMicro(at)
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+func (d *sqliteDriver) apply(ctx context.Context, m Migration) error {
+	// With _txlock=immediate (see db.ConnectSQLite) this takes the write lock
+	// up front, so the check below can't go stale.
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, m.Version).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		m.Version, m.Name, m.Checksum, time.Now().UnixMicro()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
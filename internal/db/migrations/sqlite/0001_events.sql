@@ -0,0 +1,16 @@
+-- Event log. Timestamps are Unix microseconds; causes is a JSON array.
+CREATE TABLE IF NOT EXISTS events (
+	id              TEXT PRIMARY KEY,
+	type            TEXT NOT NULL,
+	timestamp       INTEGER NOT NULL,
+	source          TEXT NOT NULL,
+	content         TEXT NOT NULL DEFAULT '{}',
+	causes          TEXT NOT NULL DEFAULT '[]',
+	conversation_id TEXT NOT NULL DEFAULT '',
+	hash            TEXT NOT NULL,
+	prev_hash       TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_events_source ON events(source);
+CREATE INDEX IF NOT EXISTS idx_events_timestamp_id ON events(timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_events_conversation ON events(conversation_id) WHERE conversation_id != '';
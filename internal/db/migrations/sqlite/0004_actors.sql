@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS actors (
+	id         TEXT PRIMARY KEY,
+	type       TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	email      TEXT,
+	created_at INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS actors_type_name_idx ON actors(type, name);
+CREATE UNIQUE INDEX IF NOT EXISTS actors_email_idx ON actors(email) WHERE email IS NOT NULL;
+CREATE INDEX IF NOT EXISTS actors_name_idx ON actors(name);
@@ -0,0 +1,3 @@
+-- Record which hash format each event was hashed with. Existing rows predate
+-- the versioned format and are v1.
+ALTER TABLE events ADD COLUMN hash_version INTEGER NOT NULL DEFAULT 1;
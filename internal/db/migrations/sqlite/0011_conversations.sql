@@ -0,0 +1,8 @@
+-- Named conversations. Their events are those with a matching
+-- events.conversation_id; participants and activity are read from there.
+CREATE TABLE conversations (
+	id         TEXT PRIMARY KEY,
+	title      TEXT NOT NULL DEFAULT '',
+	created_by TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
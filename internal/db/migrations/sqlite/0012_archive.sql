@@ -0,0 +1,23 @@
+-- Retention. Events archived to segment files (see eventgraph.Archive) leave
+-- a stub in archived_events, so the hash chain still runs from genesis.
+-- Timestamps are Unix microseconds.
+CREATE TABLE event_segments (
+	name            TEXT PRIMARY KEY,
+	first_id        TEXT NOT NULL,
+	last_id         TEXT NOT NULL,
+	first_timestamp INTEGER NOT NULL,
+	last_timestamp  INTEGER NOT NULL,
+	count           INTEGER NOT NULL,
+	prev_hash       TEXT NOT NULL,
+	first_hash      TEXT NOT NULL,
+	last_hash       TEXT NOT NULL,
+	sha256          TEXT NOT NULL,
+	archived_at     INTEGER NOT NULL
+);
+CREATE TABLE archived_events (
+	id        TEXT PRIMARY KEY,
+	timestamp INTEGER NOT NULL,
+	hash      TEXT NOT NULL,
+	segment   TEXT NOT NULL REFERENCES event_segments(name)
+);
+CREATE INDEX idx_archived_events_timestamp_id ON archived_events(timestamp, id);
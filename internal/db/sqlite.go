@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+)
+
+// ConnectSQLite opens the SQLite database at path (":memory:" for a private
+// in-memory database). WAL mode and a busy timeout let cmd/server, cmd/mind
+// and eg share one file; writers take the lock up front (_txlock=immediate)
+// so concurrent hash-chain appends serialize instead of failing.
+func ConnectSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite path is empty")
+	}
+	dsn := "file:" + path + "?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_txlock=immediate"
+	if path != ":memory:" {
+		dsn += "&_pragma=journal_mode(WAL)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	// One connection per process: SQLite has a single writer anyway, and an
+	// in-memory database only exists on the connection that created it.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping sqlite: %w", err)
+	}
+	return db, nil
+}
+
+// sqlitePath extracts the file path from a sqlite:// or sqlite: URL.
+// "sqlite:///var/data/mind.db" -> "/var/data/mind.db", "sqlite://mind.db" -> "mind.db".
+func sqlitePath(url string) string {
+	if rest, ok := strings.CutPrefix(url, "sqlite://"); ok {
+		return rest
+	}
+	return strings.TrimPrefix(url, "sqlite:")
+}
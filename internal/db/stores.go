@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mind-zero-five/internal/db/migrations"
+	"mind-zero-five/pkg/actor"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/task"
+)
+
+// Stores bundles the event, task, authority and actor stores backed by one database.
+type Stores struct {
+	Events eventgraph.EventStore
+	Tasks  task.Store
+	Auth   authority.Store
+	Actors actor.Store
+
+	// Keys holds the actors' private keys, which aren't in the database.
+	Keys *actor.Keys
+	// Scrubber masks secrets in content appended through Events and InTx.
+	Scrubber *eventgraph.Scrubber
+	// Causes is how Events and InTx treat causes that aren't on the chain.
+	Causes eventgraph.CauseMode
+
+	// Exactly one of these is set, depending on the DATABASE_URL scheme.
+	Pool   *pgxpool.Pool
+	SQLite *sql.DB
+
+	// OnCommit, if set, receives the events appended by each InTx after it
+	// commits (e.g. Bus.Publish).
+	OnCommit func(events ...*eventgraph.Event)
+}
+
+// Open connects to DATABASE_URL and returns stores for its backend.
+// sqlite:///path/to/file.db selects SQLite; anything else is handed to pgx.
+// EVENT_CAUSES=lenient makes Append keep events citing causes that aren't on
+// the chain, flagged, instead of rejecting them. EVENT_SCRUB_RULES names a
+// JSON file of scrub rules to add to, replace or remove from the defaults
+// Append masks secrets in content with. Private keys are kept in
+// ACTOR_KEY_DIR (see actor.DefaultKeys).
+func Open(ctx context.Context) (*Stores, error) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		return nil, fmt.Errorf("DATABASE_URL not set")
+	}
+	mode, err := eventgraph.ParseCauseMode(os.Getenv("EVENT_CAUSES"))
+	if err != nil {
+		return nil, fmt.Errorf("EVENT_CAUSES: %w", err)
+	}
+	scrubber, err := scrubRules(os.Getenv("EVENT_SCRUB_RULES"))
+	if err != nil {
+		return nil, fmt.Errorf("EVENT_SCRUB_RULES: %w", err)
+	}
+	keys, err := actor.DefaultKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(dsn, "sqlite:") {
+		sqlDB, err := ConnectSQLite(ctx, sqlitePath(dsn))
+		if err != nil {
+			return nil, err
+		}
+		return &Stores{
+			Events:   eventgraph.NewSQLiteStore(sqlDB, scrubber, mode),
+			Tasks:    task.NewSQLiteStore(sqlDB),
+			Auth:     authority.NewSQLiteStore(sqlDB),
+			Actors:   actor.NewSQLiteStore(sqlDB, keys),
+			Keys:     keys,
+			Scrubber: scrubber,
+			Causes:   mode,
+			SQLite:   sqlDB,
+		}, nil
+	}
+
+	pool, err := Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Stores{
+		Events:   eventgraph.NewPgStore(pool, scrubber, mode),
+		Tasks:    task.NewPgStore(pool),
+		Auth:     authority.NewPgStore(pool),
+		Actors:   actor.NewPgStore(pool, keys),
+		Keys:     keys,
+		Scrubber: scrubber,
+		Causes:   mode,
+		Pool:     pool,
+	}, nil
+}
+
+// Bus returns an event bus over s.Events. On Postgres it is a PgBus
+// listening until ctx is cancelled, so subscribers see events appended by any
+// process. On SQLite it is an in-process Bus, which also receives events
+// committed by InTx.
+func (s *Stores) Bus(ctx context.Context) eventgraph.EventBus {
+	if s.Pool != nil {
+		bus := eventgraph.NewPgBus(s.Events, s.Pool)
+		go func() {
+			if err := bus.Listen(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("event bus: %v", err)
+			}
+		}()
+		return bus
+	}
+	bus := eventgraph.NewBus(s.Events)
+	s.OnCommit = bus.Publish
+	return bus
+}
+
+// Migrations returns the schema migration runner for the backing database.
+func (s *Stores) Migrations() *migrations.Runner {
+	if s.SQLite != nil {
+		return migrations.NewSQLite(s.SQLite)
+	}
+	return migrations.NewPostgres(s.Pool)
+}
+
+// Migrate applies any pending schema migrations. Safe to call from several
+// processes at once.
+func (s *Stores) Migrate(ctx context.Context) error {
+	if _, err := s.Migrations().Up(ctx); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying connection pool.
+func (s *Stores) Close() {
+	if s.Pool != nil {
+		s.Pool.Close()
+	}
+	if s.SQLite != nil {
+		s.SQLite.Close()
+	}
+}
+
+// scrubRules returns the default scrubber with the rules file at path, if
+// any, loaded over it.
+func scrubRules(path string) (*eventgraph.Scrubber, error) {
+	s := eventgraph.NewDefaultScrubber()
+	if path == "" {
+		return s, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := s.Load(f); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"mind-zero-five/pkg/actor"
+	"mind-zero-five/pkg/authority"
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/task"
+)
+
+// Tx is a unit of work: stores bound to one database transaction, so a state
+// change and the event recording it commit or roll back together.
+type Tx struct {
+	Events eventgraph.EventStore
+	Tasks  task.Store
+	Auth   authority.Store
+	Actors actor.Store
+}
+
+// InTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. Inside fn, use only tx's stores: on SQLite the transaction
+// holds the only connection, so the others would block.
+//
+// Events appended through tx.Events are passed to OnCommit, if set, once the
+// transaction has committed.
+func (s *Stores) InTx(ctx context.Context, fn func(tx *Tx) error) error {
+	events := &recordingEvents{}
+	var err error
+	if s.SQLite != nil {
+		err = s.inSQLiteTx(ctx, events, fn)
+	} else {
+		err = pgx.BeginFunc(ctx, s.Pool, func(pgTx pgx.Tx) error {
+			events.EventStore = eventgraph.NewPgStoreTx(pgTx, s.Scrubber, s.Causes)
+			return fn(&Tx{
+				Events: events,
+				Tasks:  task.NewPgStoreTx(pgTx),
+				Auth:   authority.NewPgStoreTx(pgTx),
+				Actors: actor.NewPgStoreTx(pgTx, s.Keys),
+			})
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if s.OnCommit != nil && len(events.appended) > 0 {
+		s.OnCommit(events.appended...)
+	}
+	return nil
+}
+
+func (s *Stores) inSQLiteTx(ctx context.Context, events *recordingEvents, fn func(tx *Tx) error) error {
+	sqlTx, err := s.SQLite.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer sqlTx.Rollback()
+
+	events.EventStore = eventgraph.NewSQLiteStoreTx(sqlTx, s.Scrubber, s.Causes)
+	err = fn(&Tx{
+		Events: events,
+		Tasks:  task.NewSQLiteStoreTx(sqlTx),
+		Auth:   authority.NewSQLiteStoreTx(sqlTx),
+		Actors: actor.NewSQLiteStoreTx(sqlTx, s.Keys),
+	})
+	if err != nil {
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+// recordingEvents remembers what was appended so it can be published after
+// commit, never before: subscribers must not see events that roll back.
+type recordingEvents struct {
+	eventgraph.EventStore
+	appended []*eventgraph.Event
+}
+
+func (r *recordingEvents) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer eventgraph.Signer) (*eventgraph.Event, error) {
+	e, err := r.EventStore.Append(ctx, eventType, source, content, causes, conversationID, signer)
+	if err != nil {
+		return nil, err
+	}
+	r.appended = append(r.appended, e)
+	return e, nil
+}
+
+func (r *recordingEvents) Redact(ctx context.Context, id string, req eventgraph.RedactRequest) (*eventgraph.Event, error) {
+	e, err := r.EventStore.Redact(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	r.appended = append(r.appended, e)
+	return e, nil
+}
+
+func (r *recordingEvents) AppendBatch(ctx context.Context, reqs []eventgraph.AppendRequest) ([]*eventgraph.Event, error) {
+	events, err := r.EventStore.AppendBatch(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	r.appended = append(r.appended, events...)
+	return events, nil
+}
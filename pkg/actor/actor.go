@@ -2,9 +2,17 @@ package actor
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 )
 
+// ErrNoKey is returned (wrapped) by Signer when an actor has no keypair.
+var ErrNoKey = errors.New("actor has no key")
+
 // Actor represents an identified entity in the system.
 type Actor struct {
 	ID        string    `json:"id"`
@@ -12,6 +20,29 @@ type Actor struct {
 	Name      string    `json:"name"`       // "matt", "mind", "api"
 	Email     string    `json:"email"`      // for humans
 	CreatedAt time.Time `json:"created_at"`
+
+	// PublicKey is the hex Ed25519 key this actor's events are signed with.
+	// Empty until GenerateKey is called. The private key isn't in the
+	// database: it is kept in Keys, by the processes that sign as the actor.
+	PublicKey    string     `json:"public_key,omitempty"`
+	KeyCreatedAt *time.Time `json:"key_created_at,omitempty"`
+}
+
+// Signer signs event hashes on behalf of an actor. It satisfies
+// eventgraph.Signer.
+type Signer struct {
+	ActorID string
+	key     ed25519.PrivateKey
+}
+
+// NewSigner creates a Signer from an Ed25519 seed.
+func NewSigner(actorID string, seed []byte) *Signer {
+	return &Signer{ActorID: actorID, key: ed25519.NewKeyFromSeed(seed)}
+}
+
+// Sign signs message with the actor's private key.
+func (s *Signer) Sign(message []byte) []byte {
+	return ed25519.Sign(s.key, message)
 }
 
 // Store is the contract for actor persistence.
@@ -29,6 +60,58 @@ type Store interface {
 	// List returns all actors.
 	List(ctx context.Context) ([]Actor, error)
 
-	// EnsureTable creates the actors table if it doesn't exist.
-	EnsureTable(ctx context.Context) error
+	// GenerateKey gives an actor an Ed25519 keypair, keeping the private key
+	// in the store's Keys. Idempotent: an actor that already has a key keeps
+	// it.
+	GenerateKey(ctx context.Context, id string) (*Actor, error)
+
+	// Signer returns a signer holding the actor's private key, failing with
+	// ErrNoKey if the store's Keys don't have it.
+	Signer(ctx context.Context, id string) (*Signer, error)
+
+	// PublicKey returns the key events from source (an actor name) must be
+	// signed with, and when that key was created. The key is nil if no actor
+	// by that name has one. Satisfies eventgraph.KeyLookup.
+	PublicKey(ctx context.Context, source string) (ed25519.PublicKey, time.Time, error)
+
+	// PrivateKey returns the actor's hex private seed, or "" if the store's
+	// Keys don't have one. Only for exports that are meant to carry keys.
+	PrivateKey(ctx context.Context, id string) (string, error)
+
+	// Import inserts a exactly as given, ID, timestamps and public key
+	// included, and keeps privateKey ("" for none) as its seed in the
+	// store's Keys. For moving actors between databases.
+	Import(ctx context.Context, a *Actor, privateKey string) error
+}
+
+// newSeed generates an Ed25519 private seed.
+func newSeed() ([]byte, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	return priv.Seed(), nil
+}
+
+// publicKeyHex returns the public key for seed as stored.
+func publicKeyHex(seed []byte) string {
+	return hex.EncodeToString(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+}
+
+// decodePublicKey parses a stored hex public key.
+func decodePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %q", s)
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// decodeSeed parses a hex private seed.
+func decodeSeed(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	return b, nil
 }
@@ -0,0 +1,198 @@
+package actor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Keys holds actors' private seeds outside the database, so that reading or
+// writing the database isn't enough to sign as an actor. Each seed is a hex
+// file named after its actor's ID, readable only by its owner. Give each
+// process a directory holding just the keys it signs with.
+type Keys struct {
+	dir string
+}
+
+// NewKeys creates a key store over dir, which is created on first write.
+func NewKeys(dir string) *Keys {
+	return &Keys{dir: dir}
+}
+
+// DefaultKeys returns the key store in ACTOR_KEY_DIR, or in
+// mind-zero-five/keys under the user's config directory if that isn't set.
+func DefaultKeys() (*Keys, error) {
+	if dir := os.Getenv("ACTOR_KEY_DIR"); dir != "" {
+		return NewKeys(dir), nil
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("key directory: set ACTOR_KEY_DIR: %w", err)
+	}
+	return NewKeys(filepath.Join(config, "mind-zero-five", "keys")), nil
+}
+
+// Dir returns the directory the keys are kept in.
+func (k *Keys) Dir() string {
+	return k.dir
+}
+
+// Put stores seedHex, a hex private seed, as the key of actor id. An actor
+// can only ever have one seed: Put fails if a different one is already kept.
+func (k *Keys) Put(id, seedHex string) error {
+	seed, err := decodeSeed(seedHex)
+	if err != nil {
+		return fmt.Errorf("put key for actor %s: %w", id, err)
+	}
+	if err := k.put(id, seed); err != nil {
+		return fmt.Errorf("put key for actor %s: %w", id, err)
+	}
+	return nil
+}
+
+// generate returns actor id's seed, creating it if it has none.
+func (k *Keys) generate(id string) ([]byte, error) {
+	seed, err := newSeed()
+	if err != nil {
+		return nil, err
+	}
+	seed, err = k.create(id, seed)
+	if err != nil {
+		return nil, fmt.Errorf("generate key for actor %s: %w", id, err)
+	}
+	return seed, nil
+}
+
+// create writes seed as actor id's key unless it already has one, and returns
+// the key it ends up with. Put wants them to match; generate takes the one
+// already there, so that concurrent callers sharing a directory agree. The
+// seed is written to a temporary file and linked into place whole, so no
+// one ever reads a key file that is still being written.
+func (k *Keys) create(id string, seed []byte) ([]byte, error) {
+	path, err := k.path(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(k.dir, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(k.dir, "."+id+"-*") // mode 0600
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(hex.EncodeToString(seed) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = os.Link(f.Name(), path)
+	if errors.Is(err, fs.ErrExist) {
+		return k.seed(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// put is Put for a seed already decoded, failing if actor id has another.
+func (k *Keys) put(id string, seed []byte) error {
+	have, err := k.create(id, seed)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(have, seed) {
+		return fmt.Errorf("%s already holds a different key for actor %s", k.dir, id)
+	}
+	return nil
+}
+
+// seed reads actor id's seed, failing with ErrNoKey if it has none here.
+func (k *Keys) seed(id string) ([]byte, error) {
+	path, err := k.path(id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no private key in %s: %w", k.dir, ErrNoKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSeed(strings.TrimSpace(string(raw)))
+}
+
+// signer returns a signer for a, checking its seed against the public key
+// its events are verified with.
+func (k *Keys) signer(a *Actor) (*Signer, error) {
+	if a.PublicKey == "" {
+		return nil, fmt.Errorf("signer for actor %s: %w", a.ID, ErrNoKey)
+	}
+	seed, err := k.seed(a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("signer for actor %s: %w", a.ID, err)
+	}
+	if err := checkSeed(a, seed); err != nil {
+		return nil, fmt.Errorf("signer for actor %s: %w", a.ID, err)
+	}
+	return NewSigner(a.ID, seed), nil
+}
+
+// privateKey returns a's hex seed, or "" if it has none here.
+func (k *Keys) privateKey(a *Actor) (string, error) {
+	if a.PublicKey == "" {
+		return "", nil
+	}
+	seed, err := k.seed(a.ID)
+	if errors.Is(err, ErrNoKey) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("private key of actor %s: %w", a.ID, err)
+	}
+	return hex.EncodeToString(seed), nil
+}
+
+// importKey stores seedHex as a's key after checking it matches a's public
+// key.
+func (k *Keys) importKey(a *Actor, seedHex string) error {
+	seed, err := decodeSeed(seedHex)
+	if err == nil {
+		err = checkSeed(a, seed)
+	}
+	if err == nil {
+		err = k.put(a.ID, seed)
+	}
+	if err != nil {
+		return fmt.Errorf("import key for actor %s: %w", a.ID, err)
+	}
+	return nil
+}
+
+// path returns the file actor id's seed is kept in.
+func (k *Keys) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid actor ID %q", id)
+	}
+	return filepath.Join(k.dir, id+".key"), nil
+}
+
+// checkSeed fails unless seed is the private half of a's public key.
+func checkSeed(a *Actor, seed []byte) error {
+	if publicKeyHex(seed) != a.PublicKey {
+		return fmt.Errorf("private key doesn't match public key %s", a.PublicKey)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package actor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestKeysPut(t *testing.T) {
+	k := NewKeys(filepath.Join(t.TempDir(), "keys"))
+	seed, err := newSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := k.Put("a", hex.EncodeToString(seed)); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := k.Put("a", hex.EncodeToString(seed)); err != nil {
+		t.Errorf("put the same key again: %v", err)
+	}
+	if err := k.Put("a", hex.EncodeToString(other)); err == nil {
+		t.Error("put a different key over an existing one succeeded")
+	}
+	if got, err := k.seed("a"); err != nil || !bytes.Equal(got, seed) {
+		t.Errorf("seed after a conflicting put = %x, %v; want %x", got, err, seed)
+	}
+	if err := k.Put("b", "not hex"); err == nil {
+		t.Error("put of an invalid key succeeded")
+	}
+	if _, err := k.seed("b"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("seed of an actor without a key: err = %v, want ErrNoKey", err)
+	}
+
+	info, err := os.Stat(filepath.Join(k.Dir(), "a.key"))
+	if err != nil {
+		t.Fatalf("stat key file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if dir, err := os.Stat(k.Dir()); err != nil || dir.Mode().Perm() != 0o700 {
+		t.Errorf("key directory = %v, %v; want mode 0700", dir, err)
+	}
+}
+
+func TestKeysGenerateRace(t *testing.T) {
+	k := NewKeys(t.TempDir())
+	const n = 16
+	seeds := make([][]byte, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seeds[i], errs[i] = k.generate("mind")
+		}()
+	}
+	wg.Wait()
+
+	for i := range n {
+		if errs[i] != nil {
+			t.Fatalf("generate %d: %v", i, errs[i])
+		}
+		if !bytes.Equal(seeds[i], seeds[0]) {
+			t.Fatalf("generate %d = %x, want %x: concurrent callers must agree", i, seeds[i], seeds[0])
+		}
+	}
+	if got, err := k.seed("mind"); err != nil || !bytes.Equal(got, seeds[0]) {
+		t.Errorf("stored seed = %x, %v; want %x", got, err, seeds[0])
+	}
+	entries, err := os.ReadDir(k.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "mind.key" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("key directory holds %v, want only mind.key", names)
+	}
+}
+
+func TestKeysPath(t *testing.T) {
+	dir := t.TempDir()
+	k := NewKeys(dir)
+	seed, err := newSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", ".", "..", ".hidden", "../escape", "a/b", "/abs"} {
+		if _, err := k.path(id); err == nil {
+			t.Errorf("path(%q): expected an error", id)
+		}
+		if err := k.Put(id, hex.EncodeToString(seed)); err == nil {
+			t.Errorf("put %q: expected an error", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.key")); err == nil {
+		t.Error("put wrote a key outside its directory")
+	}
+	path, err := k.path("0195f3c2-7b1e-7c4a-9d2f-3a4b5c6d7e8f")
+	if err != nil || path != filepath.Join(dir, "0195f3c2-7b1e-7c4a-9d2f-3a4b5c6d7e8f.key") {
+		t.Errorf("path of an actor ID = %q, %v", path, err)
+	}
+}
@@ -2,60 +2,57 @@ package actor
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // PgStore is a PostgreSQL-backed actor store.
 type PgStore struct {
-	pool *pgxpool.Pool
+	pool pgConn
+	keys *Keys
 }
 
-// NewPgStore creates a PgStore.
-func NewPgStore(pool *pgxpool.Pool) *PgStore {
-	return &PgStore{pool: pool}
+// NewPgStore creates a PgStore keeping private keys in keys.
+func NewPgStore(pool *pgxpool.Pool, keys *Keys) *PgStore {
+	return &PgStore{pool: pool, keys: keys}
 }
 
-// EnsureTable creates the actors table if it doesn't exist.
-func (s *PgStore) EnsureTable(ctx context.Context) error {
-	_, err := s.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS actors (
-			id         TEXT PRIMARY KEY,
-			type       TEXT NOT NULL,
-			name       TEXT NOT NULL,
-			email      TEXT,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
-		)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS actors_type_name_idx ON actors(type, name)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS actors_email_idx ON actors(email) WHERE email IS NOT NULL`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS actors_name_idx ON actors(name)`)
-	return err
+// NewPgStoreTx creates a PgStore whose statements all run in tx, so its
+// writes commit or roll back with the caller's. It is only usable until tx
+// ends.
+func NewPgStoreTx(tx pgx.Tx, keys *Keys) *PgStore {
+	return &PgStore{pool: tx, keys: keys}
+}
+
+// pgConn is the part of *pgxpool.Pool the store uses. pgx.Tx has it too, so
+// a store can run inside a caller's transaction (see NewPgStoreTx).
+type pgConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
+const actorColumns = `id, type, name, email, created_at, public_key, key_created_at`
+
 // Register creates or returns an existing actor. Idempotent.
 func (s *PgStore) Register(ctx context.Context, actorType, name, email string) (*Actor, error) {
 	// Try to find existing by email
 	if email != "" {
-		a, err := s.scanOne(ctx, `SELECT id, type, name, email, created_at FROM actors WHERE email = $1`, email)
+		a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE email = $1`, email)
 		if err == nil {
 			return a, nil
 		}
 	}
 
 	// Try to find by type + name
-	a, err := s.scanOne(ctx, `SELECT id, type, name, email, created_at FROM actors WHERE type = $1 AND name = $2`, actorType, name)
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE type = $1 AND name = $2`, actorType, name)
 	if err == nil {
 		return a, nil
 	}
@@ -75,7 +72,7 @@ func (s *PgStore) Register(ctx context.Context, actorType, name, email string) (
 	}
 
 	// Re-fetch to handle race conditions (ON CONFLICT DO NOTHING)
-	a, err = s.scanOne(ctx, `SELECT id, type, name, email, created_at FROM actors WHERE type = $1 AND name = $2`, actorType, name)
+	a, err = s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE type = $1 AND name = $2`, actorType, name)
 	if err != nil {
 		return nil, fmt.Errorf("register actor %s/%s: re-fetch failed: %w", actorType, name, err)
 	}
@@ -84,7 +81,7 @@ func (s *PgStore) Register(ctx context.Context, actorType, name, email string) (
 
 // Get returns an actor by ID.
 func (s *PgStore) Get(ctx context.Context, id string) (*Actor, error) {
-	a, err := s.scanOne(ctx, `SELECT id, type, name, email, created_at FROM actors WHERE id = $1`, id)
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE id = $1`, id)
 	if err != nil {
 		return nil, fmt.Errorf("get actor %s: %w", id, err)
 	}
@@ -93,7 +90,7 @@ func (s *PgStore) Get(ctx context.Context, id string) (*Actor, error) {
 
 // ByName returns an actor by name.
 func (s *PgStore) ByName(ctx context.Context, name string) (*Actor, error) {
-	a, err := s.scanOne(ctx, `SELECT id, type, name, email, created_at FROM actors WHERE name = $1`, name)
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE name = $1`, name)
 	if err != nil {
 		return nil, fmt.Errorf("actor by name %s: %w", name, err)
 	}
@@ -102,7 +99,7 @@ func (s *PgStore) ByName(ctx context.Context, name string) (*Actor, error) {
 
 // List returns all actors.
 func (s *PgStore) List(ctx context.Context) ([]Actor, error) {
-	rows, err := s.pool.Query(ctx, `SELECT id, type, name, email, created_at FROM actors ORDER BY created_at ASC`)
+	rows, err := s.pool.Query(ctx, `SELECT `+actorColumns+` FROM actors ORDER BY created_at ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("list actors: %w", err)
 	}
@@ -110,29 +107,106 @@ func (s *PgStore) List(ctx context.Context) ([]Actor, error) {
 
 	var actors []Actor
 	for rows.Next() {
-		var a Actor
-		var email *string
-		if err := rows.Scan(&a.ID, &a.Type, &a.Name, &email, &a.CreatedAt); err != nil {
+		a, err := scanActor(rows)
+		if err != nil {
 			return nil, err
 		}
-		if email != nil {
-			a.Email = *email
-		}
-		actors = append(actors, a)
+		actors = append(actors, *a)
 	}
 	return actors, rows.Err()
 }
 
+// GenerateKey gives an actor an Ed25519 keypair unless it already has one.
+func (s *PgStore) GenerateKey(ctx context.Context, id string) (*Actor, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil || a.PublicKey != "" {
+		return a, err
+	}
+	seed, err := s.keys.generate(id)
+	if err != nil {
+		return nil, err
+	}
+	// Only fills an empty key, so concurrent callers agree on one keypair.
+	_, err = s.pool.Exec(ctx, `
+		UPDATE actors SET public_key = $2, key_created_at = $3
+		WHERE id = $1 AND public_key IS NULL`,
+		id, publicKeyHex(seed), time.Now().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, fmt.Errorf("generate key for actor %s: %w", id, err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Signer returns a signer holding the actor's private key.
+func (s *PgStore) Signer(ctx context.Context, id string) (*Signer, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("signer: %w", err)
+	}
+	return s.keys.signer(a)
+}
+
+// PublicKey returns the signing key for events from source, if any.
+func (s *PgStore) PublicKey(ctx context.Context, source string) (ed25519.PublicKey, time.Time, error) {
+	var pub string
+	var since time.Time
+	err := s.pool.QueryRow(ctx, `
+		SELECT public_key, key_created_at FROM actors
+		WHERE name = $1 AND public_key IS NOT NULL
+		ORDER BY created_at ASC LIMIT 1`, source).Scan(&pub, &since)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("public key for %s: %w", source, err)
+	}
+	key, err := decodePublicKey(pub)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("public key for %s: %w", source, err)
+	}
+	return key, since, nil
+}
+
+// PrivateKey returns the actor's private seed, if any.
+func (s *PgStore) PrivateKey(ctx context.Context, id string) (string, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("private key: %w", err)
+	}
+	return s.keys.privateKey(a)
+}
+
+// Import inserts an actor as given.
+func (s *PgStore) Import(ctx context.Context, a *Actor, privateKey string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO actors (`+actorColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		a.ID, a.Type, a.Name, nilIfEmpty(a.Email), a.CreatedAt, nilIfEmpty(a.PublicKey), a.KeyCreatedAt)
+	if err != nil {
+		return fmt.Errorf("import actor %s: %w", a.ID, err)
+	}
+	if privateKey == "" {
+		return nil
+	}
+	return s.keys.importKey(a, privateKey)
+}
+
 func (s *PgStore) scanOne(ctx context.Context, query string, args ...any) (*Actor, error) {
+	return scanActor(s.pool.QueryRow(ctx, query, args...))
+}
+
+func scanActor(row pgx.Row) (*Actor, error) {
 	var a Actor
-	var email *string
-	err := s.pool.QueryRow(ctx, query, args...).Scan(&a.ID, &a.Type, &a.Name, &email, &a.CreatedAt)
-	if err != nil {
+	var email, publicKey *string
+	if err := row.Scan(&a.ID, &a.Type, &a.Name, &email, &a.CreatedAt, &publicKey, &a.KeyCreatedAt); err != nil {
 		return nil, err
 	}
 	if email != nil {
 		a.Email = *email
 	}
+	if publicKey != nil {
+		a.PublicKey = *publicKey
+	}
 	return &a, nil
 }
 
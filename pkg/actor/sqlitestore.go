@@ -0,0 +1,211 @@
+package actor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteStore is a SQLite-backed actor store.
+type SQLiteStore struct {
+	db   sqlConn
+	keys *Keys
+}
+
+// NewSQLiteStore creates a SQLiteStore keeping private keys in keys. The
+// caller registers the driver.
+func NewSQLiteStore(db *sql.DB, keys *Keys) *SQLiteStore {
+	return &SQLiteStore{db: db, keys: keys}
+}
+
+// NewSQLiteStoreTx creates a SQLiteStore whose statements all run in tx, so
+// its writes commit or roll back with the caller's. It is only usable until
+// tx ends.
+func NewSQLiteStoreTx(tx *sql.Tx, keys *Keys) *SQLiteStore {
+	return &SQLiteStore{db: tx, keys: keys}
+}
+
+// sqlConn is the part of *sql.DB the store uses. *sql.Tx has it too, so a
+// store can run inside a caller's transaction (see NewSQLiteStoreTx).
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Register creates or returns an existing actor. Idempotent.
+func (s *SQLiteStore) Register(ctx context.Context, actorType, name, email string) (*Actor, error) {
+	if email != "" {
+		a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE email = ?`, email)
+		if err == nil {
+			return a, nil
+		}
+	}
+
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE type = ? AND name = ?`, actorType, name)
+	if err == nil {
+		return a, nil
+	}
+
+	id := uuid.Must(uuid.NewV7()).String()
+	now := time.Now().Truncate(time.Microsecond)
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO actors (id, type, name, email, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		id, actorType, name, nilIfEmpty(email), now.UnixMicro())
+	if err != nil {
+		return nil, fmt.Errorf("register actor %s/%s: %w", actorType, name, err)
+	}
+
+	// Re-fetch to handle races with other processes (ON CONFLICT DO NOTHING)
+	a, err = s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE type = ? AND name = ?`, actorType, name)
+	if err != nil {
+		return nil, fmt.Errorf("register actor %s/%s: re-fetch failed: %w", actorType, name, err)
+	}
+	return a, nil
+}
+
+// Get returns an actor by ID.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Actor, error) {
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("get actor %s: %w", id, err)
+	}
+	return a, nil
+}
+
+// ByName returns an actor by name.
+func (s *SQLiteStore) ByName(ctx context.Context, name string) (*Actor, error) {
+	a, err := s.scanOne(ctx, `SELECT `+actorColumns+` FROM actors WHERE name = ?`, name)
+	if err != nil {
+		return nil, fmt.Errorf("actor by name %s: %w", name, err)
+	}
+	return a, nil
+}
+
+// List returns all actors.
+func (s *SQLiteStore) List(ctx context.Context) ([]Actor, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+actorColumns+` FROM actors ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list actors: %w", err)
+	}
+	defer rows.Close()
+
+	var actors []Actor
+	for rows.Next() {
+		a, err := scanSQLiteActor(rows)
+		if err != nil {
+			return nil, err
+		}
+		actors = append(actors, *a)
+	}
+	return actors, rows.Err()
+}
+
+// GenerateKey gives an actor an Ed25519 keypair unless it already has one.
+func (s *SQLiteStore) GenerateKey(ctx context.Context, id string) (*Actor, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil || a.PublicKey != "" {
+		return a, err
+	}
+	seed, err := s.keys.generate(id)
+	if err != nil {
+		return nil, err
+	}
+	// Only fills an empty key, so concurrent callers agree on one keypair.
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE actors SET public_key = ?, key_created_at = ?
+		WHERE id = ? AND public_key IS NULL`,
+		publicKeyHex(seed), time.Now().Truncate(time.Microsecond).UnixMicro(), id)
+	if err != nil {
+		return nil, fmt.Errorf("generate key for actor %s: %w", id, err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Signer returns a signer holding the actor's private key.
+func (s *SQLiteStore) Signer(ctx context.Context, id string) (*Signer, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("signer: %w", err)
+	}
+	return s.keys.signer(a)
+}
+
+// PublicKey returns the signing key for events from source, if any.
+func (s *SQLiteStore) PublicKey(ctx context.Context, source string) (ed25519.PublicKey, time.Time, error) {
+	var pub string
+	var since int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT public_key, key_created_at FROM actors
+		WHERE name = ? AND public_key IS NOT NULL
+		ORDER BY created_at ASC LIMIT 1`, source).Scan(&pub, &since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("public key for %s: %w", source, err)
+	}
+	key, err := decodePublicKey(pub)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("public key for %s: %w", source, err)
+	}
+	return key, time.UnixMicro(since), nil
+}
+
+// PrivateKey returns the actor's private seed, if any.
+func (s *SQLiteStore) PrivateKey(ctx context.Context, id string) (string, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("private key: %w", err)
+	}
+	return s.keys.privateKey(a)
+}
+
+// Import inserts an actor as given.
+func (s *SQLiteStore) Import(ctx context.Context, a *Actor, privateKey string) error {
+	var keyCreated sql.NullInt64
+	if a.KeyCreatedAt != nil {
+		keyCreated = sql.NullInt64{Int64: a.KeyCreatedAt.UnixMicro(), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO actors (`+actorColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.Type, a.Name, nilIfEmpty(a.Email), a.CreatedAt.UnixMicro(), nilIfEmpty(a.PublicKey), keyCreated)
+	if err != nil {
+		return fmt.Errorf("import actor %s: %w", a.ID, err)
+	}
+	if privateKey == "" {
+		return nil
+	}
+	return s.keys.importKey(a, privateKey)
+}
+
+func (s *SQLiteStore) scanOne(ctx context.Context, query string, args ...any) (*Actor, error) {
+	return scanSQLiteActor(s.db.QueryRowContext(ctx, query, args...))
+}
+
+func scanSQLiteActor(row interface{ Scan(dest ...any) error }) (*Actor, error) {
+	var a Actor
+	var email, publicKey sql.NullString
+	var created int64
+	var keyCreated sql.NullInt64
+	if err := row.Scan(&a.ID, &a.Type, &a.Name, &email, &created, &publicKey, &keyCreated); err != nil {
+		return nil, err
+	}
+	a.Email = email.String
+	a.CreatedAt = time.UnixMicro(created)
+	a.PublicKey = publicKey.String
+	if keyCreated.Valid {
+		t := time.UnixMicro(keyCreated.Int64)
+		a.KeyCreatedAt = &t
+	}
+	return &a, nil
+}
@@ -3,6 +3,8 @@ package authority
 import (
 	"context"
 	"time"
+
+	"mind-zero-five/pkg/page"
 )
 
 // Level determines how an approval request is handled.
@@ -45,6 +47,9 @@ type Store interface {
 	Get(ctx context.Context, id string) (*Request, error)
 	Pending(ctx context.Context) ([]Request, error)
 	Recent(ctx context.Context, limit int) ([]Request, error)
+	// RecentPage pages through requests newest first, starting from a
+	// cursor of a previous page ("" for the first).
+	RecentPage(ctx context.Context, cursor string, limit int) (*page.Page[Request], error)
 	PendingCount(ctx context.Context) (int, error)
 
 	// Policies
@@ -52,5 +57,13 @@ type Store interface {
 	MatchPolicy(ctx context.Context, action string) (*Policy, error)
 	ListPolicies(ctx context.Context) ([]Policy, error)
 
-	EnsureTable(ctx context.Context) error
+	// ImportRequest and ImportPolicy insert r or p exactly as given, ID and
+	// timestamps included, for moving them between databases.
+	ImportRequest(ctx context.Context, r *Request) error
+	ImportPolicy(ctx context.Context, p *Policy) error
+}
+
+// pageKey is a request's position in RecentPage order.
+func pageKey(r *Request) (time.Time, string) {
+	return r.CreatedAt, r.ID
 }
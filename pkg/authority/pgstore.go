@@ -6,12 +6,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mind-zero-five/pkg/page"
 )
 
 // PgStore is a PostgreSQL-backed authority store.
 type PgStore struct {
-	pool *pgxpool.Pool
+	pool pgConn
 }
 
 // NewPgStore creates a PgStore.
@@ -19,41 +23,20 @@ func NewPgStore(pool *pgxpool.Pool) *PgStore {
 	return &PgStore{pool: pool}
 }
 
-// EnsureTable creates the approval_requests table if it doesn't exist.
-func (s *PgStore) EnsureTable(ctx context.Context) error {
-	_, err := s.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS approval_requests (
-			id          TEXT PRIMARY KEY,
-			action      TEXT NOT NULL,
-			description TEXT NOT NULL DEFAULT '',
-			level       TEXT NOT NULL,
-			source      TEXT NOT NULL DEFAULT '',
-			status      TEXT NOT NULL DEFAULT 'pending',
-			created_at  TIMESTAMPTZ DEFAULT NOW(),
-			resolved_at TIMESTAMPTZ
-		)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_approval_status ON approval_requests(status, created_at)`)
-	if err != nil {
-		return err
-	}
-
-	// Policies table
-	_, err = s.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS authority_policies (
-			id          TEXT PRIMARY KEY,
-			action      TEXT NOT NULL,
-			approver_id TEXT NOT NULL,
-			level       TEXT NOT NULL,
-			created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
-		)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_action ON authority_policies(action)`)
-	return err
+// NewPgStoreTx creates a PgStore whose statements all run in tx, so its
+// writes commit or roll back with the caller's. It is only usable until tx
+// ends.
+func NewPgStoreTx(tx pgx.Tx) *PgStore {
+	return &PgStore{pool: tx}
+}
+
+// pgConn is the part of *pgxpool.Pool the store uses. pgx.Tx has it too, so
+// a store can run inside a caller's transaction (see NewPgStoreTx).
+type pgConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 // Create inserts a new approval request. Notification level auto-approves immediately.
@@ -155,6 +138,33 @@ func (s *PgStore) Recent(ctx context.Context, limit int) ([]Request, error) {
 	return scanRequestRows(rows)
 }
 
+// RecentPage returns a page of requests, newest first.
+func (s *PgStore) RecentPage(ctx context.Context, cursor string, limit int) (*page.Page[Request], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	where, args := "", []any{limit + 1}
+	cmp, order := c.Keyset(true)
+	if !c.IsZero() {
+		where, args = "WHERE (created_at, id) "+cmp+" ($2, $3)", append(args, c.Time, c.ID)
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, action, description, level, source, status, created_at, resolved_at
+		FROM approval_requests `+where+`
+		ORDER BY created_at `+order+`, id `+order+`
+		LIMIT $1`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("recent requests: %w", err)
+	}
+	defer rows.Close()
+	reqs, err := scanRequestRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return page.Build(reqs, limit, c, pageKey), nil
+}
+
 // PendingCount returns the number of pending requests.
 func (s *PgStore) PendingCount(ctx context.Context) (int, error) {
 	var n int
@@ -225,6 +235,30 @@ func (s *PgStore) ListPolicies(ctx context.Context) ([]Policy, error) {
 	return policies, rows.Err()
 }
 
+// ImportRequest inserts a request as given.
+func (s *PgStore) ImportRequest(ctx context.Context, r *Request) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO approval_requests (id, action, description, level, source, status, created_at, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		r.ID, r.Action, r.Description, string(r.Level), r.Source, r.Status, r.CreatedAt, r.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("import request %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// ImportPolicy inserts a policy as given.
+func (s *PgStore) ImportPolicy(ctx context.Context, p *Policy) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO authority_policies (id, action, approver_id, level, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		p.ID, p.Action, p.ApproverID, string(p.Level), p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("import policy %s: %w", p.Action, err)
+	}
+	return nil
+}
+
 func scanRequestRows(rows interface {
 	Next() bool
 	Scan(dest ...any) error
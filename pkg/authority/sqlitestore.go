@@ -0,0 +1,284 @@
+package authority
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mind-zero-five/pkg/page"
+)
+
+// SQLiteStore is a SQLite-backed authority store. Timestamps are stored as
+// Unix microseconds.
+type SQLiteStore struct {
+	db sqlConn
+}
+
+// NewSQLiteStore creates a SQLiteStore. The caller registers the driver.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// NewSQLiteStoreTx creates a SQLiteStore whose statements all run in tx, so
+// its writes commit or roll back with the caller's. It is only usable until
+// tx ends.
+func NewSQLiteStoreTx(tx *sql.Tx) *SQLiteStore {
+	return &SQLiteStore{db: tx}
+}
+
+// sqlConn is the part of *sql.DB the store uses. *sql.Tx has it too, so a
+// store can run inside a caller's transaction (see NewSQLiteStoreTx).
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const (
+	sqliteRequestColumns = `id, action, description, level, source, status, created_at, resolved_at`
+	sqlitePolicyColumns  = `id, action, approver_id, level, created_at`
+)
+
+// Create inserts a new approval request. Notification level auto-approves immediately.
+func (s *SQLiteStore) Create(ctx context.Context, action, description, source string, level Level) (*Request, error) {
+	now := time.Now().Truncate(time.Microsecond)
+	r := &Request{
+		ID:          uuid.Must(uuid.NewV7()).String(),
+		Action:      action,
+		Description: description,
+		Level:       level,
+		Source:      source,
+		Status:      "pending",
+		CreatedAt:   now,
+	}
+
+	var resolved sql.NullInt64
+	if level == Notification {
+		r.Status = "approved"
+		r.ResolvedAt = &now
+		resolved = sql.NullInt64{Int64: now.UnixMicro(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_requests (id, action, description, level, source, status, created_at, resolved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Action, r.Description, string(r.Level), r.Source, r.Status, r.CreatedAt.UnixMicro(), resolved)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	return r, nil
+}
+
+// Resolve approves or rejects a pending request.
+func (s *SQLiteStore) Resolve(ctx context.Context, id string, approved bool) (*Request, error) {
+	status := "rejected"
+	if approved {
+		status = "approved"
+	}
+	now := time.Now().Truncate(time.Microsecond)
+
+	r, err := scanSQLiteRequest(s.db.QueryRowContext(ctx, `
+		UPDATE approval_requests SET status = ?, resolved_at = ?
+		WHERE id = ? AND status = 'pending'
+		RETURNING `+sqliteRequestColumns,
+		status, now.UnixMicro(), id))
+	if err != nil {
+		return nil, fmt.Errorf("resolve request %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// Get retrieves a single request by ID.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Request, error) {
+	r, err := scanSQLiteRequest(s.db.QueryRowContext(ctx, `
+		SELECT `+sqliteRequestColumns+` FROM approval_requests WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("get request %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// Pending returns all pending approval requests.
+func (s *SQLiteStore) Pending(ctx context.Context) ([]Request, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+sqliteRequestColumns+` FROM approval_requests WHERE status = 'pending'
+		ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("pending requests: %w", err)
+	}
+	defer rows.Close()
+	return scanSQLiteRequestRows(rows)
+}
+
+// Recent returns the most recently created approval requests.
+func (s *SQLiteStore) Recent(ctx context.Context, limit int) ([]Request, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+sqliteRequestColumns+` FROM approval_requests
+		ORDER BY created_at DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("recent requests: %w", err)
+	}
+	defer rows.Close()
+	return scanSQLiteRequestRows(rows)
+}
+
+// RecentPage returns a page of requests, newest first.
+func (s *SQLiteStore) RecentPage(ctx context.Context, cursor string, limit int) (*page.Page[Request], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	where, args := "", []any{}
+	cmp, order := c.Keyset(true)
+	if !c.IsZero() {
+		where, args = "WHERE (created_at, id) "+cmp+" (?, ?)", []any{c.Time.UnixMicro(), c.ID}
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+sqliteRequestColumns+` FROM approval_requests `+where+`
+		ORDER BY created_at `+order+`, id `+order+`
+		LIMIT ?`, append(args, limit+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("recent requests: %w", err)
+	}
+	defer rows.Close()
+	reqs, err := scanSQLiteRequestRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return page.Build(reqs, limit, c, pageKey), nil
+}
+
+// PendingCount returns the number of pending requests.
+func (s *SQLiteStore) PendingCount(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM approval_requests WHERE status = 'pending'`).Scan(&n)
+	return n, err
+}
+
+// CreatePolicy creates or updates a policy for an action. Idempotent on action.
+func (s *SQLiteStore) CreatePolicy(ctx context.Context, action, approverID string, level Level) (*Policy, error) {
+	id := uuid.Must(uuid.NewV7()).String()
+	now := time.Now().Truncate(time.Microsecond)
+
+	p, err := scanSQLitePolicy(s.db.QueryRowContext(ctx, `
+		INSERT INTO authority_policies (id, action, approver_id, level, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (action) DO UPDATE SET approver_id = excluded.approver_id, level = excluded.level
+		RETURNING `+sqlitePolicyColumns,
+		id, action, approverID, string(level), now.UnixMicro()))
+	if err != nil {
+		return nil, fmt.Errorf("create policy for %s: %w", action, err)
+	}
+	return p, nil
+}
+
+// MatchPolicy finds the policy for an action. Tries exact match first, then "*" fallback.
+func (s *SQLiteStore) MatchPolicy(ctx context.Context, action string) (*Policy, error) {
+	p, err := scanSQLitePolicy(s.db.QueryRowContext(ctx, `
+		SELECT `+sqlitePolicyColumns+` FROM authority_policies WHERE action = ?`, action))
+	if err == nil {
+		return p, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("match policy %s: %w", action, err)
+	}
+
+	p, err = scanSQLitePolicy(s.db.QueryRowContext(ctx, `
+		SELECT `+sqlitePolicyColumns+` FROM authority_policies WHERE action = '*'`))
+	if err != nil {
+		return nil, fmt.Errorf("no policy for action %s: %w", action, err)
+	}
+	return p, nil
+}
+
+// ListPolicies returns all policies.
+func (s *SQLiteStore) ListPolicies(ctx context.Context) ([]Policy, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sqlitePolicyColumns+` FROM authority_policies ORDER BY action`)
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []Policy
+	for rows.Next() {
+		p, err := scanSQLitePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, *p)
+	}
+	return policies, rows.Err()
+}
+
+// ImportRequest inserts a request as given.
+func (s *SQLiteStore) ImportRequest(ctx context.Context, r *Request) error {
+	var resolved sql.NullInt64
+	if r.ResolvedAt != nil {
+		resolved = sql.NullInt64{Int64: r.ResolvedAt.UnixMicro(), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_requests (`+sqliteRequestColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Action, r.Description, string(r.Level), r.Source, r.Status, r.CreatedAt.UnixMicro(), resolved)
+	if err != nil {
+		return fmt.Errorf("import request %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// ImportPolicy inserts a policy as given.
+func (s *SQLiteStore) ImportPolicy(ctx context.Context, p *Policy) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO authority_policies (`+sqlitePolicyColumns+`)
+		VALUES (?, ?, ?, ?, ?)`,
+		p.ID, p.Action, p.ApproverID, string(p.Level), p.CreatedAt.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("import policy %s: %w", p.Action, err)
+	}
+	return nil
+}
+
+func scanSQLiteRequest(row interface{ Scan(dest ...any) error }) (*Request, error) {
+	var r Request
+	var created int64
+	var resolved sql.NullInt64
+	if err := row.Scan(&r.ID, &r.Action, &r.Description, &r.Level, &r.Source, &r.Status, &created, &resolved); err != nil {
+		return nil, err
+	}
+	r.CreatedAt = time.UnixMicro(created)
+	if resolved.Valid {
+		t := time.UnixMicro(resolved.Int64)
+		r.ResolvedAt = &t
+	}
+	return &r, nil
+}
+
+func scanSQLiteRequestRows(rows *sql.Rows) ([]Request, error) {
+	var reqs []Request
+	for rows.Next() {
+		r, err := scanSQLiteRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration: %w", err)
+	}
+	return reqs, nil
+}
+
+func scanSQLitePolicy(row interface{ Scan(dest ...any) error }) (*Policy, error) {
+	var p Policy
+	var created int64
+	if err := row.Scan(&p.ID, &p.Action, &p.ApproverID, &p.Level, &created); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = time.UnixMicro(created)
+	return &p, nil
+}
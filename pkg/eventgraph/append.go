@@ -0,0 +1,193 @@
+package eventgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCause is returned (wrapped) by Append in CausesStrict mode when
+// a cause doesn't name an event already on the chain.
+var ErrInvalidCause = errors.New("invalid cause")
+
+// InvalidCausesKey is the content field Append adds in CausesLenient mode to
+// events citing causes that aren't on the chain. The bad IDs are moved out of
+// causes into it, so causes only ever links to earlier events.
+const InvalidCausesKey = "_invalid_causes"
+
+// CauseMode says what Append does with a cause that isn't on the chain.
+type CauseMode int
+
+const (
+	CausesStrict  CauseMode = iota // reject the append with ErrInvalidCause
+	CausesLenient                  // keep the event, moving bad causes to InvalidCausesKey
+)
+
+func (m CauseMode) String() string {
+	if m == CausesLenient {
+		return "lenient"
+	}
+	return "strict"
+}
+
+// ParseCauseMode parses "strict" or "lenient". "" is strict.
+func ParseCauseMode(s string) (CauseMode, error) {
+	switch s {
+	case "", "strict":
+		return CausesStrict, nil
+	case "lenient":
+		return CausesLenient, nil
+	}
+	return CausesStrict, fmt.Errorf("cause mode %q: want strict or lenient", s)
+}
+
+// AppendRequest is one event to append with AppendBatch. The fields mean the
+// same as Append's parameters.
+type AppendRequest struct {
+	Type           string
+	Source         string
+	Content        map[string]any
+	Causes         []string
+	ConversationID string
+	Signer         Signer
+}
+
+// pendingEvent is an AppendRequest with its content marshaled, ready to be
+// linked onto the chain head.
+type pendingEvent struct {
+	req         AppendRequest
+	contentJSON []byte
+}
+
+// prepareBatch normalizes and marshals requests, masks secrets in their
+// content with scrubber and checks it against DefaultRegistry. Secrets are
+// masked first so that schema errors can't quote them. It does all the work
+// that doesn't depend on the chain head, so stores can do it before taking
+// their append lock.
+func prepareBatch(reqs []AppendRequest, scrubber *Scrubber) ([]pendingEvent, error) {
+	pending := make([]pendingEvent, len(reqs))
+	for i, r := range reqs {
+		if r.Content == nil {
+			r.Content = map[string]any{}
+		}
+		if r.Causes == nil {
+			r.Causes = []string{}
+		}
+		if _, ok := r.Content[RedactedKey]; ok {
+			return nil, fmt.Errorf("content field %s is reserved for redaction tombstones: %w", RedactedKey, ErrSchema)
+		}
+		if _, ok := r.Content[ScrubbedFieldsKey]; ok {
+			return nil, fmt.Errorf("content field %s is reserved for the scrubber: %w", ScrubbedFieldsKey, ErrSchema)
+		}
+		if _, ok := r.Content[SchemaErrorsKey]; ok {
+			return nil, fmt.Errorf("content field %s is reserved for schema errors: %w", SchemaErrorsKey, ErrSchema)
+		}
+		if _, ok := r.Content[InvalidCausesKey]; ok {
+			return nil, fmt.Errorf("content field %s is reserved for invalid causes: %w", InvalidCausesKey, ErrSchema)
+		}
+		contentJSON, err := json.Marshal(r.Content)
+		if err != nil {
+			return nil, fmt.Errorf("marshal content: %w", err)
+		}
+		pending[i] = pendingEvent{req: r, contentJSON: contentJSON}
+		scrubbed, err := scrubber.scrub(&pending[i])
+		if err != nil {
+			return nil, fmt.Errorf("scrub %s content: %w", r.Type, err)
+		}
+		if err := DefaultRegistry.conform(&pending[i]); err != nil {
+			return nil, err
+		}
+		if err := markScrubbed(&pending[i], scrubbed); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// causeIDs returns the distinct causes cited by pending, for stores to look
+// up under their append lock.
+func causeIDs(pending []pendingEvent) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, p := range pending {
+		for _, c := range p.req.Causes {
+			if !seen[c] {
+				seen[c] = true
+				ids = append(ids, c)
+			}
+		}
+	}
+	return ids
+}
+
+// checkCauses applies mode to pending, given which of causeIDs exist. Stores call it under their append lock: an event on the chain then
+// precedes everything linked onto the head, so existence is all there is to
+// check, and an ID that will only be minted later fails it too.
+func checkCauses(pending []pendingEvent, exists map[string]bool, mode CauseMode) error {
+	for i := range pending {
+		p := &pending[i]
+		valid := []string{}
+		var invalid []string
+		for _, c := range p.req.Causes {
+			if exists[c] {
+				valid = append(valid, c)
+			} else {
+				invalid = append(invalid, c)
+			}
+		}
+		if len(invalid) == 0 {
+			continue
+		}
+		if mode == CausesStrict {
+			return fmt.Errorf("%s: causes not on the chain: %s: %w", p.req.Type, strings.Join(invalid, ", "), ErrInvalidCause)
+		}
+		content := maps.Clone(p.req.Content)
+		content[InvalidCausesKey] = invalid
+		contentJSON, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("marshal content: %w", err)
+		}
+		p.req.Content, p.req.Causes, p.contentJSON = content, valid, contentJSON
+	}
+	return nil
+}
+
+// chainHead is the last event on the chain: what the next event links to.
+type chainHead struct {
+	hash      string
+	timestamp time.Time
+}
+
+// link turns p into the event that follows head, and advances head to it.
+// Timestamps strictly increase along the chain, even if the wall clock steps
+// back or several events land in the same microsecond, so chain order never
+// depends on comparing IDs minted by different processes.
+func (p *pendingEvent) link(head *chainHead) (*Event, error) {
+	now := time.Now().Truncate(time.Microsecond)
+	if !now.After(head.timestamp) {
+		now = head.timestamp.Add(time.Microsecond)
+	}
+	e := &Event{
+		ID:             uuid.Must(uuid.NewV7()).String(),
+		Type:           p.req.Type,
+		Timestamp:      now,
+		Source:         p.req.Source,
+		Content:        p.req.Content,
+		Causes:         p.req.Causes,
+		ConversationID: p.req.ConversationID,
+		PrevHash:       head.hash,
+		HashVersion:    CurrentHashVersion,
+	}
+	var err error
+	if e.Hash, err = hashEvent(e, p.contentJSON); err != nil {
+		return nil, fmt.Errorf("hash event: %w", err)
+	}
+	signEvent(e, p.req.Signer)
+	head.hash, head.timestamp = e.Hash, e.Timestamp
+	return e, nil
+}
@@ -0,0 +1,398 @@
+package eventgraph
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Retention. Old events can be moved out of the store into segment files,
+// oldest first: gzipped JSONL, one event per line with its content exactly
+// as hashed, and a JSON manifest beside each. For every archived event the
+// store keeps a stub (ID, timestamp, hash), so the chain still runs from
+// genesis: verification links the first live event to the last stub, the
+// Merkle tree keeps the stubs as its first leaves, and causes may still cite
+// archived events. Archived events can't be read, traversed or proven
+// included until their segment is restored.
+
+// ErrSegment is returned (wrapped) when a segment can't be archived or
+// restored as asked, or its files don't match what the store recorded.
+var ErrSegment = errors.New("invalid segment")
+
+// DefaultSegmentSize is the most events Archive puts in one segment when the
+// policy doesn't say.
+const DefaultSegmentSize = 10000
+
+// RetentionPolicy says which events Archive moves out of the store.
+type RetentionPolicy struct {
+	MaxAge      time.Duration // archive events older than this
+	SegmentSize int           // most events per segment; 0 = DefaultSegmentSize
+}
+
+// Segment is one archived run of events.
+type Segment struct {
+	Name           string    `json:"name"` // base name of its files in the archive directory
+	FirstID        string    `json:"first_id"`
+	LastID         string    `json:"last_id"`
+	FirstTimestamp time.Time `json:"first_timestamp"`
+	LastTimestamp  time.Time `json:"last_timestamp"`
+	Count          int       `json:"count"`
+	PrevHash       string    `json:"prev_hash"` // hash of the event before the segment
+	FirstHash      string    `json:"first_hash"`
+	LastHash       string    `json:"last_hash"`
+	SHA256         string    `json:"sha256"` // hex SHA-256 of the segment file
+	ArchivedAt     time.Time `json:"archived_at"`
+}
+
+// DataFile is the name of the segment's events file.
+func (seg *Segment) DataFile() string { return seg.Name + ".jsonl.gz" }
+
+// ManifestFile is the name of the segment's manifest, a copy of the Segment.
+func (seg *Segment) ManifestFile() string { return seg.Name + ".manifest.json" }
+
+// checkPrefix confirms that seg still covers the oldest live events, given
+// the first live event's ID, how many events run from it up to seg.LastID,
+// the hash of seg.LastID and whether any event follows it. The head is
+// never archived: appends link to it.
+func (seg *Segment) checkPrefix(firstID string, n int, lastHash string, later bool) error {
+	switch {
+	case firstID != seg.FirstID || n != seg.Count || lastHash != seg.LastHash:
+		return fmt.Errorf("archive %s: no longer the oldest %d events: %w", seg.Name, seg.Count, ErrSegment)
+	case !later:
+		return fmt.Errorf("archive %s: would archive the chain head: %w", seg.Name, ErrSegment)
+	}
+	return nil
+}
+
+// archivedStub is what a store keeps of an archived event.
+type archivedStub struct {
+	id        string
+	timestamp time.Time
+	hash      string
+}
+
+// segmentStore is implemented by each store so Archive, Restore and
+// ImportEvents can be shared.
+type segmentStore interface {
+	chainScanner
+
+	// archiveSegment replaces the events seg covers with stubs and records
+	// seg, failing (see Segment.checkPrefix) if they aren't the oldest live
+	// events or include the head.
+	archiveSegment(ctx context.Context, seg *Segment) error
+	// restoreSegment puts back the events of seg, which must be the newest
+	// segment, and forgets it.
+	restoreSegment(ctx context.Context, seg *Segment, events []StoredEvent) error
+	// importEvents stores events as they are, failing if the store holds any
+	// events, live or archived.
+	importEvents(ctx context.Context, events []StoredEvent) error
+}
+
+func asSegmentStore(store EventStore) (segmentStore, error) {
+	s, ok := store.(segmentStore)
+	if !ok {
+		return nil, fmt.Errorf("%T doesn't support moving events in and out", store)
+	}
+	return s, nil
+}
+
+// Archive moves the events policy says are too old into segment files in
+// dir, oldest first, and returns the segments written. It never archives the
+// chain head, the latest checkpoint's event or any consumer's position, nor
+// anything after them, so appends, VerifySince and Since keep working.
+// Every segment is written and read back intact before its events leave the
+// store, and Archive refuses to archive a broken chain.
+func Archive(ctx context.Context, store EventStore, dir string, policy RetentionPolicy) ([]Segment, error) {
+	s, err := asSegmentStore(store)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxAge < 0 {
+		return nil, fmt.Errorf("archive: negative max age %s", policy.MaxAge)
+	}
+	size := policy.SegmentSize
+	if size <= 0 {
+		size = DefaultSegmentSize
+	}
+	before, err := archiveLimit(ctx, store, time.Now().Add(-policy.MaxAge))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	var written []Segment
+	for {
+		batch, err := store.Since(ctx, "", size)
+		if err != nil {
+			return written, fmt.Errorf("archive: %w", err)
+		}
+		n := 0
+		for n < len(batch) && batch[n].Timestamp.Before(before) {
+			n++
+		}
+		if n == 0 {
+			return written, nil
+		}
+		seg, err := writeSegment(ctx, s, dir, batch[0].ID, batch[n-1].ID)
+		if err != nil {
+			return written, err
+		}
+		if err := s.archiveSegment(ctx, seg); err != nil {
+			return written, err
+		}
+		written = append(written, *seg)
+	}
+}
+
+// archiveLimit returns the time before which events may be archived: cutoff,
+// or earlier if the head, the latest checkpoint or a consumer needs an
+// earlier event kept.
+func archiveLimit(ctx context.Context, store EventStore, cutoff time.Time) (time.Time, error) {
+	var keep []string
+	head, err := store.Recent(ctx, 1)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("archive: chain head: %w", err)
+	}
+	for _, e := range head {
+		keep = append(keep, e.ID)
+	}
+	cp, err := store.LatestCheckpoint(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("archive: %w", err)
+	}
+	if cp != nil {
+		keep = append(keep, cp.EventID)
+	}
+	consumers, err := store.Consumers(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("archive: %w", err)
+	}
+	for _, c := range consumers {
+		keep = append(keep, c.EventID)
+	}
+	for _, id := range keep {
+		e, err := store.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue // already archived, or never existed
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("archive: %w", err)
+		}
+		if e.Timestamp.Before(cutoff) {
+			cutoff = e.Timestamp
+		}
+	}
+	return cutoff, nil
+}
+
+// writeSegment writes the events firstID..lastID, which must be the oldest
+// live events, to a segment file and its manifest in dir, then reads them
+// back to check they landed intact.
+func writeSegment(ctx context.Context, s segmentStore, dir, firstID, lastID string) (*Segment, error) {
+	prevHash, _, err := s.archivedHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	var events []StoredEvent
+	err = s.scanChain(ctx, firstID, true, lastID, func(e *Event, contentJSON []byte) {
+		events = append(events, StoredEvent{Event: *e, Content: append(json.RawMessage(nil), contentJSON...)})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("archive %s..%s: no events: %w", firstID, lastID, ErrSegment)
+	}
+	for _, e := range events {
+		if problems := linkProblems(&e.Event, prevHash, e.Content); len(problems) > 0 {
+			return nil, fmt.Errorf("archive: chain is broken at %s (%s); verify it before archiving: %w", e.ID, problems[0], ErrSegment)
+		}
+		prevHash = e.Hash
+	}
+
+	first, last := events[0], events[len(events)-1]
+	seg := &Segment{
+		Name:           "events-" + first.ID,
+		FirstID:        first.ID,
+		LastID:         last.ID,
+		FirstTimestamp: first.Timestamp,
+		LastTimestamp:  last.Timestamp,
+		Count:          len(events),
+		PrevHash:       first.PrevHash,
+		FirstHash:      first.Hash,
+		LastHash:       last.Hash,
+		ArchivedAt:     time.Now().Truncate(time.Microsecond),
+	}
+	if seg.SHA256, err = writeSegmentData(filepath.Join(dir, seg.DataFile()), events); err != nil {
+		return nil, fmt.Errorf("archive %s: %w", seg.Name, err)
+	}
+	manifest, err := json.MarshalIndent(seg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("archive %s: marshal manifest: %w", seg.Name, err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, seg.ManifestFile()), func(w io.Writer) error {
+		_, err := w.Write(append(manifest, '\n'))
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("archive %s: %w", seg.Name, err)
+	}
+	if _, err := readSegment(dir, seg); err != nil {
+		return nil, err
+	}
+	return seg, nil
+}
+
+// writeSegmentData writes events to path as gzipped JSONL and returns the
+// file's hex SHA-256.
+func writeSegmentData(path string, events []StoredEvent) (string, error) {
+	h := sha256.New()
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		zw := gzip.NewWriter(io.MultiWriter(w, h))
+		enc := json.NewEncoder(zw)
+		enc.SetEscapeHTML(false)
+		for i := range events {
+			if err := enc.Encode(&events[i]); err != nil {
+				return fmt.Errorf("encode %s: %w", events[i].ID, err)
+			}
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFileAtomic writes path through a temporary file, synced and renamed
+// into place, so a crash never leaves half a file under the real name.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readSegment reads seg's events back from dir, checking the manifest and
+// file against seg and every hash link from seg.PrevHash through
+// seg.LastHash.
+func readSegment(dir string, seg *Segment) ([]StoredEvent, error) {
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("segment %s: %s: %w", seg.Name, fmt.Sprintf(format, args...), ErrSegment)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, seg.ManifestFile()))
+	if err != nil {
+		return nil, fmt.Errorf("segment %s: %w", seg.Name, err)
+	}
+	var manifest Segment
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fail("manifest: %v", err)
+	}
+	if manifest.Name != seg.Name || manifest.FirstID != seg.FirstID || manifest.LastID != seg.LastID ||
+		manifest.Count != seg.Count || manifest.PrevHash != seg.PrevHash || manifest.FirstHash != seg.FirstHash ||
+		manifest.LastHash != seg.LastHash || manifest.SHA256 != seg.SHA256 {
+		return nil, fail("manifest doesn't match the store's record")
+	}
+
+	f, err := os.Open(filepath.Join(dir, seg.DataFile()))
+	if err != nil {
+		return nil, fmt.Errorf("segment %s: %w", seg.Name, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	zr, err := gzip.NewReader(io.TeeReader(f, h))
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	var events []StoredEvent
+	dec := json.NewDecoder(zr)
+	for {
+		var e StoredEvent
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fail("event %d: %v", len(events), err)
+		}
+		events = append(events, e)
+	}
+	if _, err := io.Copy(io.Discard, f); err != nil {
+		return nil, fmt.Errorf("segment %s: %w", seg.Name, err)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != seg.SHA256 {
+		return nil, fail("file hash is %s, want %s", sum, seg.SHA256)
+	}
+
+	if len(events) != seg.Count {
+		return nil, fail("holds %d events, want %d", len(events), seg.Count)
+	}
+	if events[0].ID != seg.FirstID || events[len(events)-1].ID != seg.LastID {
+		return nil, fail("runs %s..%s, want %s..%s", events[0].ID, events[len(events)-1].ID, seg.FirstID, seg.LastID)
+	}
+	prevHash := seg.PrevHash
+	for _, e := range events {
+		if problems := linkProblems(&e.Event, prevHash, e.Content); len(problems) > 0 {
+			return nil, fail("event %s: %s", e.ID, problems[0])
+		}
+		prevHash = e.Hash
+	}
+	if prevHash != seg.LastHash {
+		return nil, fail("ends at hash %s, want %s", prevHash, seg.LastHash)
+	}
+	return events, nil
+}
+
+// Restore puts the events of the newest segment back into the store from
+// its files in dir, after checking them against the store's record and
+// re-verifying every hash. Segments come back newest first, so the live
+// chain always continues from the last stub; name, if not "", must be the
+// newest segment's.
+func Restore(ctx context.Context, store EventStore, dir, name string) (*Segment, error) {
+	s, err := asSegmentStore(store)
+	if err != nil {
+		return nil, err
+	}
+	segs, err := store.Segments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("restore: nothing is archived: %w", ErrSegment)
+	}
+	seg := &segs[len(segs)-1]
+	if name != "" && name != seg.Name {
+		return nil, fmt.Errorf("restore %s: only the newest segment, %s, can be restored: %w", name, seg.Name, ErrSegment)
+	}
+	events, err := readSegment(dir, seg)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	if err := s.restoreSegment(ctx, seg, events); err != nil {
+		return nil, err
+	}
+	return seg, nil
+}
@@ -2,50 +2,256 @@ package eventgraph
 
 import (
 	"context"
+	"log"
+	"slices"
 	"sync"
+	"time"
 )
 
+// Subscriber is the fan-out half of a bus.
+type Subscriber interface {
+	// Subscribe returns a buffered channel that receives every new event. A
+	// subscriber that falls behind misses events.
+	Subscribe() chan *Event
+	// SubscribeFilter returns a channel that receives the new events matching
+	// f. If lossless is set, a subscriber that falls behind is caught up from
+	// the store instead of missing events.
+	SubscribeFilter(f Filter, lossless bool) chan *Event
+	// Unsubscribe removes a subscriber and closes its channel.
+	Unsubscribe(ch chan *Event)
+	// Stats reports delivery counters.
+	Stats() BusStats
+}
+
+// EventBus is an EventStore whose subscribers are told about new events.
+// Bus and PgBus implement it.
+type EventBus interface {
+	EventStore
+	Subscriber
+}
+
+// Filter selects events for a subscription. Empty fields match everything;
+// within Types or Sources any entry may match.
+type Filter struct {
+	Types        []string `json:"types,omitempty"`
+	Sources      []string `json:"sources,omitempty"`
+	Conversation string   `json:"conversation,omitempty"`
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e *Event) bool {
+	return (len(f.Types) == 0 || slices.Contains(f.Types, e.Type)) &&
+		(len(f.Sources) == 0 || slices.Contains(f.Sources, e.Source)) &&
+		(f.Conversation == "" || f.Conversation == e.ConversationID)
+}
+
+// BusStats counts what a bus has delivered since it was created.
+type BusStats struct {
+	Subscribers int    `json:"subscribers"`
+	Lagging     int    `json:"lagging"`    // lossless subscribers currently being backfilled
+	Published   uint64 `json:"published"`  // events fanned out
+	Delivered   uint64 `json:"delivered"`  // sent to a subscriber live
+	Dropped     uint64 `json:"dropped"`    // lost because a lossy subscriber was full
+	Backfilled  uint64 `json:"backfilled"` // sent to a lagging subscriber from the store
+}
+
+// subBuffer is each subscriber's channel capacity.
+const subBuffer = 64
+
+// subscriber is one subscription and its cursor: the furthest event, in chain
+// order, it has been sent or has had filtered out. Timestamps strictly
+// increase along the chain, so the cursor's timestamp orders events against
+// it. Guarded by Bus.mu.
+type subscriber struct {
+	ch         chan *Event
+	filter     Filter
+	lossless   bool
+	cursorID   string
+	cursorTime time.Time
+	// backfill is non-nil while the subscriber is lagging and a goroutine is
+	// feeding it from the store; it is closed when that goroutine exits.
+	backfill chan struct{}
+	done     chan struct{} // closed by Unsubscribe
+}
+
+func (s *subscriber) advance(e *Event) {
+	if e.Timestamp.After(s.cursorTime) {
+		s.cursorID, s.cursorTime = e.ID, e.Timestamp
+	}
+}
+
 // Bus wraps an EventStore with in-process fan-out notification.
 // When Append is called, all subscribers receive the new event.
 type Bus struct {
 	EventStore
-	mu   sync.RWMutex
-	subs map[chan *Event]struct{}
+	mu       sync.Mutex
+	subs     map[chan *Event]*subscriber
+	lastID   string    // newest event published, in chain order
+	lastTime time.Time // its timestamp
+	stats    BusStats
 }
 
 // NewBus creates a Bus wrapping the given store.
 func NewBus(store EventStore) *Bus {
 	return &Bus{
 		EventStore: store,
-		subs:       make(map[chan *Event]struct{}),
+		subs:       make(map[chan *Event]*subscriber),
 	}
 }
 
 // Append delegates to the underlying store, then fans out to all subscribers.
-func (b *Bus) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string) (*Event, error) {
-	e, err := b.EventStore.Append(ctx, eventType, source, content, causes, conversationID)
+func (b *Bus) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error) {
+	e, err := b.EventStore.Append(ctx, eventType, source, content, causes, conversationID, signer)
 	if err != nil {
 		return nil, err
 	}
 
-	b.mu.RLock()
-	for ch := range b.subs {
-		select {
-		case ch <- e:
-		default:
-			// subscriber is behind; drop to avoid blocking Append
-		}
+	b.Publish(e)
+	return e, nil
+}
+
+// AppendBatch delegates to the underlying store, then fans out each event in
+// chain order.
+func (b *Bus) AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error) {
+	events, err := b.EventStore.AppendBatch(ctx, reqs)
+	if err != nil {
+		return nil, err
 	}
-	b.mu.RUnlock()
+	b.Publish(events...)
+	return events, nil
+}
 
+// Redact delegates to the underlying store, then fans out the event recording
+// the redaction.
+func (b *Bus) Redact(ctx context.Context, id string, req RedactRequest) (*Event, error) {
+	e, err := b.EventStore.Redact(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	b.Publish(e)
 	return e, nil
 }
 
-// Subscribe returns a buffered channel that receives all new events.
+// Publish fans out events that were appended without going through the Bus,
+// e.g. inside a transaction that has since committed.
+func (b *Bus) Publish(events ...*Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range events {
+		b.stats.Published++
+		if e.Timestamp.After(b.lastTime) {
+			b.lastID, b.lastTime = e.ID, e.Timestamp
+		}
+		for _, s := range b.subs {
+			b.deliver(s, e)
+		}
+	}
+}
+
+// deliver sends e to s without blocking. Callers hold b.mu.
+func (b *Bus) deliver(s *subscriber, e *Event) {
+	if s.backfill != nil {
+		// Lagging: the backfill reads this event from the store in turn.
+		return
+	}
+	if !s.filter.Match(e) {
+		s.advance(e)
+		return
+	}
+	select {
+	case s.ch <- e:
+		b.stats.Delivered++
+		s.advance(e)
+	default:
+		// A full channel means the subscriber has a cursor to resume from.
+		if !s.lossless || s.cursorID == "" {
+			b.stats.Dropped++
+			s.advance(e)
+			return
+		}
+		s.backfill = make(chan struct{})
+		go b.backfillFrom(s, s.cursorID)
+	}
+}
+
+// backfillFrom feeds a lagging subscriber from the store, starting after
+// cursor, until it has caught up with what the bus has published.
+func (b *Bus) backfillFrom(s *subscriber, cursor string) {
+	const page = 100
+	finished := s.backfill
+	defer close(finished)
+
+	var cursorTime time.Time
+	for {
+		events, err := b.EventStore.Since(context.Background(), cursor, page)
+		if err != nil {
+			log.Printf("bus: backfill: %v", err)
+			select {
+			case <-s.done:
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for i := range events {
+			e := &events[i]
+			if s.filter.Match(e) {
+				select {
+				case s.ch <- e:
+				case <-s.done:
+					return
+				}
+				b.mu.Lock()
+				b.stats.Backfilled++
+				b.mu.Unlock()
+			}
+			cursor, cursorTime = e.ID, e.Timestamp
+		}
+		if len(events) == page {
+			continue
+		}
+
+		b.mu.Lock()
+		if !b.lastTime.After(cursorTime) {
+			// Caught up: go back to live delivery from here.
+			if cursorTime.After(s.cursorTime) {
+				s.cursorID, s.cursorTime = cursor, cursorTime
+			}
+			s.backfill = nil
+			b.mu.Unlock()
+			return
+		}
+		b.mu.Unlock()
+		// Published but not readable yet (e.g. still committing); retry shortly.
+		select {
+		case <-s.done:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// Subscribe returns a buffered channel that receives all new events. A
+// subscriber that falls behind misses events.
 func (b *Bus) Subscribe() chan *Event {
-	ch := make(chan *Event, 64)
+	return b.SubscribeFilter(Filter{}, false)
+}
+
+// SubscribeFilter returns a buffered channel that receives new events
+// matching f. With lossless set, a subscriber whose channel fills up is
+// backfilled from the store via Since once it drains, in chain order, rather
+// than missing events.
+func (b *Bus) SubscribeFilter(f Filter, lossless bool) chan *Event {
+	ch := make(chan *Event, subBuffer)
 	b.mu.Lock()
-	b.subs[ch] = struct{}{}
+	b.subs[ch] = &subscriber{
+		ch:         ch,
+		filter:     f,
+		lossless:   lossless,
+		cursorID:   b.lastID,
+		cursorTime: b.lastTime,
+		done:       make(chan struct{}),
+	}
 	b.mu.Unlock()
 	return ch
 }
@@ -53,7 +259,34 @@ func (b *Bus) Subscribe() chan *Event {
 // Unsubscribe removes a subscriber and closes its channel.
 func (b *Bus) Unsubscribe(ch chan *Event) {
 	b.mu.Lock()
+	s, ok := b.subs[ch]
 	delete(b.subs, ch)
+	var backfill chan struct{}
+	if ok {
+		backfill = s.backfill
+	}
 	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	close(s.done)
+	if backfill != nil {
+		// Wait for the backfill to stop sending before closing the channel.
+		<-backfill
+	}
 	close(ch)
 }
+
+// Stats reports delivery counters.
+func (b *Bus) Stats() BusStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.stats
+	st.Subscribers = len(b.subs)
+	for _, s := range b.subs {
+		if s.backfill != nil {
+			st.Lagging++
+		}
+	}
+	return st
+}
@@ -0,0 +1,106 @@
+package eventgraph
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan *Event) *Event {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestBusSubscribeFilter(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(NewMemStore(nil, CausesStrict))
+	ch := bus.SubscribeFilter(Filter{Types: []string{"task.created", "task.done"}, Sources: []string{"api"}}, false)
+	defer bus.Unsubscribe(ch)
+
+	bus.Append(ctx, "task.created", "mind", nil, nil, "", nil)
+	bus.Append(ctx, "mind.thought", "api", nil, nil, "", nil)
+	want, _ := bus.Append(ctx, "task.created", "api", nil, nil, "", nil)
+	if got := receive(t, ch); got.ID != want.ID {
+		t.Fatalf("got %s from %s, want the api task.created", got.Type, got.Source)
+	}
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected event %s from %s", e.Type, e.Source)
+	default:
+	}
+
+	conv := bus.SubscribeFilter(Filter{Conversation: "c1"}, false)
+	defer bus.Unsubscribe(conv)
+	bus.Append(ctx, "x", "api", nil, nil, "c2", nil)
+	want, _ = bus.Append(ctx, "x", "api", nil, nil, "c1", nil)
+	if got := receive(t, conv); got.ID != want.ID {
+		t.Fatalf("conversation filter let through %s", got.ConversationID)
+	}
+}
+
+func TestBusLossySubscriberDrops(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(NewMemStore(nil, CausesStrict))
+	ch := bus.Subscribe()
+	defer bus.Unsubscribe(ch)
+
+	for i := 0; i < subBuffer+10; i++ {
+		bus.Append(ctx, "test.event", "tester", nil, nil, "", nil)
+	}
+	st := bus.Stats()
+	if st.Delivered != subBuffer || st.Dropped != 10 || st.Published != subBuffer+10 {
+		t.Fatalf("stats: %+v", st)
+	}
+}
+
+func TestBusLosslessSubscriberBackfills(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(NewMemStore(nil, CausesStrict))
+	bus.Append(ctx, "test.before", "tester", nil, nil, "", nil)
+	ch := bus.SubscribeFilter(Filter{Types: []string{"test.event"}}, true)
+	defer bus.Unsubscribe(ch)
+
+	// Overflow the buffer several times over while nobody reads, with
+	// unmatched events mixed in.
+	var want []string
+	for i := 0; i < 3*subBuffer; i++ {
+		e, err := bus.Append(ctx, "test.event", "tester", map[string]any{"i": i}, nil, "", nil)
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		want = append(want, e.ID)
+		bus.Append(ctx, "test.noise", "tester", nil, nil, "", nil)
+	}
+	if st := bus.Stats(); st.Lagging != 1 {
+		t.Fatalf("expected the subscriber to be lagging: %+v", st)
+	}
+
+	for i, id := range want {
+		if got := receive(t, ch); got.ID != id {
+			t.Fatalf("event %d: got %s (%s), want %s", i, got.ID, got.Type, id)
+		}
+	}
+
+	// Once caught up, delivery is live again.
+	deadline := time.Now().Add(2 * time.Second)
+	for bus.Stats().Lagging != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber never caught up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	live, _ := bus.Append(ctx, "test.event", "tester", nil, nil, "", nil)
+	if got := receive(t, ch); got.ID != live.ID {
+		t.Fatalf("live event after backfill: got %s, want %s", got.ID, live.ID)
+	}
+	st := bus.Stats()
+	if st.Dropped != 0 || st.Backfilled == 0 || st.Delivered+st.Backfilled != uint64(len(want)+1) {
+		t.Fatalf("stats: %+v", st)
+	}
+}
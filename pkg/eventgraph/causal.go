@@ -0,0 +1,79 @@
+package eventgraph
+
+import (
+	"fmt"
+	"slices"
+)
+
+// shortestPath finds the fewest-hop causes path that leads from `from` to
+// `to` through nodes, and returns it in that order, from first. It returns
+// nil if there is none. nodes must include both ends.
+func shortestPath(nodes []Event, from, to string) []Event {
+	byID := make(map[string]*Event, len(nodes))
+	for i := range nodes {
+		byID[nodes[i].ID] = &nodes[i]
+	}
+	if byID[from] == nil || byID[to] == nil {
+		return nil
+	}
+	// Breadth first from `to` back through causes, remembering which effect
+	// each event was reached from.
+	effect := map[string]string{to: ""}
+	frontier := []string{to}
+	for len(frontier) > 0 && !hasKey(effect, from) {
+		var next []string
+		for _, id := range frontier {
+			for _, c := range byID[id].Causes {
+				if byID[c] == nil || hasKey(effect, c) {
+					continue
+				}
+				effect[c] = id
+				next = append(next, c)
+			}
+		}
+		frontier = next
+	}
+	if !hasKey(effect, from) {
+		return nil
+	}
+	var path []Event
+	for id := from; id != ""; id = effect[id] {
+		path = append(path, *byID[id])
+	}
+	return path
+}
+
+func hasKey(m map[string]string, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
+// commonIDs dedupes the IDs given to CommonAncestors, keeping their order.
+func commonIDs(ids []string) ([]string, error) {
+	var out []string
+	for _, id := range ids {
+		if !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("common ancestors: no events given: %w", ErrInvalidQuery)
+	}
+	return out, nil
+}
+
+// Kinds of CauseProblem.
+const (
+	CauseDangling = "dangling" // the cause isn't on the chain
+	CauseFuture   = "future"   // the cause doesn't precede the event citing it
+)
+
+// CauseProblem is one causes reference that breaks the causal DAG. Append
+// rejects these, but events written before it checked, or by hand, may hold
+// them. They can't be repaired in place: causes are covered by the hash.
+type CauseProblem struct {
+	EventID string `json:"event_id"`
+	Type    string `json:"type"`
+	Cause   string `json:"cause"`
+	Problem string `json:"problem"`
+}
@@ -0,0 +1,129 @@
+package eventgraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ConsumerState is a named consumer's durable position in the log.
+type ConsumerState struct {
+	Name    string    `json:"name"`
+	EventID string    `json:"event_id"` // last event acknowledged
+	AckedAt time.Time `json:"acked_at"`
+	Lag     int       `json:"lag"` // events after EventID, whether or not they match the consumer's filter
+}
+
+// consumerPoll is how often a consumer rereads the log without being woken,
+// in case it missed a wakeup (e.g. an append by another process on a
+// single-process bus).
+const consumerPoll = 30 * time.Second
+
+// Consumer is a durable, named reader of the event log. It delivers the
+// events matching its filter in chain order, starting after the last one it
+// acknowledged, so a restarted process picks up where it left off. Delivery
+// is at-least-once: anything delivered but not acked before a restart is
+// delivered again, so handlers must tolerate repeats.
+type Consumer struct {
+	name   string
+	bus    EventBus
+	filter Filter
+}
+
+// NewConsumer creates the consumer called name over bus. Consumers with
+// different names keep independent positions.
+func NewConsumer(bus EventBus, name string, f Filter) *Consumer {
+	return &Consumer{name: name, bus: bus, filter: f}
+}
+
+// Name returns the consumer's name.
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+// Start begins delivery on the returned channel, which is closed once ctx is
+// cancelled. A consumer that has never acked starts at the current head: its
+// first run records the head as its position, so events appended from then
+// on are delivered even if it restarts before acking any.
+func (c *Consumer) Start(ctx context.Context) (<-chan *Event, error) {
+	cursor, err := c.bus.ConsumerPosition(ctx, c.name)
+	if err != nil {
+		return nil, fmt.Errorf("consumer %s: %w", c.name, err)
+	}
+	// Subscribe before reading the head so nothing appended in between goes
+	// unnoticed.
+	wake := c.bus.SubscribeFilter(c.filter, false)
+	if cursor == "" {
+		head, err := c.bus.Recent(ctx, 1)
+		if err != nil {
+			c.bus.Unsubscribe(wake)
+			return nil, fmt.Errorf("consumer %s: read chain head: %w", c.name, err)
+		}
+		if len(head) > 0 {
+			cursor = head[0].ID
+			if err := c.bus.AckConsumer(ctx, c.name, cursor); err != nil {
+				c.bus.Unsubscribe(wake)
+				return nil, fmt.Errorf("consumer %s: %w", c.name, err)
+			}
+		}
+	}
+
+	out := make(chan *Event, subBuffer)
+	go c.run(ctx, cursor, wake, out)
+	return out, nil
+}
+
+// run feeds out from the store until ctx is cancelled. The bus only wakes
+// it; events are always read from the store after cursor, the last event
+// delivered or filtered out, so a dropped wakeup loses nothing.
+func (c *Consumer) run(ctx context.Context, cursor string, wake chan *Event, out chan<- *Event) {
+	const page = 100
+	defer close(out)
+	defer c.bus.Unsubscribe(wake)
+
+	ticker := time.NewTicker(consumerPoll)
+	defer ticker.Stop()
+	for {
+		for {
+			events, err := c.bus.Since(ctx, cursor, page)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("consumer %s: read events: %v", c.name, err)
+				}
+				break
+			}
+			for i := range events {
+				e := &events[i]
+				if c.filter.Match(e) {
+					select {
+					case out <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				cursor = e.ID
+			}
+			if len(events) < page {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			// Coalesce a burst of wakeups into one read.
+			for len(wake) > 0 {
+				<-wake
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ack records that e, and every event delivered before it, has been handled.
+// Acking an event older than the consumer's position is a no-op.
+func (c *Consumer) Ack(ctx context.Context, e *Event) error {
+	return c.bus.AckConsumer(ctx, c.name, e.ID)
+}
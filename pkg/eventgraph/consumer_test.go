@@ -0,0 +1,73 @@
+package eventgraph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConsumerResumesAfterAck(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(NewMemStore(nil, CausesStrict))
+	bus.Append(ctx, "task.created", "api", nil, nil, "", nil)
+
+	f := Filter{Types: []string{"task.created"}}
+	runCtx, stop := context.WithCancel(ctx)
+	ch, err := NewConsumer(bus, "worker", f).Start(runCtx)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	// A new consumer starts at the head: the earlier event isn't delivered.
+	bus.Append(ctx, "mind.thought", "mind", nil, nil, "", nil)
+	e1, _ := bus.Append(ctx, "task.created", "api", nil, nil, "", nil)
+	e2, _ := bus.Append(ctx, "task.created", "api", nil, nil, "", nil)
+	if got := receive(t, ch); got.ID != e1.ID {
+		t.Fatalf("first delivery = %s (%s), want %s", got.ID, got.Type, e1.ID)
+	}
+	if got := receive(t, ch); got.ID != e2.ID {
+		t.Fatalf("second delivery = %s, want %s", got.ID, e2.ID)
+	}
+	c := NewConsumer(bus, "worker", f)
+	if err := c.Ack(ctx, e1); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	stop()
+	for range ch {
+	}
+
+	// Restarted, it redelivers everything after its ack, including e2, which
+	// was delivered but never acked, and events appended while it was down.
+	e3, _ := bus.Append(ctx, "task.created", "api", nil, nil, "", nil)
+	ch, err = c.Start(ctx)
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if got := receive(t, ch); got.ID != e2.ID {
+		t.Fatalf("redelivery = %s, want unacked %s", got.ID, e2.ID)
+	}
+	if got := receive(t, ch); got.ID != e3.ID {
+		t.Fatalf("delivery after restart = %s, want %s", got.ID, e3.ID)
+	}
+	c.Ack(ctx, e3)
+
+	consumers, err := bus.Consumers(ctx)
+	if err != nil {
+		t.Fatalf("consumers: %v", err)
+	}
+	if len(consumers) != 1 || consumers[0].EventID != e3.ID || consumers[0].Lag != 0 {
+		t.Fatalf("consumers = %+v, want worker at %s with no lag", consumers, e3.ID)
+	}
+}
+
+func TestConsumerFromEmptyLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewBus(NewMemStore(nil, CausesStrict))
+	ch, err := NewConsumer(bus, "worker", Filter{}).Start(ctx)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	first, _ := bus.Append(ctx, "test.event", "tester", nil, nil, "", nil)
+	if got := receive(t, ch); got.ID != first.ID {
+		t.Fatalf("got %s, want genesis event %s", got.ID, first.ID)
+	}
+}
@@ -0,0 +1,64 @@
+package eventgraph
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrConversationExists is returned (wrapped) by CreateConversation for an
+// ID that is already taken.
+var ErrConversationExists = errors.New("conversation already exists")
+
+// Conversation is a named thread of events: those whose ConversationID is
+// its ID. Participants, EventCount and LastActivity are worked out from the
+// events when it is read, so they are always current.
+type Conversation struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	Participants []string  `json:"participants"` // the creator and every source that has posted, sorted
+	EventCount   int       `json:"event_count"`
+	LastActivity time.Time `json:"last_activity"` // the newest event, or CreatedAt if there are none
+}
+
+// newConversation starts a conversation for CreateConversation, minting an
+// ID if id is "".
+func newConversation(id, title, createdBy string) *Conversation {
+	if id == "" {
+		id = uuid.Must(uuid.NewV7()).String()
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	return &Conversation{
+		ID: id, Title: title, CreatedBy: createdBy, CreatedAt: now,
+		Participants: []string{createdBy}, LastActivity: now,
+	}
+}
+
+// conversationNotFound is the error for reading or retitling an unknown
+// conversation. It matches ErrNotFound.
+func conversationNotFound(id string) error {
+	return fmt.Errorf("conversation %s: %w", id, errNoConversation{})
+}
+
+type errNoConversation struct{}
+
+func (errNoConversation) Error() string        { return "conversation not found" }
+func (errNoConversation) Is(target error) bool { return target == ErrNotFound }
+
+// conversationKey is a conversation's position in ConversationsPage order.
+func conversationKey(c *Conversation) (time.Time, string) {
+	return c.LastActivity, c.ID
+}
+
+// addParticipants adds the sources of a conversation's events to its
+// creator, sorted and without repeats.
+func (c *Conversation) addParticipants(sources []string) {
+	c.Participants = append([]string{c.CreatedBy}, sources...)
+	slices.Sort(c.Participants)
+	c.Participants = slices.Compact(c.Participants)
+}
@@ -2,9 +2,16 @@ package eventgraph
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"time"
+
+	"mind-zero-five/pkg/page"
 )
 
+// ErrNotFound is returned (wrapped) when an event ID does not exist.
+var ErrNotFound = errors.New("event not found")
+
 // Event is a single node in the hash-chained, append-only causal event log.
 type Event struct {
 	ID             string         `json:"id"`              // UUID v7 (time-ordered)
@@ -16,25 +23,117 @@ type Event struct {
 	ConversationID string         `json:"conversation_id"` // groups related events into a conversation
 	Hash           string         `json:"hash"`            // SHA-256 of canonical form
 	PrevHash       string         `json:"prev_hash"`       // hash chain link
+	HashVersion    int            `json:"hash_version"`    // format Hash was computed with (HashV1 or HashV2)
+	Signature      string         `json:"signature"`       // hex Ed25519 signature over Hash by the source actor; empty if unsigned
+}
+
+// Signer signs an event's hash on behalf of its source actor.
+// *actor.Signer implements it.
+type Signer interface {
+	Sign(message []byte) []byte
+}
+
+// KeyLookup resolves an event source to the Ed25519 public key its events
+// must be signed with, and when that key was created. A nil key means the
+// source has no key and its events may be unsigned. actor.Store implements it.
+type KeyLookup interface {
+	PublicKey(ctx context.Context, source string) (ed25519.PublicKey, time.Time, error)
 }
 
 // EventStore is the contract for event persistence.
 type EventStore interface {
-	Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string) (*Event, error)
+	// Append stores a new event. If signer is non-nil it signs the event's
+	// hash; it should hold the key of the actor named by source.
+	Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error)
+	// AppendBatch appends several events atomically and adjacently, taking
+	// the append lock once. Safe for concurrent use, like Append.
+	AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error)
 	Get(ctx context.Context, id string) (*Event, error)
 	Recent(ctx context.Context, limit int) ([]Event, error)
 	ByType(ctx context.Context, eventType string, limit int) ([]Event, error)
 	BySource(ctx context.Context, source string, limit int) ([]Event, error)
 	ByConversation(ctx context.Context, conversationID string, limit int) ([]Event, error)
+	// Since returns up to limit events after afterID in chain order; an
+	// afterID of "" reads from genesis, and an archived one from the first
+	// live event.
 	Since(ctx context.Context, afterID string, limit int) ([]Event, error)
 	Count(ctx context.Context) (int, error)
-	VerifyChain(ctx context.Context) error
-	EnsureTable(ctx context.Context) error
+	// Query returns a page of the events matching q; see Query for how
+	// conditions combine. It fails with ErrInvalidQuery for a malformed q.
+	// Unlike the single-purpose listings above, it pages with cursors.
+	Query(ctx context.Context, q Query) (*page.Page[Event], error)
+	// VerifyChain checks every hash link. If keys is non-nil it also checks
+	// each event's signature against its source actor's public key.
+	VerifyChain(ctx context.Context, keys KeyLookup) error
+
+	// Incremental verification. VerifyRange checks fromID..toID inclusive
+	// ("" = genesis / head), linking fromID to its predecessor; VerifySince
+	// checks everything after a checkpoint (nil = everything). Both report
+	// every broken link rather than stopping at the first.
+	VerifyRange(ctx context.Context, fromID, toID string, keys KeyLookup) (*VerifyReport, error)
+	VerifySince(ctx context.Context, checkpoint *Checkpoint, keys KeyLookup) (*VerifyReport, error)
+	// SaveCheckpoint records a clean report that ran to the head, signed by
+	// signer on behalf of source. LatestCheckpoint returns nil if there is none.
+	SaveCheckpoint(ctx context.Context, report *VerifyReport, source string, signer Signer) (*Checkpoint, error)
+	LatestCheckpoint(ctx context.Context) (*Checkpoint, error)
+
+	// Durable consumer positions. AckConsumer moves a named consumer's
+	// position forward to eventID, never back; ConsumerPosition returns ""
+	// for a consumer that has never acked. Consumers lists every position
+	// with its lag.
+	AckConsumer(ctx context.Context, name, eventID string) error
+	ConsumerPosition(ctx context.Context, name string) (string, error)
+	Consumers(ctx context.Context) ([]ConsumerState, error)
+
+	// Merkle proofs over the events in chain order. A tree size of 0 means
+	// the whole log.
+	TreeHead(ctx context.Context, treeSize int) (*TreeHead, error)
+	InclusionProof(ctx context.Context, id string, treeSize int) (*InclusionProof, error)
+	ConsistencyProof(ctx context.Context, oldSize, newSize int) (*ConsistencyProof, error)
 
 	// Causal traversal
 	Ancestors(ctx context.Context, id string, maxDepth int) ([]Event, error)
 	Descendants(ctx context.Context, id string, maxDepth int) ([]Event, error)
-	Search(ctx context.Context, query string, limit int) ([]Event, error)
+	// PathBetween returns the shortest chain of causes by which from led to
+	// to, from first and to last; it is empty if to doesn't descend from
+	// from. CommonAncestors returns the events every one of ids descends
+	// from, in chain order, so the nearest come last. Both fail with
+	// ErrNotFound for an unknown ID.
+	PathBetween(ctx context.Context, from, to string) ([]Event, error)
+	CommonAncestors(ctx context.Context, ids ...string) ([]Event, error)
+	// Conversations. CreateConversation mints an ID if id is "" and fails
+	// with ErrConversationExists for a taken one. GetConversation and
+	// RetitleConversation fail with ErrNotFound for an unknown ID.
+	// Conversations lists the most recently active first, and
+	// ConversationsPage pages through them in that order, starting from a
+	// cursor of a previous page ("" for the first). ImportConversation
+	// inserts c's ID, title, creator and creation time exactly as given, for
+	// moving conversations between databases, and fails with
+	// ErrConversationExists for a taken ID.
+	CreateConversation(ctx context.Context, id, title, createdBy string) (*Conversation, error)
+	GetConversation(ctx context.Context, id string) (*Conversation, error)
+	RetitleConversation(ctx context.Context, id, title string) (*Conversation, error)
+	Conversations(ctx context.Context, limit int) ([]Conversation, error)
+	ConversationsPage(ctx context.Context, cursor string, limit int) (*page.Page[Conversation], error)
+	ImportConversation(ctx context.Context, c *Conversation) error
+
+	// AuditCauses reports every cause that doesn't exist or doesn't precede
+	// the event citing it, in chain order of the citing events.
+	AuditCauses(ctx context.Context) ([]CauseProblem, error)
+	// Segments lists the archived segments in chain order; see Archive.
+	Segments(ctx context.Context) ([]Segment, error)
+
+	// Redact replaces the content of event id with a tombstone keeping only
+	// a commitment to it, which its hash covers, so the chain still
+	// verifies. In the same transaction it appends an event.redacted event
+	// from req.Source recording the redaction, its reason and who asked for
+	// it, and returns that. Archived events can't be redacted, nor can
+	// events hashed before HashV2 (ErrNotRedactable).
+	Redact(ctx context.Context, id string, req RedactRequest) (*Event, error)
+	// Search ranks events against a full-text query, best first; see
+	// searchTerm for the syntax. It fails with ErrInvalidQuery if the query
+	// has nothing to match.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
 	DistinctTypes(ctx context.Context) ([]string, error)
 	DistinctSources(ctx context.Context) ([]string, error)
 }
@@ -0,0 +1,95 @@
+package eventgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// StoredEvent is an event with its content exactly as stored and hashed, so
+// it can leave one store and enter another without being re-hashed. Segment
+// files hold these, and ExportEvents and ImportEvents move them.
+type StoredEvent struct {
+	Event
+	Content json.RawMessage `json:"content"`
+}
+
+// UnmarshalJSON also decodes the raw content into Event.Content.
+func (e *StoredEvent) UnmarshalJSON(data []byte) error {
+	type plain StoredEvent
+	if err := json.Unmarshal(data, (*plain)(e)); err != nil {
+		return err
+	}
+	if len(e.Content) == 0 {
+		return fmt.Errorf("event %s has no content", e.ID)
+	}
+	if err := json.Unmarshal(e.Content, &e.Event.Content); err != nil {
+		return fmt.Errorf("event %s: content: %w", e.ID, err)
+	}
+	return nil
+}
+
+// ExportEvents returns the whole chain in order, each event with its content
+// as stored. It fails with ErrSegment if any events are archived: an export
+// starts at genesis, so restore them first.
+func ExportEvents(ctx context.Context, store EventStore) ([]StoredEvent, error) {
+	s, err := asSegmentStore(store)
+	if err != nil {
+		return nil, err
+	}
+	if _, n, err := s.archivedHead(ctx); err != nil {
+		return nil, fmt.Errorf("export events: %w", err)
+	} else if n > 0 {
+		return nil, fmt.Errorf("export events: %d are archived; restore them first: %w", n, ErrSegment)
+	}
+	events := []StoredEvent{}
+	err = s.scanChain(ctx, "", true, "", func(e *Event, contentJSON []byte) {
+		events = append(events, StoredEvent{Event: *e, Content: append(json.RawMessage(nil), contentJSON...)})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export events: %w", err)
+	}
+	return events, nil
+}
+
+// ImportEvents stores events, a whole chain from genesis in chain order, in
+// an empty store exactly as they are: IDs, timestamps, hashes and prev_hash
+// are kept, not recomputed. Every hash link is verified first, and every
+// signature too if keys is non-nil; if any fails nothing is stored.
+func ImportEvents(ctx context.Context, store EventStore, events []StoredEvent, keys KeyLookup) error {
+	s, err := asSegmentStore(store)
+	if err != nil {
+		return err
+	}
+	var sources []string
+	for _, e := range events {
+		if !slices.Contains(sources, e.Source) {
+			sources = append(sources, e.Source)
+		}
+	}
+	sigs, err := sourceKeys(ctx, sources, keys)
+	if err != nil {
+		return fmt.Errorf("import events: %w", err)
+	}
+	w := newChainWalk(sigs, "")
+	for i := range events {
+		e := &events[i].Event
+		if i > 0 && !chainPrecedes(&events[i-1].Event, e) {
+			w.fail(e.ID, fmt.Sprintf("out of chain order after %s", events[i-1].ID))
+		}
+		w.add(e, events[i].Content)
+	}
+	if err := w.report.Err(); err != nil {
+		return fmt.Errorf("import events: %w", err)
+	}
+	return s.importEvents(ctx, events)
+}
+
+// chainPrecedes reports whether a comes before b in chain order.
+func chainPrecedes(a, b *Event) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.Before(b.Timestamp)
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,309 @@
+package eventgraph
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned (wrapped) for a graph format Export doesn't
+// know.
+var ErrUnknownFormat = errors.New("unknown graph format")
+
+// Graph is the causal subgraph around one event: the events within some
+// depth of it and the causes edges between them.
+type Graph struct {
+	Root  string  `json:"root"`
+	Nodes []Event `json:"nodes"` // chain order
+	Edges []Edge  `json:"edges"`
+}
+
+// Edge is one causes link: Event lists Cause among its causes.
+type Edge struct {
+	Event string `json:"event"`
+	Cause string `json:"cause"`
+}
+
+// Which events Subgraph follows from the root.
+const (
+	GraphAncestors   = "ancestors"   // its causes, their causes, ...
+	GraphDescendants = "descendants" // events citing it, events citing those, ...
+	GraphBoth        = "both"
+)
+
+// Subgraph collects the events within depth causes links of id, following
+// direction (GraphBoth if ""), with every causes edge among them. Edges to
+// events outside the subgraph are left out. It fails with ErrNotFound if id
+// doesn't exist.
+func Subgraph(ctx context.Context, store EventStore, id string, depth int, direction string) (*Graph, error) {
+	root, err := store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Event{*root}
+	if direction == "" {
+		direction = GraphBoth
+	}
+	switch direction {
+	case GraphAncestors, GraphDescendants, GraphBoth:
+	default:
+		return nil, fmt.Errorf("graph direction %q: want %s, %s or %s: %w", direction, GraphAncestors, GraphDescendants, GraphBoth, ErrInvalidQuery)
+	}
+	if direction != GraphDescendants {
+		up, err := store.Ancestors(ctx, id, depth)
+		if err != nil {
+			return nil, fmt.Errorf("graph %s: %w", id, err)
+		}
+		nodes = append(nodes, up...)
+	}
+	if direction != GraphAncestors {
+		down, err := store.Descendants(ctx, id, depth)
+		if err != nil {
+			return nil, fmt.Errorf("graph %s: %w", id, err)
+		}
+		nodes = append(nodes, down...)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if !nodes[i].Timestamp.Equal(nodes[j].Timestamp) {
+			return nodes[i].Timestamp.Before(nodes[j].Timestamp)
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	in := make(map[string]bool, len(nodes))
+	for _, e := range nodes {
+		in[e.ID] = true
+	}
+	g := &Graph{Root: id, Nodes: nodes, Edges: []Edge{}}
+	for _, e := range nodes {
+		for _, c := range e.Causes {
+			if in[c] {
+				g.Edges = append(g.Edges, Edge{Event: e.ID, Cause: c})
+			}
+		}
+	}
+	return g, nil
+}
+
+// graphFormats are the formats Export writes, by name.
+var graphFormats = map[string]struct {
+	contentType string
+	write       func(io.Writer, *Graph) error
+}{
+	"json":    {"application/json", writeGraphJSON},
+	"dot":     {"text/vnd.graphviz; charset=utf-8", writeDOT},
+	"graphml": {"application/graphml+xml", writeGraphML},
+	"jsonld":  {"application/ld+json", writeJSONLD},
+}
+
+// GraphContentType returns the media type of a graph format, or "" for a
+// format Export doesn't know.
+func GraphContentType(format string) string {
+	return graphFormats[format].contentType
+}
+
+// Export writes g as json (the Graph itself), dot (Graphviz), graphml or
+// jsonld. Edges run from cause to effect in dot and graphml, so layouts read
+// forward in time.
+func (g *Graph) Export(w io.Writer, format string) error {
+	f, ok := graphFormats[format]
+	if !ok {
+		return fmt.Errorf("%q: want json, dot, graphml or jsonld: %w", format, ErrUnknownFormat)
+	}
+	return f.write(w, g)
+}
+
+func writeGraphJSON(w io.Writer, g *Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// dotQuote quotes s as a DOT string. Newlines become DOT's \n line breaks.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func writeDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph events {\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\", fontsize=10];\n")
+	for _, e := range g.Nodes {
+		label := e.Type + "\n" + e.Source + "\n" + e.Timestamp.UTC().Format(time.DateTime)
+		attrs := "label=" + dotQuote(label)
+		if e.ID == g.Root {
+			attrs += ", penwidth=2, style=filled, fillcolor=\"#ffffcc\""
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(e.ID), attrs)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(edge.Cause), dotQuote(edge.Event))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportContent renders content as JSON text for exports. Unlike
+// json.Marshal it leaves <, > and & alone: exports aren't embedded in HTML.
+func exportContent(content map[string]any) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(content); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// GraphML documents, as far as writeGraphML needs them.
+type graphML struct {
+	XMLName xml.Name       `xml:"graphml"`
+	NS      string         `xml:"xmlns,attr"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graph   graphMLContent `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLContent struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		NS: "http://graphml.graphdrawing.org/xmlns",
+		Graph: graphMLContent{
+			ID:          "events",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLNode, 0, len(g.Nodes)),
+			Edges:       make([]graphMLEdge, 0, len(g.Edges)),
+		},
+	}
+	for _, k := range []string{"type", "source", "timestamp", "conversation_id", "hash", "content", "root"} {
+		typ := "string"
+		if k == "root" {
+			typ = "boolean"
+		}
+		doc.Keys = append(doc.Keys, graphMLKey{ID: k, For: "node", AttrName: k, AttrType: typ})
+	}
+	for _, e := range g.Nodes {
+		content, err := exportContent(e.Content)
+		if err != nil {
+			return fmt.Errorf("marshal content of %s: %w", e.ID, err)
+		}
+		n := graphMLNode{ID: e.ID, Data: []graphMLData{
+			{"type", e.Type},
+			{"source", e.Source},
+			{"timestamp", e.Timestamp.UTC().Format(time.RFC3339Nano)},
+			{"conversation_id", e.ConversationID},
+			{"hash", e.Hash},
+			{"content", content},
+		}}
+		if e.ID == g.Root {
+			n.Data = append(n.Data, graphMLData{"root", "true"})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for _, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: edge.Cause, Target: edge.Event})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonLDVocab is the vocabulary JSON-LD terms expand under. Events are named
+// by their UUIDs as urn:uuid IRIs.
+const jsonLDVocab = "urn:mind-zero-five:eventgraph:"
+
+func writeJSONLD(w io.Writer, g *Graph) error {
+	type node struct {
+		ID             string          `json:"@id"`
+		Type           string          `json:"@type"`
+		EventType      string          `json:"eventType"`
+		Source         string          `json:"source"`
+		Timestamp      string          `json:"timestamp"`
+		ConversationID string          `json:"conversationId,omitempty"`
+		Hash           string          `json:"hash"`
+		Content        json.RawMessage `json:"content"`
+		Causes         []string        `json:"causes"`
+	}
+	iri := func(id string) string { return "urn:uuid:" + id }
+	doc := struct {
+		Context map[string]any `json:"@context"`
+		Graph   []node         `json:"@graph"`
+	}{
+		Context: map[string]any{
+			"@vocab":    jsonLDVocab,
+			"xsd":       "http://www.w3.org/2001/XMLSchema#",
+			"causes":    map[string]string{"@type": "@id", "@container": "@set"},
+			"timestamp": map[string]string{"@type": "xsd:dateTime"},
+			"content":   map[string]string{"@type": "@json"},
+		},
+		Graph: make([]node, 0, len(g.Nodes)),
+	}
+	in := make(map[string]bool, len(g.Nodes))
+	for _, e := range g.Nodes {
+		in[e.ID] = true
+	}
+	for _, e := range g.Nodes {
+		content, err := exportContent(e.Content)
+		if err != nil {
+			return fmt.Errorf("marshal content of %s: %w", e.ID, err)
+		}
+		n := node{
+			ID: iri(e.ID), Type: "Event", EventType: e.Type, Source: e.Source,
+			Timestamp:      e.Timestamp.UTC().Format(time.RFC3339Nano),
+			ConversationID: e.ConversationID, Hash: e.Hash, Content: json.RawMessage(content),
+			Causes: []string{},
+		}
+		for _, c := range e.Causes {
+			if in[c] {
+				n.Causes = append(n.Causes, iri(c))
+			}
+		}
+		doc.Graph = append(doc.Graph, n)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
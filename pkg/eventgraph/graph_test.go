@@ -0,0 +1,116 @@
+package eventgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSubgraph(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStore(nil, CausesStrict)
+	a := mustAppend(t, s, "test.a", "tester", nil, nil, "")
+	b := mustAppend(t, s, "test.b", "tester", nil, []string{a.ID}, "")
+	c := mustAppend(t, s, "test.c", "tester", nil, []string{a.ID, b.ID}, "")
+	d := mustAppend(t, s, "test.d", "tester", nil, []string{c.ID}, "")
+	x := mustAppend(t, s, "test.x", "tester", nil, nil, "")
+	e := mustAppend(t, s, "test.e", "tester", nil, []string{x.ID, c.ID}, "")
+
+	g, err := Subgraph(ctx, s, c.ID, 10, "")
+	if err != nil {
+		t.Fatalf("subgraph: %v", err)
+	}
+	var nodes []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	if want := []string{a.ID, b.ID, c.ID, d.ID, e.ID}; !slices.Equal(nodes, want) {
+		t.Errorf("nodes = %v, want %v", nodes, want)
+	}
+	// e's cause x is outside the subgraph, so that edge is left out.
+	wantEdges := []Edge{{b.ID, a.ID}, {c.ID, a.ID}, {c.ID, b.ID}, {d.ID, c.ID}, {e.ID, c.ID}}
+	if !slices.Equal(g.Edges, wantEdges) {
+		t.Errorf("edges = %v, want %v", g.Edges, wantEdges)
+	}
+
+	up, err := Subgraph(ctx, s, d.ID, 1, GraphAncestors)
+	if err != nil {
+		t.Fatalf("subgraph ancestors: %v", err)
+	}
+	if len(up.Nodes) != 2 || len(up.Edges) != 1 || up.Edges[0] != (Edge{d.ID, c.ID}) {
+		t.Errorf("depth-1 ancestors of d = %+v", up)
+	}
+
+	if _, err := Subgraph(ctx, s, "missing", 10, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing root: err = %v, want ErrNotFound", err)
+	}
+	if _, err := Subgraph(ctx, s, c.ID, 10, "sideways"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad direction: err = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestGraphExport(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStore(nil, CausesStrict)
+	a := mustAppend(t, s, "test.a", "tester", map[string]any{"note": `say "hi" & <bye>`}, nil, "")
+	b := mustAppend(t, s, "test.b", "tester", nil, []string{a.ID}, "")
+	g, err := Subgraph(ctx, s, b.ID, 10, "")
+	if err != nil {
+		t.Fatalf("subgraph: %v", err)
+	}
+
+	export := func(format string) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := g.Export(&buf, format); err != nil {
+			t.Fatalf("export %s: %v", format, err)
+		}
+		return buf.Bytes()
+	}
+
+	dot := string(export("dot"))
+	if !strings.HasPrefix(dot, "digraph events {") || !strings.Contains(dot, `"`+a.ID+`" -> "`+b.ID+`";`) {
+		t.Errorf("dot output missing the a -> b edge:\n%s", dot)
+	}
+
+	var ml graphML
+	if err := xml.Unmarshal(export("graphml"), &ml); err != nil {
+		t.Fatalf("graphml doesn't parse: %v", err)
+	}
+	if len(ml.Graph.Nodes) != 2 || len(ml.Graph.Edges) != 1 ||
+		ml.Graph.Edges[0] != (graphMLEdge{Source: a.ID, Target: b.ID}) {
+		t.Errorf("graphml graph = %+v", ml.Graph)
+	}
+	if got := ml.Graph.Nodes[0].Data[5]; got.Key != "content" || !strings.Contains(got.Value, `<bye>`) {
+		t.Errorf("graphml content = %+v", got)
+	}
+
+	var ld struct {
+		Context map[string]any `json:"@context"`
+		Graph   []struct {
+			ID     string   `json:"@id"`
+			Causes []string `json:"causes"`
+		} `json:"@graph"`
+	}
+	if err := json.Unmarshal(export("jsonld"), &ld); err != nil {
+		t.Fatalf("jsonld doesn't parse: %v", err)
+	}
+	if ld.Context["@vocab"] == nil || len(ld.Graph) != 2 ||
+		ld.Graph[1].ID != "urn:uuid:"+b.ID || !slices.Equal(ld.Graph[1].Causes, []string{"urn:uuid:" + a.ID}) {
+		t.Errorf("jsonld = %+v", ld)
+	}
+
+	var round Graph
+	if err := json.Unmarshal(export("json"), &round); err != nil || round.Root != b.ID || len(round.Edges) != 1 {
+		t.Errorf("json export = %+v, %v", round, err)
+	}
+
+	if err := g.Export(&bytes.Buffer{}, "svg"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("export svg: err = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,212 @@
+package eventgraph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hash format versions. Every event records the version its hash was computed
+// with, so the chain stays verifiable across format changes.
+const (
+	// HashV1 is the original format: "|"-joined fields. It does not cover
+	// causes, and "|" inside a field makes it ambiguous.
+	HashV1 = 1
+	// HashV2 length-prefixes every field, including each cause, and covers
+	// the content through a commitment to it: the SHA-256 of its canonical
+	// form, with numbers kept exactly. The content can then be redacted
+	// without breaking the hash (see Redact).
+	HashV2 = 2
+
+	// CurrentHashVersion is the format used for newly appended events.
+	CurrentHashVersion = HashV2
+)
+
+// hashV2Domain prefixes every v2 preimage so it can't collide with any other
+// use of SHA-256 over the same bytes.
+const hashV2Domain = "mind-zero-five/eventgraph/v2"
+
+// hashEvent computes e's hash using e.HashVersion and e.PrevHash. contentJSON
+// is the content as stored: v1 hashed those exact bytes and v2 commits to
+// them.
+func hashEvent(e *Event, contentJSON []byte) (string, error) {
+	switch e.HashVersion {
+	case HashV1:
+		return computeHashV1(e.PrevHash, e.ID, e.Type, e.Source, e.ConversationID, e.Timestamp, contentJSON), nil
+	case HashV2:
+		commitment, err := contentCommitment(contentJSON)
+		if err != nil {
+			return "", err
+		}
+		return computeHashV2(e.PrevHash, e.ID, e.Type, e.Source, e.ConversationID, e.Timestamp, commitment, e.Causes), nil
+	default:
+		return "", fmt.Errorf("unknown hash version %d", e.HashVersion)
+	}
+}
+
+// linkProblems checks that e links to prevHash and that its hash matches its
+// contents under its recorded hash version. It returns one reason per problem.
+func linkProblems(e *Event, prevHash string, contentJSON []byte) []string {
+	var problems []string
+	if e.PrevHash != prevHash {
+		problems = append(problems, fmt.Sprintf("prev_hash mismatch: got %s, want %s", e.PrevHash, prevHash))
+	}
+	expected, err := hashEvent(e, contentJSON)
+	if err != nil {
+		return append(problems, err.Error())
+	}
+	if e.Hash == expected {
+		return problems
+	}
+	if e.HashVersion == HashV1 {
+		// v1 hashed the JSON as first marshaled; JSONB may have normalized the
+		// stored text since, so also accept the re-marshaled content.
+		remarshaled, _ := json.Marshal(e.Content)
+		if computeHashV1(e.PrevHash, e.ID, e.Type, e.Source, e.ConversationID, e.Timestamp, remarshaled) == e.Hash {
+			return problems
+		}
+	}
+	return append(problems, fmt.Sprintf("hash mismatch (v%d): got %s, want %s", e.HashVersion, e.Hash, expected))
+}
+
+// decodeContent decodes content JSON with its numbers as json.Number in
+// canonicalNumber's form, so that none lose precision.
+func decodeContent(contentJSON []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(contentJSON))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after content")
+	}
+	return exactNumbers(v)
+}
+
+// exactNumbers rewrites every json.Number in v with canonicalNumber.
+func exactNumbers(v any) (any, error) {
+	var err error
+	switch v := v.(type) {
+	case json.Number:
+		return canonicalNumber(string(v))
+	case map[string]any:
+		for k, x := range v {
+			if v[k], err = exactNumbers(x); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, x := range v {
+			if v[i], err = exactNumbers(x); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// canonicalNumber writes a JSON number literal in one form per value, so a
+// backend that reformats numbers (JSONB stores 1e2 as 100) doesn't change the
+// hash: its exact decimal digits without leading or trailing zeros, then a
+// power of ten. Integers of up to 21 digits are written out in full.
+func canonicalNumber(lit string) (json.Number, error) {
+	sign, s := "", lit
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign, s = "-", rest
+	}
+	mantissa, expPart, hasExp := strings.Cut(strings.ToLower(s), "e")
+	exp := 0
+	if hasExp {
+		var err error
+		if exp, err = strconv.Atoi(expPart); err != nil {
+			return "", fmt.Errorf("number %s: exponent out of range", lit)
+		}
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	digits := strings.TrimLeft(whole+frac, "0")
+	if digits == "" {
+		return "0", nil
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed) - len(frac)
+	if exp >= 0 && len(trimmed)+exp <= 21 {
+		return json.Number(sign + trimmed + strings.Repeat("0", exp)), nil
+	}
+	return json.Number(sign + trimmed + "e" + strconv.Itoa(exp)), nil
+}
+
+// canonicalize encodes decoded content in the stable form v2 commits to:
+// object keys sorted, numbers as canonicalNumber writes them, no HTML
+// escaping, no insignificant whitespace. Decoding and re-encoding is
+// idempotent, so the result doesn't depend on how a backend stored the text.
+func canonicalize(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("canonicalize content: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// contentCommitment is what a v2 hash covers in place of the content: the
+// hex SHA-256 of its canonical form or, once the event is redacted, the one
+// its tombstone kept.
+func contentCommitment(contentJSON []byte) (string, error) {
+	v, err := decodeContent(contentJSON)
+	if err != nil {
+		return "", fmt.Errorf("commit to content: %w", err)
+	}
+	if m, ok := v.(map[string]any); ok && m[RedactedKey] != nil {
+		return tombstoneCommitment(m)
+	}
+	canonical, err := canonicalize(v)
+	if err != nil {
+		return "", fmt.Errorf("commit to content: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// computeHashV1 computes the original v1 SHA-256 hash for chain integrity.
+func computeHashV1(prevHash, id, eventType, source, conversationID string, timestamp time.Time, contentJSON []byte) string {
+	data := fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s", prevHash, id, eventType, source, conversationID, timestamp.UnixNano(), string(contentJSON))
+	h := sha256.Sum256([]byte(data))
+	return fmt.Sprintf("%x", h)
+}
+
+// computeHashV2 computes the v2 hash over the hex content commitment. Each
+// field is written as a big-endian uint64 length followed by its bytes;
+// causes are written as a count followed by each cause, in order.
+func computeHashV2(prevHash, id, eventType, source, conversationID string, timestamp time.Time, contentSHA256 string, causes []string) string {
+	h := sha256.New()
+	var n [8]byte
+	field := func(b []byte) {
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+
+	field([]byte(hashV2Domain))
+	field([]byte(prevHash))
+	field([]byte(id))
+	field([]byte(eventType))
+	field([]byte(source))
+	field([]byte(conversationID))
+	field([]byte(strconv.FormatInt(timestamp.UnixNano(), 10)))
+	field([]byte(contentSHA256))
+	binary.BigEndian.PutUint64(n[:], uint64(len(causes)))
+	h.Write(n[:])
+	for _, c := range causes {
+		field([]byte(c))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -1,42 +1,167 @@
 package eventgraph
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
 
-func TestComputeHash(t *testing.T) {
+func TestComputeHashV1(t *testing.T) {
 	now := time.Date(2026, 2, 25, 12, 0, 0, 0, time.UTC)
 	content, _ := json.Marshal(map[string]any{"key": "value"})
 
-	h1 := computeHash("", "id1", "test.event", "source1", "", now, content)
-	h2 := computeHash("", "id1", "test.event", "source1", "", now, content)
+	h1 := computeHashV1("", "id1", "test.event", "source1", "", now, content)
+	h2 := computeHashV1("", "id1", "test.event", "source1", "", now, content)
 	if h1 != h2 {
 		t.Fatalf("same inputs should produce same hash: %s != %s", h1, h2)
 	}
 
-	h3 := computeHash("", "id2", "test.event", "source1", "", now, content)
+	h3 := computeHashV1("", "id2", "test.event", "source1", "", now, content)
 	if h1 == h3 {
 		t.Fatalf("different ID should produce different hash")
 	}
 
-	h4 := computeHash("prevhash", "id1", "test.event", "source1", "", now, content)
+	h4 := computeHashV1("prevhash", "id1", "test.event", "source1", "", now, content)
 	if h1 == h4 {
 		t.Fatalf("different prevHash should produce different hash")
 	}
 }
 
-func TestComputeHashDeterministic(t *testing.T) {
+func TestComputeHashV1Deterministic(t *testing.T) {
 	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// JSON marshal of map sorts keys deterministically
 	content1, _ := json.Marshal(map[string]any{"a": 1, "b": 2})
 	content2, _ := json.Marshal(map[string]any{"b": 2, "a": 1})
 
-	h1 := computeHash("", "id", "type", "src", "conv", now, content1)
-	h2 := computeHash("", "id", "type", "src", "conv", now, content2)
+	h1 := computeHashV1("", "id", "type", "src", "conv", now, content1)
+	h2 := computeHashV1("", "id", "type", "src", "conv", now, content2)
 	if h1 != h2 {
 		t.Fatalf("json.Marshal sorts keys, so hashes should match: %s != %s", h1, h2)
 	}
 }
+
+func TestComputeHashV2CoversCauses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := strings.Repeat("c", 64)
+
+	h1 := computeHashV2("", "id", "type", "src", "", now, content, []string{"a", "b"})
+	h2 := computeHashV2("", "id", "type", "src", "", now, content, []string{"a", "c"})
+	h3 := computeHashV2("", "id", "type", "src", "", now, content, []string{"b", "a"})
+	h4 := computeHashV2("", "id", "type", "src", "", now, content, nil)
+	if h1 == h2 || h1 == h3 || h1 == h4 {
+		t.Fatalf("changing causes should change the hash")
+	}
+}
+
+func TestComputeHashV2Unambiguous(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	content, commitment := []byte(`{}`), strings.Repeat("c", 64)
+
+	// Under v1 these collide: both are "|a|b|c|..." once joined.
+	if computeHashV1("", "a", "b|c", "", "", now, content) != computeHashV1("", "a|b", "c", "", "", now, content) {
+		t.Fatalf("expected v1 collision")
+	}
+	if computeHashV2("", "a", "b|c", "", "", now, commitment, nil) == computeHashV2("", "a|b", "c", "", "", now, commitment, nil) {
+		t.Fatalf("v2 should not collide when a field boundary moves")
+	}
+	if computeHashV2("", "id", "t", "", "", now, commitment, []string{"a", "b"}) == computeHashV2("", "id", "t", "", "", now, commitment, []string{"ab"}) {
+		t.Fatalf("v2 should not collide when causes are re-split")
+	}
+}
+
+func TestContentCommitment(t *testing.T) {
+	// The commitment is over the canonical form, so encodings don't matter.
+	a, err := contentCommitment([]byte(`{"b": 1.0, "a": "x"}`))
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	b, err := contentCommitment([]byte(`{"a":"x","b":1}`))
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if a != b || len(a) != 64 {
+		t.Fatalf("commitments %s and %s should match", a, b)
+	}
+
+	// A tombstone stands in for the content it replaced.
+	tomb, err := contentCommitment([]byte(`{"` + RedactedKey + `":{"content_sha256":"` + a + `","redaction":"r"}}`))
+	if err != nil || tomb != a {
+		t.Fatalf("tombstone commitment = %s, %v; want %s", tomb, err, a)
+	}
+	if _, err := contentCommitment([]byte(`{"` + RedactedKey + `":{"content_sha256":"short"}}`)); err == nil {
+		t.Fatalf("malformed tombstone should be rejected")
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	cases := map[string]string{
+		"0": "0", "-0": "0", "0.000": "0",
+		"1": "1", "1.0": "1", "1e0": "1", "10E-1": "1", "100": "100", "1e2": "100", "1E+2": "100",
+		"-1.50": "-15e-1", "0.0000001": "1e-7", "1e-7": "1e-7",
+		"9007199254740993":      "9007199254740993",
+		"123456789012345678901": "123456789012345678901", "1e20": "100000000000000000000", "1e21": "1e21",
+	}
+	for lit, want := range cases {
+		got, err := canonicalNumber(lit)
+		if err != nil || string(got) != want {
+			t.Errorf("canonicalNumber(%s) = %s, %v; want %s", lit, got, err, want)
+		}
+	}
+}
+
+func TestHashV2KeepsLargeIntegers(t *testing.T) {
+	// 2^53+1 and 2^53 are the same float64, so reading numbers as float64
+	// couldn't tell them apart.
+	big, small := []byte(`{"id":9007199254740993}`), []byte(`{"id":9007199254740992}`)
+	bigSum, _ := contentCommitment(big)
+	smallSum, _ := contentCommitment(small)
+	if bigSum == smallSum {
+		t.Fatalf("commitments to %s and %s should differ", big, small)
+	}
+
+	s := NewMemStore(nil, CausesStrict)
+	e := mustAppend(t, s, "test.big", "tester", map[string]any{"id": int64(9007199254740993)}, nil, "")
+	if e.HashVersion != HashV2 {
+		t.Fatalf("hash version = %d, want %d", e.HashVersion, HashV2)
+	}
+	if h, err := hashEvent(e, big); err != nil || h != e.Hash {
+		t.Fatalf("hash over the stored content = %s, %v; want %s", h, err, e.Hash)
+	}
+	if h, _ := hashEvent(e, small); h == e.Hash {
+		t.Fatalf("hash over %s should differ from the one over %s", small, big)
+	}
+	if err := s.VerifyChain(context.Background(), nil); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestCanonicalContent(t *testing.T) {
+	canonicalContent := func(contentJSON []byte) ([]byte, error) {
+		v, err := decodeContent(contentJSON)
+		if err != nil {
+			return nil, err
+		}
+		return canonicalize(v)
+	}
+	// Same value, different encodings: key order, whitespace, number form, escaping.
+	a, err := canonicalContent([]byte(`{"b": 1.0, "a": "<x>", "n": {"z": [1, 2], "y": null}}`))
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+	b, err := canonicalContent([]byte(`{"n":{"y":null,"z":[1e0,2]},"a":"<x>","b":1}`))
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+	want := `{"a":"<x>","b":1,"n":{"y":null,"z":[1,2]}}`
+	if string(a) != want || string(b) != want {
+		t.Fatalf("canonical forms: got %s and %s, want %s", a, b, want)
+	}
+
+	again, _ := canonicalContent(a)
+	if string(again) != want {
+		t.Fatalf("canonicalization should be idempotent: got %s", again)
+	}
+}
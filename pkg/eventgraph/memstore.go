@@ -0,0 +1,841 @@
+package eventgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mind-zero-five/pkg/page"
+)
+
+// MemStore is an in-memory EventStore with the same hash chaining and
+// ordering semantics as PgStore. Intended for tests and embedded use.
+type MemStore struct {
+	mu     sync.RWMutex
+	events []Event        // ordered by (timestamp, id) ascending
+	raw    [][]byte       // content JSON as hashed, parallel to events
+	byID   map[string]int // event ID -> index into events
+
+	checkpoints   []Checkpoint
+	consumers     map[string]ConsumerState
+	conversations map[string]Conversation // as created; the rest is derived on read
+	archived      []archivedStub          // chain order, all before events
+	segments      []Segment
+	merkle        merkleLog
+	scrubber      *Scrubber
+	causes        CauseMode
+}
+
+// NewMemStore creates an empty MemStore that masks secrets in appended
+// content with scrubber and treats causes that aren't on the chain as causes
+// says.
+func NewMemStore(scrubber *Scrubber, causes CauseMode) *MemStore {
+	return &MemStore{
+		scrubber:      scrubber,
+		causes:        causes,
+		byID:          make(map[string]int),
+		consumers:     make(map[string]ConsumerState),
+		conversations: make(map[string]Conversation),
+	}
+}
+
+// Append creates and stores a new event, computing the hash chain.
+func (s *MemStore) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error) {
+	events, err := s.AppendBatch(ctx, []AppendRequest{{
+		Type: eventType, Source: source, Content: content, Causes: causes, ConversationID: conversationID, Signer: signer,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// AppendBatch appends events consecutively: either all are stored, adjacent
+// on the chain in order, or none are.
+func (s *MemStore) AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	pending, err := prepareBatch(reqs, s.scrubber)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	linked, err := s.linkLocked(pending)
+	if err != nil {
+		return nil, err
+	}
+	return s.commitLocked(pending, linked), nil
+}
+
+// linkLocked links pending onto the chain without storing them, doing
+// everything that can fail. Caller holds mu.
+func (s *MemStore) linkLocked(pending []pendingEvent) ([]*Event, error) {
+	exists := make(map[string]bool)
+	for _, c := range causeIDs(pending) {
+		_, live := s.byID[c]
+		exists[c] = live || s.isArchived(c)
+	}
+	if err := checkCauses(pending, exists, s.causes); err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		// Round-trip content through JSON so readers see the same types
+		// (float64 numbers, []any arrays) they would get back from JSONB.
+		var stored map[string]any
+		if err := json.Unmarshal(pending[i].contentJSON, &stored); err != nil {
+			return nil, fmt.Errorf("unmarshal content: %w", err)
+		}
+		pending[i].req.Content = stored
+		pending[i].req.Causes = append([]string{}, pending[i].req.Causes...)
+	}
+
+	var head chainHead
+	if n := len(s.events); n > 0 {
+		head = chainHead{hash: s.events[n-1].Hash, timestamp: s.events[n-1].Timestamp}
+	}
+	linked := make([]*Event, len(pending))
+	for i := range pending {
+		var err error
+		if linked[i], err = pending[i].link(&head); err != nil {
+			return nil, err
+		}
+	}
+	return linked, nil
+}
+
+// commitLocked stores events linked by linkLocked. Caller holds mu.
+func (s *MemStore) commitLocked(pending []pendingEvent, linked []*Event) []*Event {
+	out := make([]*Event, len(linked))
+	for i, e := range linked {
+		s.byID[e.ID] = len(s.events)
+		s.events = append(s.events, *e)
+		s.raw = append(s.raw, pending[i].contentJSON)
+		c := copyEvent(*e)
+		out[i] = &c
+	}
+	return out
+}
+
+// Redact replaces an event's content with a tombstone and records it.
+func (s *MemStore) Redact(ctx context.Context, id string, req RedactRequest) (*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("redact %s: %w", id, ErrNotFound)
+	}
+	r, err := prepareRedaction(&s.events[i], s.raw[i], req)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := prepareBatch([]AppendRequest{r.audit}, s.scrubber)
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	linked, err := s.linkLocked(pending)
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	// Build the tombstone before storing anything, so a failure can't leave
+	// an event.redacted event for content that is still there.
+	raw, content, err := r.tombstone(linked[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	audit := s.commitLocked(pending, linked)
+	s.events[i].Content, s.raw[i] = content, raw
+	return audit[0], nil
+}
+
+// Get retrieves a single event by ID.
+func (s *MemStore) Get(ctx context.Context, id string) (*Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("get event %s: %w", id, ErrNotFound)
+	}
+	e := copyEvent(s.events[i])
+	return &e, nil
+}
+
+// Recent returns the most recent events in reverse chronological order.
+func (s *MemStore) Recent(ctx context.Context, limit int) ([]Event, error) {
+	return s.newestFirst(limit, func(e *Event) bool { return true }), nil
+}
+
+// ByType returns events of the given type, newest first.
+func (s *MemStore) ByType(ctx context.Context, eventType string, limit int) ([]Event, error) {
+	return s.newestFirst(limit, func(e *Event) bool { return e.Type == eventType }), nil
+}
+
+// BySource returns events filtered by source, newest first.
+func (s *MemStore) BySource(ctx context.Context, source string, limit int) ([]Event, error) {
+	return s.newestFirst(limit, func(e *Event) bool { return e.Source == source }), nil
+}
+
+// ByConversation returns events in a conversation in chronological order.
+func (s *MemStore) ByConversation(ctx context.Context, conversationID string, limit int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Event
+	for i := range s.events {
+		if len(out) >= limit {
+			break
+		}
+		if s.events[i].ConversationID == conversationID {
+			out = append(out, copyEvent(s.events[i]))
+		}
+	}
+	return out, nil
+}
+
+// Since returns events created after the given ID, for polling/SSE.
+// Returns nothing if afterID is unknown, matching PgStore.
+func (s *MemStore) Since(ctx context.Context, afterID string, limit int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := -1
+	if afterID != "" {
+		j, ok := s.byID[afterID]
+		switch {
+		case ok:
+			i = j
+		case !s.isArchived(afterID):
+			return nil, nil
+		}
+	}
+	var out []Event
+	for j := i + 1; j < len(s.events) && len(out) < limit; j++ {
+		out = append(out, copyEvent(s.events[j]))
+	}
+	return out, nil
+}
+
+// Query returns a page of the events matching q.
+func (s *MemStore) Query(ctx context.Context, q Query) (*page.Page[Event], error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	c, _ := page.Parse(q.Cursor)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// The first event after the cursor's key in chain order.
+	after := sort.Search(len(s.events), func(i int) bool {
+		e := &s.events[i]
+		return e.Timestamp.After(c.Time) || e.Timestamp.Equal(c.Time) && e.ID > c.ID
+	})
+	start, step := after, 1
+	if _, order := c.Keyset(!q.Ascending); order == "DESC" {
+		start, step = len(s.events)-1, -1
+		if !c.IsZero() {
+			start = sort.Search(len(s.events), func(i int) bool {
+				e := &s.events[i]
+				return !e.Timestamp.Before(c.Time) && (!e.Timestamp.Equal(c.Time) || e.ID >= c.ID)
+			}) - 1
+		}
+	}
+	var out []Event
+	for i := start; i >= 0 && i < len(s.events) && len(out) <= q.limit(); i += step {
+		if q.match(&s.events[i]) {
+			out = append(out, copyEvent(s.events[i]))
+		}
+	}
+	return page.Build(out, q.limit(), c, eventKey), nil
+}
+
+// Count returns the total number of events.
+func (s *MemStore) Count(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.events), nil
+}
+
+// VerifyChain walks the entire chain chronologically and verifies hash
+// integrity and, if keys is non-nil, signatures.
+func (s *MemStore) VerifyChain(ctx context.Context, keys KeyLookup) error {
+	r, err := verifyRange(ctx, s, "", "", keys)
+	if err != nil {
+		return err
+	}
+	return r.Err()
+}
+
+// VerifyRange verifies fromID..toID inclusive.
+func (s *MemStore) VerifyRange(ctx context.Context, fromID, toID string, keys KeyLookup) (*VerifyReport, error) {
+	return verifyRange(ctx, s, fromID, toID, keys)
+}
+
+// VerifySince verifies every event after a checkpoint.
+func (s *MemStore) VerifySince(ctx context.Context, checkpoint *Checkpoint, keys KeyLookup) (*VerifyReport, error) {
+	return verifySince(ctx, s, checkpoint, keys)
+}
+
+// SaveCheckpoint records a verification checkpoint.
+func (s *MemStore) SaveCheckpoint(ctx context.Context, report *VerifyReport, source string, signer Signer) (*Checkpoint, error) {
+	c, err := newCheckpoint(uuid.Must(uuid.NewV7()).String(), report, source, signer)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints = append(s.checkpoints, *c)
+	return c, nil
+}
+
+// LatestCheckpoint returns the most recent checkpoint, or nil.
+func (s *MemStore) LatestCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.checkpoints) == 0 {
+		return nil, nil
+	}
+	c := s.checkpoints[len(s.checkpoints)-1]
+	return &c, nil
+}
+
+// AckConsumer advances a consumer's position to eventID.
+func (s *MemStore) AckConsumer(ctx context.Context, name, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.chainIndex(eventID)
+	if i < 0 {
+		return fmt.Errorf("ack %s: event %s: %w", name, eventID, ErrNotFound)
+	}
+	if cur, ok := s.consumers[name]; ok && s.chainIndex(cur.EventID) >= i {
+		return nil
+	}
+	s.consumers[name] = ConsumerState{Name: name, EventID: eventID, AckedAt: time.Now()}
+	return nil
+}
+
+// ConsumerPosition returns the last event a consumer acked, or "".
+func (s *MemStore) ConsumerPosition(ctx context.Context, name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.consumers[name].EventID, nil
+}
+
+// Consumers lists consumer positions by name, with their lag.
+func (s *MemStore) Consumers(ctx context.Context) ([]ConsumerState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]ConsumerState, 0, len(s.consumers))
+	for _, c := range s.consumers {
+		c.Lag = len(s.archived) + len(s.events) - 1 - s.chainIndex(c.EventID)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// CreateConversation starts a conversation.
+func (s *MemStore) CreateConversation(ctx context.Context, id, title, createdBy string) (*Conversation, error) {
+	c := newConversation(id, title, createdBy)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[c.ID]; ok {
+		return nil, fmt.Errorf("create conversation %s: %w", c.ID, ErrConversationExists)
+	}
+	s.conversations[c.ID] = *c
+	return s.conversation(c.ID), nil
+}
+
+// ImportConversation stores a conversation as given.
+func (s *MemStore) ImportConversation(ctx context.Context, c *Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[c.ID]; ok {
+		return fmt.Errorf("import conversation %s: %w", c.ID, ErrConversationExists)
+	}
+	s.conversations[c.ID] = Conversation{ID: c.ID, Title: c.Title, CreatedBy: c.CreatedBy, CreatedAt: c.CreatedAt, LastActivity: c.CreatedAt}
+	return nil
+}
+
+// GetConversation returns a conversation with its participants and activity.
+func (s *MemStore) GetConversation(ctx context.Context, id string) (*Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.conversations[id]; !ok {
+		return nil, conversationNotFound(id)
+	}
+	return s.conversation(id), nil
+}
+
+// RetitleConversation changes a conversation's title.
+func (s *MemStore) RetitleConversation(ctx context.Context, id, title string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.conversations[id]
+	if !ok {
+		return nil, conversationNotFound(id)
+	}
+	c.Title = title
+	s.conversations[id] = c
+	return s.conversation(id), nil
+}
+
+// Conversations lists conversations, most recently active first.
+func (s *MemStore) Conversations(ctx context.Context, limit int) ([]Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Conversation, 0, len(s.conversations))
+	for id := range s.conversations {
+		out = append(out, *s.conversation(id))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastActivity.Equal(out[j].LastActivity) {
+			return out[i].LastActivity.After(out[j].LastActivity)
+		}
+		return out[i].ID > out[j].ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ConversationsPage returns a page of conversations, most recently active
+// first.
+func (s *MemStore) ConversationsPage(ctx context.Context, cursor string, limit int) (*page.Page[Conversation], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	all, _ := s.Conversations(ctx, math.MaxInt)
+	cmp, order := c.Keyset(true)
+	if order == "ASC" {
+		slices.Reverse(all)
+	}
+	var out []Conversation
+	for _, conv := range all {
+		if len(out) > limit {
+			break
+		}
+		if !c.IsZero() {
+			d := conv.LastActivity.Compare(c.Time)
+			if d == 0 {
+				d = strings.Compare(conv.ID, c.ID)
+			}
+			if cmp == "<" && d >= 0 || cmp == ">" && d <= 0 {
+				continue
+			}
+		}
+		out = append(out, conv)
+	}
+	return page.Build(out, limit, c, conversationKey), nil
+}
+
+// conversation fills in a stored conversation from its events. Caller holds
+// mu.
+func (s *MemStore) conversation(id string) *Conversation {
+	c := s.conversations[id]
+	var sources []string
+	for _, e := range s.events {
+		if e.ConversationID == id {
+			c.EventCount++
+			c.LastActivity = e.Timestamp
+			sources = append(sources, e.Source)
+		}
+	}
+	c.addParticipants(sources)
+	return &c
+}
+
+// TreeHead returns the Merkle root over the first treeSize events.
+func (s *MemStore) TreeHead(ctx context.Context, treeSize int) (*TreeHead, error) {
+	return treeHead(ctx, s, &s.merkle, treeSize)
+}
+
+// InclusionProof proves that an event is in the tree of treeSize events.
+func (s *MemStore) InclusionProof(ctx context.Context, id string, treeSize int) (*InclusionProof, error) {
+	return inclusionProof(ctx, s, &s.merkle, id, treeSize)
+}
+
+// ConsistencyProof proves that the tree of newSize events extends the tree
+// of oldSize events.
+func (s *MemStore) ConsistencyProof(ctx context.Context, oldSize, newSize int) (*ConsistencyProof, error) {
+	return consistencyProof(ctx, s, &s.merkle, oldSize, newSize)
+}
+
+func (s *MemStore) hashBefore(ctx context.Context, id string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.byID[id]
+	if !ok {
+		return "", fmt.Errorf("event %s: %w", id, ErrNotFound)
+	}
+	if i == 0 {
+		if n := len(s.archived); n > 0 {
+			return s.archived[n-1].hash, nil
+		}
+		return "", nil
+	}
+	return s.events[i-1].Hash, nil
+}
+
+func (s *MemStore) archivedHead(ctx context.Context) (string, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n := len(s.archived); n > 0 {
+		return s.archived[n-1].hash, n, nil
+	}
+	return "", 0, nil
+}
+
+func (s *MemStore) scanArchived(ctx context.Context, fn func(id, hash string)) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, a := range s.archived {
+		fn(a.id, a.hash)
+	}
+	return nil
+}
+
+// isArchived reports whether id is an archived event. Caller holds mu.
+func (s *MemStore) isArchived(id string) bool {
+	for _, a := range s.archived {
+		if a.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// chainIndex returns id's position in the chain, archived events included,
+// or -1 if there is no such event. Caller holds mu.
+func (s *MemStore) chainIndex(id string) int {
+	if i, ok := s.byID[id]; ok {
+		return len(s.archived) + i
+	}
+	for i, a := range s.archived {
+		if a.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Segments lists the archived segments in chain order.
+func (s *MemStore) Segments(ctx context.Context) ([]Segment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Segment{}, s.segments...), nil
+}
+
+func (s *MemStore) archiveSegment(ctx context.Context, seg *Segment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstID, lastHash string
+	n := 0
+	if len(s.events) > 0 {
+		firstID = s.events[0].ID
+	}
+	if i, ok := s.byID[seg.LastID]; ok {
+		n, lastHash = i+1, s.events[i].Hash
+	}
+	if err := seg.checkPrefix(firstID, n, lastHash, n < len(s.events)); err != nil {
+		return err
+	}
+	for _, e := range s.events[:n] {
+		s.archived = append(s.archived, archivedStub{id: e.ID, timestamp: e.Timestamp, hash: e.Hash})
+	}
+	s.events = append([]Event{}, s.events[n:]...)
+	s.raw = append([][]byte{}, s.raw[n:]...)
+	s.reindex()
+	s.segments = append(s.segments, *seg)
+	return nil
+}
+
+func (s *MemStore) restoreSegment(ctx context.Context, seg *Segment, events []StoredEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.segments) == 0 || s.segments[len(s.segments)-1].Name != seg.Name {
+		return fmt.Errorf("restore %s: not the newest segment: %w", seg.Name, ErrSegment)
+	}
+	s.prepend(events)
+	s.archived = s.archived[:len(s.archived)-len(events)]
+	s.segments = s.segments[:len(s.segments)-1]
+	return nil
+}
+
+func (s *MemStore) importEvents(ctx context.Context, events []StoredEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) > 0 || len(s.archived) > 0 {
+		return fmt.Errorf("import events: the store already holds events")
+	}
+	s.prepend(events)
+	return nil
+}
+
+// prepend puts events, which precede every event held, at the start of the
+// chain. Caller holds mu.
+func (s *MemStore) prepend(events []StoredEvent) {
+	restored := make([]Event, len(events))
+	raw := make([][]byte, len(events))
+	for i, e := range events {
+		restored[i], raw[i] = copyEvent(e.Event), e.Content
+	}
+	s.events = append(restored, s.events...)
+	s.raw = append(raw, s.raw...)
+	s.reindex()
+}
+
+// reindex rebuilds byID after events moved. Caller holds mu.
+func (s *MemStore) reindex() {
+	s.byID = make(map[string]int, len(s.events))
+	for i, e := range s.events {
+		s.byID[e.ID] = i
+	}
+}
+
+func (s *MemStore) scanChain(ctx context.Context, fromID string, inclusive bool, toID string, fn func(e *Event, contentJSON []byte)) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	start, end := 0, len(s.events)-1
+	if fromID != "" {
+		i, ok := s.byID[fromID]
+		if !ok {
+			return fmt.Errorf("event %s: %w", fromID, ErrNotFound)
+		}
+		start = i
+		if !inclusive {
+			start++
+		}
+	}
+	if toID != "" {
+		i, ok := s.byID[toID]
+		if !ok {
+			return fmt.Errorf("event %s: %w", toID, ErrNotFound)
+		}
+		end = i
+	}
+	for i := start; i <= end; i++ {
+		e := copyEvent(s.events[i])
+		fn(&e, s.raw[i])
+	}
+	return nil
+}
+
+// Ancestors walks up the causes chain breadth-first, up to maxDepth levels.
+func (s *MemStore) Ancestors(ctx context.Context, id string, maxDepth int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.walk(id, maxDepth, func(e *Event) []string { return e.Causes }), nil
+}
+
+// Descendants finds events that cite the given ID in their causes, transitively.
+func (s *MemStore) Descendants(ctx context.Context, id string, maxDepth int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	effects := make(map[string][]string)
+	for i := range s.events {
+		for _, c := range s.events[i].Causes {
+			effects[c] = append(effects[c], s.events[i].ID)
+		}
+	}
+	return s.walk(id, maxDepth, func(e *Event) []string { return effects[e.ID] }), nil
+}
+
+// PathBetween finds the shortest causes path from from to to.
+func (s *MemStore) PathBetween(ctx context.Context, from, to string) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, id := range []string{from, to} {
+		if _, ok := s.byID[id]; !ok {
+			return nil, fmt.Errorf("path: event %s: %w", id, ErrNotFound)
+		}
+	}
+	nodes := append(s.walk(to, math.MaxInt, func(e *Event) []string { return e.Causes }), copyEvent(s.events[s.byID[to]]))
+	path := shortestPath(nodes, from, to)
+	if path == nil {
+		path = []Event{}
+	}
+	return path, nil
+}
+
+// CommonAncestors finds the events every one of ids descends from.
+func (s *MemStore) CommonAncestors(ctx context.Context, ids ...string) ([]Event, error) {
+	ids, err := commonIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := make(map[string]int)
+	for _, id := range ids {
+		if _, ok := s.byID[id]; !ok {
+			return nil, fmt.Errorf("common ancestors: event %s: %w", id, ErrNotFound)
+		}
+		for _, e := range s.walk(id, math.MaxInt, func(e *Event) []string { return e.Causes }) {
+			count[e.ID]++
+		}
+	}
+	out := []Event{}
+	for i := range s.events {
+		if count[s.events[i].ID] == len(ids) {
+			out = append(out, copyEvent(s.events[i]))
+		}
+	}
+	return out, nil
+}
+
+// AuditCauses reports causes that aren't in the store or don't come before
+// the event citing them.
+func (s *MemStore) AuditCauses(ctx context.Context) ([]CauseProblem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	problems := []CauseProblem{}
+	for i, e := range s.events {
+		for _, c := range e.Causes {
+			j, ok := s.byID[c]
+			switch {
+			case !ok && s.isArchived(c):
+				// Archived events precede every live one.
+			case !ok:
+				problems = append(problems, CauseProblem{e.ID, e.Type, c, CauseDangling})
+			case j >= i:
+				problems = append(problems, CauseProblem{e.ID, e.Type, c, CauseFuture})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// walk collects events reachable from id via next, excluding id itself,
+// ordered chronologically. Caller holds mu.
+func (s *MemStore) walk(id string, maxDepth int, next func(e *Event) []string) []Event {
+	start, ok := s.byID[id]
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool)
+	frontier := []int{start}
+	// Like the recursive CTE in PgStore, the first level is always expanded.
+	for depth := 0; len(frontier) > 0 && (depth == 0 || depth < maxDepth); depth++ {
+		var nextFrontier []int
+		for _, i := range frontier {
+			for _, nid := range next(&s.events[i]) {
+				j, ok := s.byID[nid]
+				if !ok || seen[nid] {
+					continue
+				}
+				seen[nid] = true
+				nextFrontier = append(nextFrontier, j)
+			}
+		}
+		frontier = nextFrontier
+	}
+
+	idx := make([]int, 0, len(seen))
+	for nid := range seen {
+		idx = append(idx, s.byID[nid])
+	}
+	sort.Ints(idx)
+	out := make([]Event, 0, len(idx))
+	for _, i := range idx {
+		out = append(out, copyEvent(s.events[i]))
+	}
+	return out
+}
+
+// Search ranks events against a full-text query; see SearchResult. It
+// scans every event.
+func (s *MemStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []SearchResult
+	for i := range s.events {
+		if rank, snip, ok := searchEvent(&s.events[i], terms); ok {
+			results = append(results, SearchResult{Event: copyEvent(s.events[i]), Rank: rank, Snippet: snip})
+		}
+	}
+	return rankResults(results, limit), nil
+}
+
+// DistinctTypes returns all unique event types.
+func (s *MemStore) DistinctTypes(ctx context.Context) ([]string, error) {
+	return s.distinct(func(e *Event) string { return e.Type }), nil
+}
+
+// DistinctSources returns all unique event sources.
+func (s *MemStore) DistinctSources(ctx context.Context) ([]string, error) {
+	return s.distinct(func(e *Event) string { return e.Source }), nil
+}
+
+func (s *MemStore) distinct(field func(e *Event) string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set := make(map[string]bool)
+	for i := range s.events {
+		set[field(&s.events[i])] = true
+	}
+	var out []string
+	for v := range set {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// newestFirst returns up to limit matching events in reverse chronological order.
+func (s *MemStore) newestFirst(limit int, match func(e *Event) bool) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Event
+	for i := len(s.events) - 1; i >= 0 && len(out) < limit; i-- {
+		if match(&s.events[i]) {
+			out = append(out, copyEvent(s.events[i]))
+		}
+	}
+	return out
+}
+
+// copyEvent returns a copy of e that shares no mutable state with the store.
+func copyEvent(e Event) Event {
+	e.Causes = append([]string{}, e.Causes...)
+	e.Content = copyContent(e.Content)
+	return e
+}
+
+func copyContent(c map[string]any) map[string]any {
+	if c == nil {
+		return nil
+	}
+	out := make(map[string]any, len(c))
+	for k, v := range c {
+		out[k] = copyValue(v)
+	}
+	return out
+}
+
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return copyContent(v)
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = copyValue(v[i])
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,90 @@
+package eventgraph
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMemStoreConformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T, causes CauseMode) EventStore {
+		return NewMemStore(NewDefaultScrubber(), causes)
+	})
+}
+
+func TestMemStoreVerifyChainDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStore(nil, CausesStrict)
+	mustAppend(t, s, "test.one", "tester", map[string]any{"n": 1}, nil, "")
+	e2 := mustAppend(t, s, "test.two", "tester", map[string]any{"n": 2}, nil, "")
+	mustAppend(t, s, "test.three", "tester", nil, nil, "")
+
+	s.events[s.byID[e2.ID]].Type = "test.forged"
+	if err := s.VerifyChain(ctx, nil); err == nil {
+		t.Fatal("expected hash mismatch after tampering with an event")
+	}
+}
+
+func TestMemStoreVerifyChainDetectsCauseTampering(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStore(nil, CausesStrict)
+	root := mustAppend(t, s, "test.root", "tester", nil, nil, "")
+	other := mustAppend(t, s, "test.other", "tester", nil, nil, "")
+	child := mustAppend(t, s, "test.child", "tester", nil, []string{root.ID}, "")
+
+	s.events[s.byID[child.ID]].Causes = []string{other.ID}
+	if err := s.VerifyChain(ctx, nil); err == nil {
+		t.Fatal("expected hash mismatch after rewriting an event's causes")
+	}
+}
+
+func TestMemStoreAuditCauses(t *testing.T) {
+	s := NewMemStore(nil, CausesStrict)
+	root := mustAppend(t, s, "test.root", "tester", nil, nil, "")
+	child := mustAppend(t, s, "test.child", "tester", nil, []string{root.ID}, "")
+
+	// Written before Append checked causes.
+	s.events[s.byID[root.ID]].Causes = []string{"gone", child.ID}
+	problems, err := s.AuditCauses(context.Background())
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	want := []CauseProblem{
+		{root.ID, "test.root", "gone", CauseDangling},
+		{root.ID, "test.root", child.ID, CauseFuture},
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("audit = %+v, want %+v", problems, want)
+	}
+}
+
+func TestMemStoreVerifyReportsEveryBreak(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStore(nil, CausesStrict)
+	var ev []*Event
+	for i := 0; i < 5; i++ {
+		ev = append(ev, mustAppend(t, s, "test.event", "tester", map[string]any{"i": i}, nil, ""))
+	}
+	s.events[s.byID[ev[1].ID]].Type = "test.forged"
+	s.events[s.byID[ev[3].ID]].PrevHash = "bogus"
+
+	r, err := s.VerifyRange(ctx, "", "", nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	var broken []string
+	for _, b := range r.Breaks {
+		broken = append(broken, b.EventID)
+	}
+	// ev[3] has both a bad link and, since prev_hash is hashed, a bad hash.
+	if want := []string{ev[1].ID, ev[3].ID, ev[3].ID}; strings.Join(broken, ",") != strings.Join(want, ",") {
+		t.Fatalf("breaks: got %v, want %v", r.Breaks, want)
+	}
+	if r.Checked != 5 {
+		t.Errorf("checked %d events, want 5", r.Checked)
+	}
+	if err := s.VerifyChain(ctx, nil); err == nil || !strings.Contains(err.Error(), "and 2 more") {
+		t.Fatalf("VerifyChain error should summarize every break: %v", err)
+	}
+}
@@ -0,0 +1,458 @@
+package eventgraph
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// The event log is also a Merkle tree, built as in RFC 9162 (Certificate
+// Transparency v2) over the events in chain order. Leaf i is event i's hash, so
+// the tree commits to everything the hash chain does, but a single event can
+// be proven present with O(log n) hashes instead of the whole chain, and any
+// later tree can be proven to extend an earlier one.
+
+// Proof types, as in the EGIP PROOF message.
+const (
+	ProofInclusion   = "event_inclusion"
+	ProofConsistency = "tree_consistency"
+)
+
+// ErrTreeSize is returned (wrapped) when a requested tree size is larger than
+// the log, or otherwise can't be proven.
+var ErrTreeSize = errors.New("invalid tree size")
+
+// TreeHead is the root of the tree over the first TreeSize events.
+type TreeHead struct {
+	TreeSize int    `json:"tree_size"`
+	RootHash string `json:"root_hash"`
+}
+
+// InclusionProof proves that an event is leaf LeafIndex of the tree with root
+// RootHash.
+type InclusionProof struct {
+	ProofType string   `json:"proof_type"`
+	EventID   string   `json:"event_id"`
+	EventHash string   `json:"event_hash"`
+	LeafIndex int      `json:"leaf_index"`
+	TreeSize  int      `json:"tree_size"`
+	RootHash  string   `json:"root_hash"`
+	Path      []string `json:"path"` // sibling hashes, leaf to root
+}
+
+// ConsistencyProof proves that the tree of NewSize events extends the tree of
+// OldSize events: nothing in the first OldSize leaves was changed or reordered.
+type ConsistencyProof struct {
+	ProofType string   `json:"proof_type"`
+	OldSize   int      `json:"old_size"`
+	NewSize   int      `json:"new_size"`
+	OldRoot   string   `json:"old_root"`
+	NewRoot   string   `json:"new_root"`
+	Path      []string `json:"path"`
+}
+
+// Verify checks the proof against its own RootHash. It needs no access to the
+// log; callers should also check RootHash against a tree head they trust.
+func (p *InclusionProof) Verify() error {
+	path, err := decodeHashes(p.Path)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(p.RootHash)
+	if err != nil {
+		return fmt.Errorf("root hash: %w", err)
+	}
+	return verifyInclusion(merkleLeafHash(p.EventHash), p.LeafIndex, p.TreeSize, path, root)
+}
+
+// Verify checks the proof against its own OldRoot and NewRoot. Like
+// InclusionProof.Verify, it is only as good as the roots it is given.
+func (p *ConsistencyProof) Verify() error {
+	path, err := decodeHashes(p.Path)
+	if err != nil {
+		return err
+	}
+	oldRoot, err := hex.DecodeString(p.OldRoot)
+	if err != nil {
+		return fmt.Errorf("old root: %w", err)
+	}
+	newRoot, err := hex.DecodeString(p.NewRoot)
+	if err != nil {
+		return fmt.Errorf("new root: %w", err)
+	}
+	return verifyConsistency(p.OldSize, p.NewSize, oldRoot, newRoot, path)
+}
+
+func decodeHashes(in []string) ([][]byte, error) {
+	out := make([][]byte, len(in))
+	for i, h := range in {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("path[%d]: %w", i, err)
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+func encodeHashes(in [][]byte) []string {
+	out := make([]string, len(in))
+	for i, h := range in {
+		out[i] = hex.EncodeToString(h)
+	}
+	return out
+}
+
+// merkleLeafHash hashes an event hash into a leaf. The 0x00/0x01 prefixes keep
+// leaves and interior nodes from ever colliding.
+func merkleLeafHash(eventHash string) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write([]byte(eventHash))
+	return h.Sum(nil)
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// splitPoint returns the largest power of two smaller than n (n > 1).
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// emptyRoot is the root of the tree with no leaves.
+func emptyRoot() []byte {
+	h := sha256.Sum256(nil)
+	return h[:]
+}
+
+// merkleTree is a Merkle tree that grows a leaf at a time. It keeps the hash
+// of every perfect subtree completed so far, nodes[h][i] covering leaves
+// i<<h to (i+1)<<h - 1, so adding a leaf hashes at most log n nodes, and the
+// root of any prefix of the tree, or a proof in it, takes O(log n) of them.
+type merkleTree struct {
+	nodes [][][]byte
+}
+
+// size returns the number of leaves.
+func (t *merkleTree) size() int {
+	if len(t.nodes) == 0 {
+		return 0
+	}
+	return len(t.nodes[0])
+}
+
+// add appends a leaf, completing the perfect subtrees it is the last leaf of.
+func (t *merkleTree) add(leaf []byte) {
+	node := leaf
+	for h := 0; ; h++ {
+		if h == len(t.nodes) {
+			t.nodes = append(t.nodes, nil)
+		}
+		t.nodes[h] = append(t.nodes[h], node)
+		n := len(t.nodes[h])
+		if n%2 == 1 {
+			return
+		}
+		node = merkleNodeHash(t.nodes[h][n-2], t.nodes[h][n-1])
+	}
+}
+
+// root returns the root of the first n leaves.
+func (t *merkleTree) root(n int) []byte {
+	if n == 0 {
+		return emptyRoot()
+	}
+	return t.subtree(0, n)
+}
+
+// subtree returns the hash of the n leaves from start (n > 0), a range
+// RFC 9162's recursion splits the tree into: a perfect subtree, which is
+// looked up, followed by fewer leaves than it holds.
+func (t *merkleTree) subtree(start, n int) []byte {
+	if n&(n-1) == 0 {
+		h := bits.TrailingZeros(uint(n))
+		return t.nodes[h][start>>h]
+	}
+	k := splitPoint(n)
+	return merkleNodeHash(t.subtree(start, k), t.subtree(start+k, n-k))
+}
+
+// inclusionPath returns the audit path for leaf m in the tree of the first n
+// leaves.
+func (t *merkleTree) inclusionPath(m, n int) [][]byte {
+	return t.path(m, 0, n)
+}
+
+func (t *merkleTree) path(m, start, n int) [][]byte {
+	if n <= 1 {
+		return nil
+	}
+	k := splitPoint(n)
+	if m < k {
+		return append(t.path(m, start, k), t.subtree(start+k, n-k))
+	}
+	return append(t.path(m-k, start+k, n-k), t.subtree(start, k))
+}
+
+// consistencyPath returns the proof that the tree of the first m leaves is a
+// prefix of the tree of the first n.
+func (t *merkleTree) consistencyPath(m, n int) [][]byte {
+	if m == 0 || m == n {
+		return nil
+	}
+	return t.subproof(m, 0, n, true)
+}
+
+func (t *merkleTree) subproof(m, start, n int, complete bool) [][]byte {
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{t.subtree(start, n)}
+	}
+	k := splitPoint(n)
+	if m <= k {
+		return append(t.subproof(m, start, k, complete), t.subtree(start+k, n-k))
+	}
+	return append(t.subproof(m-k, start+k, n-k, false), t.subtree(start, k))
+}
+
+// verifyInclusion is RFC 9162 section 2.1.3.2.
+func verifyInclusion(leaf []byte, index, size int, path [][]byte, root []byte) error {
+	if index < 0 || index >= size {
+		return fmt.Errorf("leaf index %d outside tree of size %d", index, size)
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range path {
+		if sn == 0 {
+			return fmt.Errorf("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("inclusion proof is too short")
+	}
+	if !bytes.Equal(r, root) {
+		return fmt.Errorf("inclusion proof does not lead to root %x", root)
+	}
+	return nil
+}
+
+// verifyConsistency is RFC 9162 section 2.1.4.2, extended so that the empty
+// tree is consistent with every tree.
+func verifyConsistency(oldSize, newSize int, oldRoot, newRoot []byte, path [][]byte) error {
+	switch {
+	case oldSize < 0 || oldSize > newSize:
+		return fmt.Errorf("old size %d does not precede new size %d", oldSize, newSize)
+	case oldSize == 0:
+		if len(path) != 0 {
+			return fmt.Errorf("consistency proof from the empty tree must be empty")
+		}
+		if !bytes.Equal(oldRoot, emptyRoot()) {
+			return fmt.Errorf("old root is not the empty tree's root")
+		}
+		return nil
+	case oldSize == newSize:
+		if len(path) != 0 {
+			return fmt.Errorf("consistency proof between equal sizes must be empty")
+		}
+		if !bytes.Equal(oldRoot, newRoot) {
+			return fmt.Errorf("roots of trees of equal size differ")
+		}
+		return nil
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		// The old tree is a complete subtree; its root is the proof's implicit
+		// first node.
+		path = append([][]byte{oldRoot}, path...)
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("consistency proof is empty")
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return fmt.Errorf("consistency proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = merkleNodeHash(c, fr)
+			sr = merkleNodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = merkleNodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("consistency proof is too short")
+	}
+	if !bytes.Equal(fr, oldRoot) {
+		return fmt.Errorf("consistency proof does not lead to old root %x", oldRoot)
+	}
+	if !bytes.Equal(sr, newRoot) {
+		return fmt.Errorf("consistency proof does not lead to new root %x", newRoot)
+	}
+	return nil
+}
+
+// merkleLog caches the tree for a store. Appends only ever add leaves at the
+// end, so each call reads just the events added since the last. Archived
+// events keep their leaves, from their stubs.
+type merkleLog struct {
+	mu     sync.Mutex
+	tree   merkleTree
+	index  map[string]int // event ID -> leaf index
+	lastID string
+}
+
+// sync brings the cache up to date and returns the number of leaves. If the
+// event count disagrees with the cache afterwards — an event landed earlier
+// in chain order than the last one cached, or the last one cached was
+// archived — it is rebuilt from genesis. Callers hold l.mu.
+func (l *merkleLog) sync(ctx context.Context, s chainScanner) (int, error) {
+	addLeaf := func(id, hash string) {
+		l.index[id] = l.tree.size()
+		l.tree.add(merkleLeafHash(hash))
+	}
+	add := func(e *Event, _ []byte) {
+		addLeaf(e.ID, e.Hash)
+		l.lastID = e.ID
+	}
+	if l.index == nil {
+		l.index = make(map[string]int)
+	}
+	if l.lastID != "" {
+		if err := s.scanChain(ctx, l.lastID, false, "", add); err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+	}
+	n, err := s.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, archived, err := s.archivedHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if archived+n != l.tree.size() {
+		l.tree, l.index, l.lastID = merkleTree{}, make(map[string]int), ""
+		if err := s.scanArchived(ctx, addLeaf); err != nil {
+			return 0, err
+		}
+		if err := s.scanChain(ctx, "", true, "", add); err != nil {
+			return 0, err
+		}
+	}
+	return l.tree.size(), nil
+}
+
+// treeSize resolves a requested size against the log; 0 means the whole log.
+func treeSize(requested, n int) (int, error) {
+	if requested == 0 {
+		return n, nil
+	}
+	if requested < 0 || requested > n {
+		return 0, fmt.Errorf("tree size %d (log has %d events): %w", requested, n, ErrTreeSize)
+	}
+	return requested, nil
+}
+
+// treeHead implements EventStore.TreeHead.
+func treeHead(ctx context.Context, s chainScanner, l *merkleLog, size int) (*TreeHead, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.sync(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("tree head: %w", err)
+	}
+	if size, err = treeSize(size, n); err != nil {
+		return nil, err
+	}
+	return &TreeHead{TreeSize: size, RootHash: hex.EncodeToString(l.tree.root(size))}, nil
+}
+
+// inclusionProof implements EventStore.InclusionProof.
+func inclusionProof(ctx context.Context, s chainScanner, l *merkleLog, id string, size int) (*InclusionProof, error) {
+	e, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("inclusion proof: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.sync(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("inclusion proof: %w", err)
+	}
+	if size, err = treeSize(size, n); err != nil {
+		return nil, err
+	}
+	i, ok := l.index[id]
+	if !ok || i >= size {
+		return nil, fmt.Errorf("event %s is not in the tree of size %d: %w", id, size, ErrTreeSize)
+	}
+	return &InclusionProof{
+		ProofType: ProofInclusion,
+		EventID:   e.ID,
+		EventHash: e.Hash,
+		LeafIndex: i,
+		TreeSize:  size,
+		RootHash:  hex.EncodeToString(l.tree.root(size)),
+		Path:      encodeHashes(l.tree.inclusionPath(i, size)),
+	}, nil
+}
+
+// consistencyProof implements EventStore.ConsistencyProof.
+func consistencyProof(ctx context.Context, s chainScanner, l *merkleLog, oldSize, newSize int) (*ConsistencyProof, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.sync(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("consistency proof: %w", err)
+	}
+	if newSize, err = treeSize(newSize, n); err != nil {
+		return nil, err
+	}
+	if oldSize < 0 || oldSize > newSize {
+		return nil, fmt.Errorf("old size %d must be between 0 and %d: %w", oldSize, newSize, ErrTreeSize)
+	}
+	return &ConsistencyProof{
+		ProofType: ProofConsistency,
+		OldSize:   oldSize,
+		NewSize:   newSize,
+		OldRoot:   hex.EncodeToString(l.tree.root(oldSize)),
+		NewRoot:   hex.EncodeToString(l.tree.root(newSize)),
+		Path:      encodeHashes(l.tree.consistencyPath(oldSize, newSize)),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package eventgraph
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = merkleLeafHash(fmt.Sprintf("event-%d", i))
+	}
+	return leaves
+}
+
+func testTree(leaves [][]byte) *merkleTree {
+	t := &merkleTree{}
+	for _, l := range leaves {
+		t.add(l)
+	}
+	return t
+}
+
+// naiveRoot is RFC 9162's definition of the root, computed from every leaf.
+func naiveRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return emptyRoot()
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return merkleNodeHash(naiveRoot(leaves[:k]), naiveRoot(leaves[k:]))
+}
+
+func TestMerkleRootShape(t *testing.T) {
+	l := testLeaves(3)
+	want := merkleNodeHash(merkleNodeHash(l[0], l[1]), l[2])
+	if got := testTree(l).root(3); !bytes.Equal(got, want) {
+		t.Fatalf("root of 3 leaves: got %x, want %x", got, want)
+	}
+	leaves := testLeaves(70)
+	tree := testTree(leaves)
+	for n := 0; n <= len(leaves); n++ {
+		if got, want := tree.root(n), naiveRoot(leaves[:n]); !bytes.Equal(got, want) {
+			t.Fatalf("root of the first %d of %d leaves: got %x, want %x", n, len(leaves), got, want)
+		}
+	}
+	if bytes.Equal(merkleLeafHash("x"), merkleNodeHash(nil, []byte("x"))) {
+		t.Fatal("leaf and node hashes must be domain-separated")
+	}
+}
+
+func TestMerkleInclusionProofs(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := testLeaves(n)
+		tree := testTree(testLeaves(n + 5))
+		root := tree.root(n)
+		for m := 0; m < n; m++ {
+			path := tree.inclusionPath(m, n)
+			if err := verifyInclusion(leaves[m], m, n, path, root); err != nil {
+				t.Fatalf("n=%d m=%d: %v", n, m, err)
+			}
+			if n > 1 {
+				if err := verifyInclusion(leaves[m], (m+1)%n, n, path, root); err == nil {
+					t.Fatalf("n=%d m=%d: proof accepted at the wrong index", n, m)
+				}
+				bad := append([][]byte{}, path...)
+				bad[0] = merkleLeafHash("forged")
+				if err := verifyInclusion(leaves[m], m, n, bad, root); err == nil {
+					t.Fatalf("n=%d m=%d: tampered path accepted", n, m)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleConsistencyProofs(t *testing.T) {
+	tree := testTree(testLeaves(17))
+	for n := 0; n <= tree.size(); n++ {
+		newRoot := tree.root(n)
+		for m := 0; m <= n; m++ {
+			oldRoot := tree.root(m)
+			path := tree.consistencyPath(m, n)
+			if err := verifyConsistency(m, n, oldRoot, newRoot, path); err != nil {
+				t.Fatalf("m=%d n=%d: %v", m, n, err)
+			}
+		}
+	}
+}
+
+func TestMerkleConsistencyDetectsRewrite(t *testing.T) {
+	leaves := testLeaves(10)
+	oldRoot := testTree(leaves).root(6)
+
+	rewritten := append([][]byte{}, leaves...)
+	rewritten[3] = merkleLeafHash("rewritten")
+	tree := testTree(rewritten)
+	path := tree.consistencyPath(6, 10)
+	if err := verifyConsistency(6, 10, oldRoot, tree.root(10), path); err == nil {
+		t.Fatal("consistency proof accepted after an old leaf was rewritten")
+	}
+}
+
+func TestInclusionProofVerify(t *testing.T) {
+	p := &InclusionProof{ProofType: ProofInclusion, EventHash: "abc", LeafIndex: 0, TreeSize: 1}
+	p.RootHash = fmt.Sprintf("%x", merkleLeafHash("abc"))
+	if err := p.Verify(); err != nil {
+		t.Fatalf("single-leaf proof: %v", err)
+	}
+	p.EventHash = "abd"
+	if err := p.Verify(); err == nil {
+		t.Fatal("proof accepted for a different event hash")
+	}
+}
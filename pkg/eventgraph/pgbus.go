@@ -0,0 +1,125 @@
+package eventgraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// notifyChannel is the Postgres NOTIFY channel PgStore announces appends on.
+// The payload is the event ID. Notifications are sent inside the append
+// transaction, so they are delivered on commit and never for a rollback.
+const notifyChannel = "mz5_events"
+
+// PgBus is a Bus whose subscribers see events appended by any process using
+// the same Postgres database, not just this one. It LISTENs for the
+// notifications PgStore sends on append and fans out the new events; Listen
+// must be running for anything to be delivered.
+type PgBus struct {
+	*Bus
+	pool   *pgxpool.Pool
+	lastID string // last event delivered; only touched by Listen
+}
+
+// NewPgBus creates a PgBus over store, listening on a connection from pool.
+func NewPgBus(store EventStore, pool *pgxpool.Pool) *PgBus {
+	return &PgBus{Bus: NewBus(store), pool: pool}
+}
+
+// Append stores the event. Unlike Bus.Append it doesn't publish: the event
+// comes back as a notification like everyone else's, so local and remote
+// subscribers see one stream in commit order.
+func (b *PgBus) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error) {
+	return b.EventStore.Append(ctx, eventType, source, content, causes, conversationID, signer)
+}
+
+// AppendBatch stores the events without publishing; see Append.
+func (b *PgBus) AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error) {
+	return b.EventStore.AppendBatch(ctx, reqs)
+}
+
+// Redact redacts the event without publishing the event.redacted event that
+// records it; see Append.
+func (b *PgBus) Redact(ctx context.Context, id string, req RedactRequest) (*Event, error) {
+	return b.EventStore.Redact(ctx, id, req)
+}
+
+// Listen delivers events to subscribers until ctx is cancelled, reconnecting
+// after errors. Events appended while it was disconnected are delivered once
+// it reconnects.
+func (b *PgBus) Listen(ctx context.Context) error {
+	if b.lastID == "" {
+		head, err := b.EventStore.Recent(ctx, 1)
+		if err != nil {
+			return fmt.Errorf("pgbus: read chain head: %w", err)
+		}
+		if len(head) > 0 {
+			b.lastID = head[0].ID
+		}
+	}
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("pgbus: %v; reconnecting", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (b *PgBus) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire: %w", err)
+	}
+	// The connection stays subscribed to the channel, so it mustn't go back
+	// to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	// Pick up anything appended before LISTEN took effect.
+	if err := b.catchUp(ctx); err != nil {
+		return err
+	}
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		// Rather than fetch the notified ID, read everything after the last
+		// delivered event: it dedupes, preserves chain order, and covers
+		// any notification that was coalesced or lost.
+		if err := b.catchUp(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// catchUp publishes every event after lastID.
+func (b *PgBus) catchUp(ctx context.Context) error {
+	const page = 100
+	for {
+		// An empty lastID means the log was empty when Listen started, and
+		// Since reads from genesis.
+		events, err := b.EventStore.Since(ctx, b.lastID, page)
+		if err != nil {
+			return fmt.Errorf("read new events: %w", err)
+		}
+		for i := range events {
+			b.Publish(&events[i])
+			b.lastID = events[i].ID
+		}
+		if len(events) < page {
+			return nil
+		}
+	}
+}
@@ -2,167 +2,304 @@ package eventgraph
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mind-zero-five/pkg/page"
 )
 
 // PgStore is a PostgreSQL-backed EventStore with hash-chained integrity.
 type PgStore struct {
-	pool *pgxpool.Pool
+	pool     pgConn
+	merkle   merkleLog
+	scrubber *Scrubber
+	causes   CauseMode
+}
+
+// NewPgStore creates a PgStore that masks secrets in appended content with
+// scrubber and treats causes that aren't on the chain as causes says.
+func NewPgStore(pool *pgxpool.Pool, scrubber *Scrubber, causes CauseMode) *PgStore {
+	return &PgStore{pool: pool, scrubber: scrubber, causes: causes}
 }
 
-// NewPgStore creates a PgStore.
-func NewPgStore(pool *pgxpool.Pool) *PgStore {
-	return &PgStore{pool: pool}
+// NewPgStoreTx creates a PgStore whose statements all run in tx, so its
+// writes commit or roll back with the caller's. It is only usable until tx
+// ends.
+func NewPgStoreTx(tx pgx.Tx, scrubber *Scrubber, causes CauseMode) *PgStore {
+	return &PgStore{pool: tx, scrubber: scrubber, causes: causes}
 }
 
-// EnsureTable creates the events table if it doesn't exist.
-func (s *PgStore) EnsureTable(ctx context.Context) error {
-	_, err := s.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS events (
-			id              TEXT PRIMARY KEY,
-			type            TEXT NOT NULL,
-			timestamp       TIMESTAMPTZ NOT NULL,
-			source          TEXT NOT NULL,
-			content         JSONB NOT NULL DEFAULT '{}',
-			causes          TEXT[] DEFAULT '{}',
-			conversation_id TEXT NOT NULL DEFAULT '',
-			hash            TEXT NOT NULL,
-			prev_hash       TEXT NOT NULL DEFAULT ''
-		)`)
+// pgConn is the part of *pgxpool.Pool the store uses. pgx.Tx has it too, so
+// a store can run inside a caller's transaction (see NewPgStoreTx).
+type pgConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const pgEventColumns = `id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash, hash_version, signature`
+
+// appendLockKey is the pg_advisory_xact_lock key serializing appends. A
+// transaction-scoped advisory lock exists even when the events table is
+// empty, unlike a row lock on the newest event, so two first appends can't
+// both see an empty chain and fork it.
+const appendLockKey int64 = 0x6d7a355f6368616e // "mz5_chan"
+
+// Append creates and stores a new event, computing the hash chain.
+func (s *PgStore) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error) {
+	events, err := s.AppendBatch(ctx, []AppendRequest{{
+		Type: eventType, Source: source, Content: content, Causes: causes, ConversationID: conversationID, Signer: signer,
+	}})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_events_type ON events(type)`)
-	if err != nil {
-		return err
+	return events[0], nil
+}
+
+// AppendBatch appends events consecutively in one transaction: either all
+// are stored, adjacent on the chain in order, or none are.
+func (s *PgStore) AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error) {
+	if len(reqs) == 0 {
+		return nil, nil
 	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_events_source ON events(source)`)
+	pending, err := prepareBatch(reqs, s.scrubber)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_events_timestamp_id ON events(timestamp, id)`)
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_events_causes ON events USING GIN(causes)`)
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return nil, fmt.Errorf("lock chain: %w", err)
+	}
+	var head chainHead
+	err = tx.QueryRow(ctx, `SELECT hash, timestamp FROM events ORDER BY timestamp DESC, id DESC LIMIT 1`).Scan(&head.hash, &head.timestamp)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("read chain head: %w", err)
+	}
+	exists, err := pgExisting(ctx, tx, causeIDs(pending))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := checkCauses(pending, exists, s.causes); err != nil {
+		return nil, err
 	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_events_conversation ON events(conversation_id) WHERE conversation_id != ''`)
-	return err
-}
 
-// Append creates and stores a new event, computing the hash chain.
-func (s *PgStore) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string) (*Event, error) {
-	if content == nil {
-		content = map[string]any{}
+	events := make([]*Event, len(pending))
+	batch := &pgx.Batch{}
+	for i := range pending {
+		e, err := pending[i].link(&head)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = e
+		batch.Queue(`
+			INSERT INTO events (`+pgEventColumns+`)
+			VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7, $8, $9, $10, $11)`,
+			e.ID, e.Type, e.Timestamp, e.Source, string(pending[i].contentJSON), e.Causes, e.ConversationID, e.Hash, e.PrevHash, e.HashVersion, e.Signature)
+		// Wake PgBus listeners in every process once this commits.
+		batch.Queue(`SELECT pg_notify($1, $2)`, notifyChannel, e.ID)
 	}
-	if causes == nil {
-		causes = []string{}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("insert event: %w", err)
 	}
 
-	contentJSON, err := json.Marshal(content)
-	if err != nil {
-		return nil, fmt.Errorf("marshal content: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit event: %w", err)
 	}
+	return events, nil
+}
 
-	now := time.Now().Truncate(time.Microsecond)
-	id := uuid.Must(uuid.NewV7()).String()
-
+func (s *PgStore) importEvents(ctx context.Context, events []StoredEvent) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("begin tx: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	var prevHash string
-	err = tx.QueryRow(ctx, `SELECT hash FROM events ORDER BY timestamp DESC, id DESC LIMIT 1 FOR UPDATE`).Scan(&prevHash)
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return fmt.Errorf("lock chain: %w", err)
+	}
+	var held bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM events) OR EXISTS (SELECT 1 FROM archived_events)`).Scan(&held)
 	if err != nil {
-		prevHash = ""
+		return fmt.Errorf("import events: %w", err)
 	}
+	if held {
+		return fmt.Errorf("import events: the store already holds events")
+	}
+	if err := tx.SendBatch(ctx, pgInsertStored(events)).Close(); err != nil {
+		return fmt.Errorf("import events: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("import events: commit: %w", err)
+	}
+	return nil
+}
 
-	hash := computeHash(prevHash, id, eventType, source, conversationID, now, contentJSON)
-
-	e := &Event{
-		ID:             id,
-		Type:           eventType,
-		Timestamp:      now,
-		Source:         source,
-		Content:        content,
-		Causes:         causes,
-		ConversationID: conversationID,
-		Hash:           hash,
-		PrevHash:       prevHash,
+// pgInsertStored queues inserts of events as they are, hashes and all.
+func pgInsertStored(events []StoredEvent) *pgx.Batch {
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(`
+			INSERT INTO events (`+pgEventColumns+`)
+			VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7, $8, $9, $10, $11)`,
+			e.ID, e.Type, e.Timestamp, e.Source, string(e.Content), e.Causes, e.ConversationID, e.Hash, e.PrevHash, e.HashVersion, e.Signature)
 	}
+	return batch
+}
 
-	_, err = tx.Exec(ctx, `
-		INSERT INTO events (id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash)
-		VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7, $8, $9)`,
-		e.ID, e.Type, e.Timestamp, e.Source, string(contentJSON), e.Causes, e.ConversationID, e.Hash, e.PrevHash)
+// pgExisting reports which of ids are events in tx, live or archived.
+func pgExisting(ctx context.Context, tx pgx.Tx, ids []string) (map[string]bool, error) {
+	exists := make(map[string]bool)
+	if len(ids) == 0 {
+		return exists, nil
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM events WHERE id = ANY($1)
+		UNION ALL
+		SELECT id FROM archived_events WHERE id = ANY($1)`, ids)
 	if err != nil {
-		return nil, fmt.Errorf("insert event: %w", err)
+		return nil, fmt.Errorf("look up causes: %w", err)
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit event: %w", err)
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("look up causes: %w", err)
+		}
+		exists[id] = true
 	}
-
-	return e, nil
+	return exists, rows.Err()
 }
 
 // Get retrieves a single event by ID.
 func (s *PgStore) Get(ctx context.Context, id string) (*Event, error) {
 	e, err := s.scanOne(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
+		SELECT `+pgEventColumns+`
 		FROM events WHERE id = $1`, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get event %s: %w", id, ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("get event %s: %w", id, err)
 	}
 	return e, nil
 }
 
+// Redact replaces an event's content with a tombstone and records it.
+func (s *PgStore) Redact(ctx context.Context, id string, req RedactRequest) (*Event, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Under the append lock, so concurrent redactions of one event queue.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return nil, fmt.Errorf("lock chain: %w", err)
+	}
+	var contentJSON []byte
+	in := NewPgStoreTx(tx, s.scrubber, s.causes)
+	e, err := in.Get(ctx, id)
+	if err == nil {
+		// Read the content as stored, numbers exact. JSONB may have
+		// reformatted the text; the commitment is over the canonical form,
+		// which doesn't depend on it.
+		err = tx.QueryRow(ctx, `SELECT content FROM events WHERE id = $1`, id).Scan(&contentJSON)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+	r, err := prepareRedaction(e, contentJSON, req)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := in.AppendBatch(ctx, []AppendRequest{r.audit})
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	raw, _, err := r.tombstone(audit[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE events SET content = $2::jsonb WHERE id = $1`, id, string(raw)); err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("redact %s: commit: %w", id, err)
+	}
+	return audit[0], nil
+}
+
 // Recent returns the most recent events in reverse chronological order.
 func (s *PgStore) Recent(ctx context.Context, limit int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
+		SELECT `+pgEventColumns+`
 		FROM events ORDER BY timestamp DESC, id DESC LIMIT $1`, limit)
 }
 
 // ByType returns events filtered by type (exact match or prefix with %).
 func (s *PgStore) ByType(ctx context.Context, eventType string, limit int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
+		SELECT `+pgEventColumns+`
 		FROM events WHERE type = $1 ORDER BY timestamp DESC, id DESC LIMIT $2`, eventType, limit)
 }
 
 // BySource returns events filtered by source.
 func (s *PgStore) BySource(ctx context.Context, source string, limit int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
+		SELECT `+pgEventColumns+`
 		FROM events WHERE source = $1 ORDER BY timestamp DESC, id DESC LIMIT $2`, source, limit)
 }
 
 // ByConversation returns events in a conversation in chronological order.
 func (s *PgStore) ByConversation(ctx context.Context, conversationID string, limit int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
+		SELECT `+pgEventColumns+`
 		FROM events WHERE conversation_id = $1 ORDER BY timestamp ASC, id ASC LIMIT $2`, conversationID, limit)
 }
 
 // Since returns events created after the given ID, for polling/SSE.
 func (s *PgStore) Since(ctx context.Context, afterID string, limit int) ([]Event, error) {
+	if afterID == "" {
+		return s.scanMany(ctx, `
+			SELECT `+pgEventColumns+`
+			FROM events ORDER BY timestamp ASC, id ASC LIMIT $1`, limit)
+	}
 	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
-		FROM events WHERE (timestamp, id) > (SELECT timestamp, id FROM events WHERE id = $1)
+		SELECT `+pgEventColumns+`
+		FROM events WHERE (timestamp, id) > (
+			SELECT timestamp, id FROM events WHERE id = $1
+			UNION ALL SELECT timestamp, id FROM archived_events WHERE id = $1)
 		ORDER BY timestamp ASC, id ASC LIMIT $2`, afterID, limit)
 }
 
+// Query returns a page of the events matching q.
+func (s *PgStore) Query(ctx context.Context, q Query) (*page.Page[Event], error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	c, _ := page.Parse(q.Cursor)
+	b := &sqlQuery{}
+	events, err := s.scanMany(ctx, b.build(q, c, pgEventColumns), b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	return page.Build(events, q.limit(), c, eventKey), nil
+}
+
 // Count returns the total number of events.
 func (s *PgStore) Count(ctx context.Context) (int, error) {
 	var n int
@@ -173,93 +310,608 @@ func (s *PgStore) Count(ctx context.Context) (int, error) {
 	return n, nil
 }
 
-// VerifyChain walks the entire chain chronologically and verifies hash integrity.
-func (s *PgStore) VerifyChain(ctx context.Context) error {
+// VerifyChain walks the entire chain chronologically and verifies hash
+// integrity and, if keys is non-nil, signatures.
+func (s *PgStore) VerifyChain(ctx context.Context, keys KeyLookup) error {
+	r, err := verifyRange(ctx, s, "", "", keys)
+	if err != nil {
+		return err
+	}
+	return r.Err()
+}
+
+// VerifyRange verifies fromID..toID inclusive.
+func (s *PgStore) VerifyRange(ctx context.Context, fromID, toID string, keys KeyLookup) (*VerifyReport, error) {
+	return verifyRange(ctx, s, fromID, toID, keys)
+}
+
+// VerifySince verifies every event after a checkpoint.
+func (s *PgStore) VerifySince(ctx context.Context, checkpoint *Checkpoint, keys KeyLookup) (*VerifyReport, error) {
+	return verifySince(ctx, s, checkpoint, keys)
+}
+
+// SaveCheckpoint records a verification checkpoint.
+func (s *PgStore) SaveCheckpoint(ctx context.Context, report *VerifyReport, source string, signer Signer) (*Checkpoint, error) {
+	c, err := newCheckpoint(uuid.Must(uuid.NewV7()).String(), report, source, signer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO verification_checkpoints (id, event_id, event_hash, verified_at, source, signature)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		c.ID, c.EventID, c.EventHash, c.VerifiedAt, c.Source, c.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("save checkpoint: %w", err)
+	}
+	return c, nil
+}
+
+// LatestCheckpoint returns the most recent checkpoint, or nil.
+func (s *PgStore) LatestCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	var c Checkpoint
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, event_id, event_hash, verified_at, source, signature
+		FROM verification_checkpoints ORDER BY verified_at DESC, id DESC LIMIT 1`).
+		Scan(&c.ID, &c.EventID, &c.EventHash, &c.VerifiedAt, &c.Source, &c.Signature)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("latest checkpoint: %w", err)
+	}
+	return &c, nil
+}
+
+// AckConsumer advances a consumer's position to eventID.
+func (s *PgStore) AckConsumer(ctx context.Context, name, eventID string) error {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 UNION ALL SELECT 1 FROM archived_events WHERE id = $1)`,
+		eventID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("ack %s: %w", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("ack %s: event %s: %w", name, eventID, ErrNotFound)
+	}
+	_, err = s.pool.Exec(ctx, `
+		WITH chain AS (SELECT id, timestamp FROM events UNION ALL SELECT id, timestamp FROM archived_events)
+		INSERT INTO consumer_offsets (name, event_id, acked_at) VALUES ($1, $2, now())
+		ON CONFLICT (name) DO UPDATE SET event_id = EXCLUDED.event_id, acked_at = EXCLUDED.acked_at
+		WHERE NOT EXISTS (
+			SELECT 1 FROM chain cur, chain acked
+			WHERE cur.id = consumer_offsets.event_id AND acked.id = EXCLUDED.event_id
+			  AND (acked.timestamp, acked.id) <= (cur.timestamp, cur.id))`,
+		name, eventID)
+	if err != nil {
+		return fmt.Errorf("ack %s: %w", name, err)
+	}
+	return nil
+}
+
+// ConsumerPosition returns the last event a consumer acked, or "".
+func (s *PgStore) ConsumerPosition(ctx context.Context, name string) (string, error) {
+	var id string
+	err := s.pool.QueryRow(ctx, `SELECT event_id FROM consumer_offsets WHERE name = $1`, name).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("consumer %s position: %w", name, err)
+	}
+	return id, nil
+}
+
+// Consumers lists consumer positions by name, with their lag.
+func (s *PgStore) Consumers(ctx context.Context) ([]ConsumerState, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
-		FROM events ORDER BY timestamp ASC, id ASC`)
+		WITH chain AS (SELECT id, timestamp FROM events UNION ALL SELECT id, timestamp FROM archived_events)
+		SELECT c.name, c.event_id, c.acked_at,
+			(SELECT COUNT(*) FROM chain e WHERE p.id IS NULL OR (e.timestamp, e.id) > (p.timestamp, p.id))
+		FROM consumer_offsets c LEFT JOIN chain p ON p.id = c.event_id
+		ORDER BY c.name`)
 	if err != nil {
-		return fmt.Errorf("verify chain query: %w", err)
+		return nil, fmt.Errorf("list consumers: %w", err)
 	}
 	defer rows.Close()
-
-	prevHash := ""
-	i := 0
+	out := []ConsumerState{}
 	for rows.Next() {
-		var e Event
-		var contentJSON []byte
-		err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash)
-		if err != nil {
-			return fmt.Errorf("verify chain scan row %d: %w", i, err)
+		var c ConsumerState
+		if err := rows.Scan(&c.Name, &c.EventID, &c.AckedAt, &c.Lag); err != nil {
+			return nil, fmt.Errorf("scan consumer: %w", err)
 		}
-		if err := json.Unmarshal(contentJSON, &e.Content); err != nil {
-			e.Content = map[string]any{"_raw": string(contentJSON)}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// CreateConversation starts a conversation.
+func (s *PgStore) CreateConversation(ctx context.Context, id, title, createdBy string) (*Conversation, error) {
+	c := newConversation(id, title, createdBy)
+	if err := s.insertConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("create conversation %s: %w", c.ID, err)
+	}
+	return s.GetConversation(ctx, c.ID)
+}
+
+// ImportConversation inserts a conversation as given.
+func (s *PgStore) ImportConversation(ctx context.Context, c *Conversation) error {
+	if err := s.insertConversation(ctx, c); err != nil {
+		return fmt.Errorf("import conversation %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+func (s *PgStore) insertConversation(ctx context.Context, c *Conversation) error {
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO conversations (id, title, created_by, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING`,
+		c.ID, c.Title, c.CreatedBy, c.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConversationExists
+	}
+	return nil
+}
+
+// GetConversation returns a conversation with its participants and activity.
+func (s *PgStore) GetConversation(ctx context.Context, id string) (*Conversation, error) {
+	cs, err := s.conversations(ctx, id, page.Cursor{}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation %s: %w", id, err)
+	}
+	if len(cs) == 0 {
+		return nil, conversationNotFound(id)
+	}
+	return &cs[0], nil
+}
+
+// RetitleConversation changes a conversation's title.
+func (s *PgStore) RetitleConversation(ctx context.Context, id, title string) (*Conversation, error) {
+	tag, err := s.pool.Exec(ctx, `UPDATE conversations SET title = $1 WHERE id = $2`, title, id)
+	if err != nil {
+		return nil, fmt.Errorf("retitle conversation %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, conversationNotFound(id)
+	}
+	return s.GetConversation(ctx, id)
+}
+
+// Conversations lists conversations, most recently active first.
+func (s *PgStore) Conversations(ctx context.Context, limit int) ([]Conversation, error) {
+	cs, err := s.conversations(ctx, "", page.Cursor{}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	return cs, nil
+}
+
+// ConversationsPage returns a page of conversations, most recently active
+// first.
+func (s *PgStore) ConversationsPage(ctx context.Context, cursor string, limit int) (*page.Page[Conversation], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := s.conversations(ctx, "", c, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	return page.Build(cs, limit, c, conversationKey), nil
+}
+
+// conversations reads conversation id, or all of them if id is "", with
+// what their events say about them, from cursor c on.
+func (s *PgStore) conversations(ctx context.Context, id string, c page.Cursor, limit int) ([]Conversation, error) {
+	cmp, order := c.Keyset(true)
+	rows, err := s.pool.Query(ctx, `
+		SELECT * FROM (
+			SELECT c.id, c.title, c.created_by, c.created_at, count(e.id),
+				coalesce(max(e.timestamp), c.created_at) AS last_activity,
+				array_remove(array_agg(DISTINCT e.source), NULL)
+			FROM conversations c
+			LEFT JOIN events e ON e.conversation_id = c.id AND e.conversation_id != ''
+			WHERE $1 = '' OR c.id = $1
+			GROUP BY c.id) c
+		WHERE $4 = '' OR (last_activity, id) `+cmp+` ($3::timestamptz, $4)
+		ORDER BY last_activity `+order+`, id `+order+` LIMIT $2`, id, limit, c.Time, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Conversation{}
+	for rows.Next() {
+		var c Conversation
+		var sources []string
+		if err := rows.Scan(&c.ID, &c.Title, &c.CreatedBy, &c.CreatedAt, &c.EventCount, &c.LastActivity, &sources); err != nil {
+			return nil, err
 		}
+		c.addParticipants(sources)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
 
-		if e.PrevHash != prevHash {
-			return fmt.Errorf("event %d (%s): prev_hash mismatch: got %s, want %s", i, e.ID, e.PrevHash, prevHash)
+// TreeHead returns the Merkle root over the first treeSize events.
+func (s *PgStore) TreeHead(ctx context.Context, treeSize int) (*TreeHead, error) {
+	return treeHead(ctx, s, &s.merkle, treeSize)
+}
+
+// InclusionProof proves that an event is in the tree of treeSize events.
+func (s *PgStore) InclusionProof(ctx context.Context, id string, treeSize int) (*InclusionProof, error) {
+	return inclusionProof(ctx, s, &s.merkle, id, treeSize)
+}
+
+// ConsistencyProof proves that the tree of newSize events extends the tree
+// of oldSize events.
+func (s *PgStore) ConsistencyProof(ctx context.Context, oldSize, newSize int) (*ConsistencyProof, error) {
+	return consistencyProof(ctx, s, &s.merkle, oldSize, newSize)
+}
+
+func (s *PgStore) hashBefore(ctx context.Context, id string) (string, error) {
+	var hash string
+	err := s.pool.QueryRow(ctx, `
+		SELECT hash FROM events
+		WHERE (timestamp, id) < (SELECT timestamp, id FROM events WHERE id = $1)
+		ORDER BY timestamp DESC, id DESC LIMIT 1`, id).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		hash, _, err = s.archivedHead(ctx)
+	}
+	return hash, err
+}
+
+func (s *PgStore) archivedHead(ctx context.Context) (string, int, error) {
+	var hash string
+	var n int
+	err := s.pool.QueryRow(ctx, `
+		SELECT coalesce((SELECT hash FROM archived_events ORDER BY timestamp DESC, id DESC LIMIT 1), ''),
+			(SELECT count(*) FROM archived_events)`).Scan(&hash, &n)
+	if err != nil {
+		return "", 0, fmt.Errorf("archived head: %w", err)
+	}
+	return hash, n, nil
+}
+
+func (s *PgStore) scanArchived(ctx context.Context, fn func(id, hash string)) error {
+	rows, err := s.pool.Query(ctx, `SELECT id, hash FROM archived_events ORDER BY timestamp ASC, id ASC`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return err
 		}
-		contentJSON2, _ := json.Marshal(e.Content)
-		expected := computeHash(prevHash, e.ID, e.Type, e.Source, e.ConversationID, e.Timestamp, contentJSON2)
-		if e.Hash != expected {
-			expected2 := computeHash(prevHash, e.ID, e.Type, e.Source, e.ConversationID, e.Timestamp, contentJSON)
-			if e.Hash != expected2 {
-				return fmt.Errorf("event %d (%s): hash mismatch: got %s, want remarshal=%s or raw=%s", i, e.ID, e.Hash, expected, expected2)
-			}
+		fn(id, hash)
+	}
+	return rows.Err()
+}
+
+// Segments lists the archived segments in chain order.
+func (s *PgStore) Segments(ctx context.Context) ([]Segment, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT name, first_id, last_id, first_timestamp, last_timestamp, count,
+			prev_hash, first_hash, last_hash, sha256, archived_at
+		FROM event_segments ORDER BY first_timestamp ASC, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	defer rows.Close()
+	out := []Segment{}
+	for rows.Next() {
+		var seg Segment
+		if err := rows.Scan(&seg.Name, &seg.FirstID, &seg.LastID, &seg.FirstTimestamp, &seg.LastTimestamp, &seg.Count,
+			&seg.PrevHash, &seg.FirstHash, &seg.LastHash, &seg.SHA256, &seg.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("scan segment: %w", err)
 		}
-		prevHash = e.Hash
-		i++
+		out = append(out, seg)
 	}
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("verify chain rows: %w", err)
+	return out, rows.Err()
+}
+
+// archiveSegment takes the append lock, so no append sees half a segment
+// archived.
+func (s *PgStore) archiveSegment(ctx context.Context, seg *Segment) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return fmt.Errorf("lock chain: %w", err)
+	}
+	var firstID, lastHash string
+	var n int
+	var later bool
+	err = tx.QueryRow(ctx, `
+		SELECT coalesce((SELECT id FROM events ORDER BY timestamp ASC, id ASC LIMIT 1), ''),
+			(SELECT count(*) FROM events WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = $1)),
+			coalesce((SELECT hash FROM events WHERE id = $1), ''),
+			EXISTS (SELECT 1 FROM events WHERE (timestamp, id) > (SELECT timestamp, id FROM events WHERE id = $1))`,
+		seg.LastID).Scan(&firstID, &n, &lastHash, &later)
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", seg.Name, err)
+	}
+	if err := seg.checkPrefix(firstID, n, lastHash, later); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO event_segments (name, first_id, last_id, first_timestamp, last_timestamp, count,
+			prev_hash, first_hash, last_hash, sha256, archived_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		seg.Name, seg.FirstID, seg.LastID, seg.FirstTimestamp, seg.LastTimestamp, seg.Count,
+		seg.PrevHash, seg.FirstHash, seg.LastHash, seg.SHA256, seg.ArchivedAt)
+	if err != nil {
+		return fmt.Errorf("archive %s: record segment: %w", seg.Name, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO archived_events (id, timestamp, hash, segment)
+		SELECT id, timestamp, hash, $1 FROM events
+		WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = $2)`, seg.Name, seg.LastID)
+	if err != nil {
+		return fmt.Errorf("archive %s: stub events: %w", seg.Name, err)
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM events WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = $1)`, seg.LastID)
+	if err != nil {
+		return fmt.Errorf("archive %s: delete events: %w", seg.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("archive %s: commit: %w", seg.Name, err)
 	}
 	return nil
 }
 
+func (s *PgStore) restoreSegment(ctx context.Context, seg *Segment, events []StoredEvent) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return fmt.Errorf("lock chain: %w", err)
+	}
+	var newest string
+	err = tx.QueryRow(ctx, `SELECT name FROM event_segments ORDER BY first_timestamp DESC, name DESC LIMIT 1`).Scan(&newest)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("restore %s: %w", seg.Name, err)
+	}
+	if newest != seg.Name {
+		return fmt.Errorf("restore %s: not the newest segment: %w", seg.Name, ErrSegment)
+	}
+
+	batch := pgInsertStored(events)
+	batch.Queue(`DELETE FROM archived_events WHERE segment = $1`, seg.Name)
+	batch.Queue(`DELETE FROM event_segments WHERE name = $1`, seg.Name)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("restore %s: %w", seg.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("restore %s: commit: %w", seg.Name, err)
+	}
+	return nil
+}
+
+func (s *PgStore) scanChain(ctx context.Context, fromID string, inclusive bool, toID string, fn func(e *Event, contentJSON []byte)) error {
+	query := `SELECT ` + pgEventColumns + ` FROM events WHERE true`
+	var args []any
+	if fromID != "" {
+		op := ">"
+		if inclusive {
+			op = ">="
+		}
+		args = append(args, fromID)
+		query += fmt.Sprintf(" AND (timestamp, id) %s (SELECT timestamp, id FROM events WHERE id = $%d)", op, len(args))
+	}
+	if toID != "" {
+		args = append(args, toID)
+		query += fmt.Sprintf(" AND (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = $%d)", len(args))
+	}
+	query += ` ORDER BY timestamp ASC, id ASC`
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Event
+		var contentJSON []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash, &e.HashVersion, &e.Signature); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(contentJSON, &e.Content); err != nil {
+			e.Content = map[string]any{"_raw": string(contentJSON)}
+		}
+		fn(&e, contentJSON)
+	}
+	return rows.Err()
+}
+
 // Ancestors walks up the causes chain recursively.
 func (s *PgStore) Ancestors(ctx context.Context, id string, maxDepth int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		WITH RECURSIVE ancestors AS (
-			SELECT e.id, e.type, e.timestamp, e.source, e.content, e.causes, e.conversation_id, e.hash, e.prev_hash, 1 AS depth
-			FROM events e
-			WHERE e.id = ANY(SELECT unnest(causes) FROM events WHERE id = $1)
+		WITH RECURSIVE ancestors(id, depth) AS (
+			SELECT c.id, 1
+			FROM events e, unnest(e.causes) AS c(id)
+			WHERE e.id = $1
 			UNION
-			SELECT e.id, e.type, e.timestamp, e.source, e.content, e.causes, e.conversation_id, e.hash, e.prev_hash, a.depth + 1
-			FROM events e
-			JOIN ancestors a ON e.id = ANY(SELECT unnest(causes) FROM events WHERE id = a.id)
+			SELECT c.id, a.depth + 1
+			FROM ancestors a
+			JOIN events e ON e.id = a.id, unnest(e.causes) AS c(id)
 			WHERE a.depth < $2
 		)
-		SELECT DISTINCT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
-		FROM ancestors
+		SELECT `+pgEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM ancestors)
 		ORDER BY timestamp ASC, id ASC`, id, maxDepth)
 }
 
 // Descendants finds events that cite the given ID in their causes.
 func (s *PgStore) Descendants(ctx context.Context, id string, maxDepth int) ([]Event, error) {
 	return s.scanMany(ctx, `
-		WITH RECURSIVE descendants AS (
-			SELECT e.id, e.type, e.timestamp, e.source, e.content, e.causes, e.conversation_id, e.hash, e.prev_hash, 1 AS depth
+		WITH RECURSIVE descendants(id, depth) AS (
+			SELECT e.id, 1
 			FROM events e
 			WHERE $1 = ANY(e.causes)
 			UNION
-			SELECT e.id, e.type, e.timestamp, e.source, e.content, e.causes, e.conversation_id, e.hash, e.prev_hash, d.depth + 1
-			FROM events e
-			JOIN descendants d ON d.id = ANY(e.causes)
+			SELECT e.id, d.depth + 1
+			FROM descendants d
+			JOIN events e ON d.id = ANY(e.causes)
 			WHERE d.depth < $2
 		)
-		SELECT DISTINCT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
-		FROM descendants
+		SELECT `+pgEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM descendants)
 		ORDER BY timestamp ASC, id ASC`, id, maxDepth)
 }
 
-// Search performs a text search across type, source, and content fields.
-func (s *PgStore) Search(ctx context.Context, query string, limit int) ([]Event, error) {
-	like := "%" + query + "%"
-	return s.scanMany(ctx, `
-		SELECT id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash
-		FROM events
-		WHERE type ILIKE $1 OR source ILIKE $1 OR content::text ILIKE $1
-		ORDER BY timestamp DESC, id DESC LIMIT $2`, like, limit)
+// PathBetween finds the shortest causes path from from to to. The recursive
+// CTEs collect the events that are both ancestors of to and descendants of
+// from, pruned by timestamp since causes precede their effects; the path
+// through them is found in Go.
+func (s *PgStore) PathBetween(ctx context.Context, from, to string) ([]Event, error) {
+	start, err := s.Get(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+	end, err := s.Get(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+	nodes, err := s.scanMany(ctx, `
+		WITH RECURSIVE
+		up(id) AS (
+			SELECT $2::text
+			UNION
+			SELECT c.id
+			FROM up JOIN events e ON e.id = up.id AND e.timestamp > $3, unnest(e.causes) AS c(id)
+		),
+		down(id) AS (
+			SELECT $1::text
+			UNION
+			SELECT e.id
+			FROM down JOIN events e ON down.id = ANY(e.causes) AND e.timestamp <= $4
+		)
+		SELECT `+pgEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM up INTERSECT SELECT id FROM down)
+		ORDER BY timestamp ASC, id ASC`, from, to, start.Timestamp, end.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("path %s to %s: %w", from, to, err)
+	}
+	path := shortestPath(nodes, from, to)
+	if path == nil {
+		path = []Event{}
+	}
+	return path, nil
+}
+
+// CommonAncestors finds the events every one of ids descends from, walking
+// each one's ancestors in a single recursive CTE tagged by origin.
+func (s *PgStore) CommonAncestors(ctx context.Context, ids ...string) ([]Event, error) {
+	ids, err := commonIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	var found int
+	if err := s.pool.QueryRow(ctx, `SELECT count(*) FROM events WHERE id = ANY($1)`, ids).Scan(&found); err != nil {
+		return nil, fmt.Errorf("common ancestors: %w", err)
+	}
+	if found < len(ids) {
+		return nil, fmt.Errorf("common ancestors: %d of %d events: %w", len(ids)-found, len(ids), ErrNotFound)
+	}
+	events, err := s.scanMany(ctx, `
+		WITH RECURSIVE ancestors(origin, id) AS (
+			SELECT o.id, c.id
+			FROM unnest($1::text[]) AS o(id) JOIN events e ON e.id = o.id, unnest(e.causes) AS c(id)
+			UNION
+			SELECT a.origin, c.id
+			FROM ancestors a JOIN events e ON e.id = a.id, unnest(e.causes) AS c(id)
+		)
+		SELECT `+pgEventColumns+`
+		FROM events WHERE id IN (
+			SELECT id FROM ancestors GROUP BY id HAVING count(DISTINCT origin) = $2
+		)
+		ORDER BY timestamp ASC, id ASC`, ids, len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("common ancestors: %w", err)
+	}
+	if events == nil {
+		events = []Event{}
+	}
+	return events, nil
+}
+
+// AuditCauses reports causes that aren't in the store or don't come before
+// the event citing them.
+func (s *PgStore) AuditCauses(ctx context.Context) ([]CauseProblem, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT e.id, e.type, c.id, CASE WHEN p.id IS NULL THEN $1 ELSE $2 END
+		FROM events e
+		CROSS JOIN LATERAL unnest(e.causes) WITH ORDINALITY AS c(id, n)
+		LEFT JOIN events p ON p.id = c.id
+		LEFT JOIN archived_events a ON a.id = c.id
+		WHERE (p.id IS NULL AND a.id IS NULL) OR (p.timestamp, p.id) >= (e.timestamp, e.id)
+		ORDER BY e.timestamp ASC, e.id ASC, c.n ASC`, CauseDangling, CauseFuture)
+	if err != nil {
+		return nil, fmt.Errorf("audit causes: %w", err)
+	}
+	defer rows.Close()
+	problems := []CauseProblem{}
+	for rows.Next() {
+		var p CauseProblem
+		if err := rows.Scan(&p.EventID, &p.Type, &p.Cause, &p.Problem); err != nil {
+			return nil, fmt.Errorf("audit causes: %w", err)
+		}
+		problems = append(problems, p)
+	}
+	return problems, rows.Err()
+}
+
+// Search ranks events against a full-text query using the search tsvector
+// column (type and source weighted above content string values) and its GIN
+// index. Snippets are ts_headline over the content's string values.
+func (s *PgStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+pgEventColumns+`, ts_rank_cd(search, q) AS rank,
+			ts_headline('english', coalesce((
+				SELECT string_agg(v #>> '{}', ' ')
+				FROM jsonb_path_query(content, 'strict $.**') v
+				WHERE jsonb_typeof(v) = 'string'), ''), q, 'MaxWords=20, MinWords=5')
+		FROM events, to_tsquery('english', $1) q
+		WHERE search @@ q
+		ORDER BY rank DESC, timestamp DESC, id DESC LIMIT $2`, tsquery(terms), limit)
+	if err != nil {
+		return nil, fmt.Errorf("search events: %w", err)
+	}
+	defer rows.Close()
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var rank float32
+		var contentJSON []byte
+		e := &r.Event
+		if err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash, &e.HashVersion, &e.Signature, &rank, &r.Snippet); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		if err := json.Unmarshal(contentJSON, &e.Content); err != nil {
+			return nil, fmt.Errorf("unmarshal content: %w", err)
+		}
+		r.Rank = float64(rank)
+		results = append(results, r)
+	}
+	return results, rows.Err()
 }
 
 // DistinctTypes returns all unique event types.
@@ -302,7 +954,7 @@ func (s *PgStore) scanOne(ctx context.Context, query string, args ...any) (*Even
 	var e Event
 	var contentJSON []byte
 	err := s.pool.QueryRow(ctx, query, args...).
-		Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash)
+		Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash, &e.HashVersion, &e.Signature)
 	if err != nil {
 		return nil, err
 	}
@@ -330,7 +982,7 @@ func scanRows(rows interface {
 	for rows.Next() {
 		var e Event
 		var contentJSON []byte
-		if err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash); err != nil {
+		if err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &e.Source, &contentJSON, &e.Causes, &e.ConversationID, &e.Hash, &e.PrevHash, &e.HashVersion, &e.Signature); err != nil {
 			return nil, err
 		}
 		if err := json.Unmarshal(contentJSON, &e.Content); err != nil {
@@ -343,10 +995,3 @@ func scanRows(rows interface {
 	}
 	return events, nil
 }
-
-// computeHash computes a SHA-256 hash for chain integrity.
-func computeHash(prevHash, id, eventType, source, conversationID string, timestamp time.Time, contentJSON []byte) string {
-	data := fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s", prevHash, id, eventType, source, conversationID, timestamp.UnixNano(), string(contentJSON))
-	h := sha256.Sum256([]byte(data))
-	return fmt.Sprintf("%x", h)
-}
@@ -0,0 +1,138 @@
+package eventgraph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mind-zero-five/internal/db/migrations"
+)
+
+// newTestPool connects to TEST_DATABASE_URL with search_path set to a fresh,
+// throwaway, fully migrated schema. Skips the test when no database is
+// configured.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("eventgraph_test_%d", time.Now().UnixNano())
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		conn, err := pgx.Connect(context.Background(), dsn)
+		if err != nil {
+			return
+		}
+		defer conn.Close(context.Background())
+		conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	})
+	if _, err := migrations.NewPostgres(pool).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return pool
+}
+
+func TestPgStoreConformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T, causes CauseMode) EventStore {
+		return NewPgStore(newTestPool(t), NewDefaultScrubber(), causes)
+	})
+}
+
+func TestPgBusDeliversAcrossProcesses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := newTestPool(t)
+	// A second pool on the same schema stands in for another process.
+	other, err := pgxpool.NewWithConfig(ctx, pool.Config())
+	if err != nil {
+		t.Fatalf("second pool: %v", err)
+	}
+	defer other.Close()
+
+	writer := NewPgStore(pool, nil, CausesStrict)
+	first := mustAppend(t, writer, "test.before", "writer", nil, nil, "")
+
+	bus := NewPgBus(NewPgStore(other, nil, CausesStrict), other)
+	bus.lastID = first.ID
+	ch := bus.Subscribe()
+	defer bus.Unsubscribe(ch)
+	go bus.Listen(ctx)
+
+	want := []*Event{mustAppend(t, writer, "test.one", "writer", nil, nil, "")}
+	batch, err := writer.AppendBatch(ctx, []AppendRequest{{Type: "test.two", Source: "writer"}, {Type: "test.three", Source: "writer"}})
+	if err != nil {
+		t.Fatalf("append batch: %v", err)
+	}
+	want = append(want, batch...)
+
+	for _, w := range want {
+		select {
+		case got := <-ch:
+			if got.ID != w.ID {
+				t.Fatalf("got event %s (%s), want %s (%s)", got.ID, got.Type, w.ID, w.Type)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", w.Type)
+		}
+	}
+}
+
+func TestPgBusRedactDeliversOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := newTestPool(t)
+	bus := NewPgBus(NewPgStore(pool, nil, CausesStrict), pool)
+	ch := bus.Subscribe()
+	defer bus.Unsubscribe(ch)
+	go bus.Listen(ctx)
+
+	e, err := bus.Append(ctx, "test.secret", "writer", map[string]any{"token": "x"}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	audit, err := bus.Redact(ctx, e.ID, RedactRequest{Source: "writer", Reason: "leaked"})
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+
+	deliveries := map[string]int{}
+	for timeout := time.After(2 * time.Second); ; {
+		select {
+		case got := <-ch:
+			deliveries[got.ID]++
+			continue
+		case <-timeout:
+		}
+		break
+	}
+	if deliveries[e.ID] != 1 || deliveries[audit.ID] != 1 {
+		t.Errorf("deliveries = %v, want each of %s and %s once", deliveries, e.ID, audit.ID)
+	}
+}
@@ -0,0 +1,279 @@
+package eventgraph
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mind-zero-five/pkg/page"
+)
+
+// ErrInvalidQuery is returned (wrapped) for a Query that can't be run.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// defaultQueryLimit applies when Query.Limit is unset.
+const defaultQueryLimit = 50
+
+// Query selects events by several conditions at once. Within Types, Sources
+// and Conversations any entry may match (OR); every field that is set must
+// match (AND). The zero Query returns the first page of newest events, like
+// Recent.
+type Query struct {
+	// Types may end in ".*" to match every type under a prefix, e.g.
+	// "mind.*" matches "mind.thought" and "mind.claude.failed".
+	Types         []string
+	Sources       []string
+	Conversations []string
+	Since         time.Time // inclusive; zero means unbounded
+	Until         time.Time // exclusive; zero means unbounded
+	Content       []ContentMatch
+	// Cursor is a Next or Prev cursor from a previous page of the same
+	// query; "" starts at the beginning.
+	Cursor    string
+	Ascending bool // chain order; default is newest first
+	Limit     int
+}
+
+// ContentMatch compares one field of an event's content. Path names a field
+// inside content, with dots for nesting ("task_id", "result.status"). The
+// field's value is compared as text: strings as-is, numbers and booleans as
+// their JSON form. A missing or null field never equals anything and always
+// differs from everything.
+type ContentMatch struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	Not   bool   `json:"not,omitempty"` // match when the field differs
+}
+
+// ParseContentMatch parses "path=value" or "path!=value", as used in query
+// strings and flags.
+func ParseContentMatch(s string) (ContentMatch, error) {
+	if i := strings.Index(s, "!="); i > 0 {
+		return ContentMatch{Path: s[:i], Value: s[i+2:], Not: true}, nil
+	}
+	if i := strings.Index(s, "="); i > 0 {
+		return ContentMatch{Path: s[:i], Value: s[i+1:]}, nil
+	}
+	return ContentMatch{}, fmt.Errorf("content match %q: want path=value or path!=value: %w", s, ErrInvalidQuery)
+}
+
+// ParseQueryTime parses a query time bound: an RFC 3339 timestamp, or a
+// duration such as "90m" meaning that long ago.
+func ParseQueryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("time %q: want RFC 3339 or a duration ago: %w", s, ErrInvalidQuery)
+}
+
+func (c ContentMatch) keys() []string {
+	return strings.Split(c.Path, ".")
+}
+
+func (q *Query) validate() error {
+	for _, c := range q.Content {
+		for _, k := range c.keys() {
+			if k == "" || strings.ContainsAny(k, `"\`) {
+				return fmt.Errorf("content path %q: %w", c.Path, ErrInvalidQuery)
+			}
+		}
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Since.Before(q.Until) {
+		return fmt.Errorf("since must be before until: %w", ErrInvalidQuery)
+	}
+	if _, err := page.Parse(q.Cursor); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	return nil
+}
+
+// eventKey is an event's position in chain order, for cursors.
+func eventKey(e *Event) (time.Time, string) {
+	return e.Timestamp, e.ID
+}
+
+func (q *Query) limit() int {
+	if q.Limit <= 0 {
+		return defaultQueryLimit
+	}
+	return q.Limit
+}
+
+// typePrefix reports whether pattern is a wildcard and returns its prefix,
+// dot included: "mind.*" gives "mind.". A lone "*" matches every type.
+func typePrefix(pattern string) (string, bool) {
+	if pattern == "*" {
+		return "", true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.TrimSuffix(pattern, "*"), true
+	}
+	return "", false
+}
+
+// match reports whether e satisfies everything but the cursor.
+func (q *Query) match(e *Event) bool {
+	if len(q.Types) > 0 && !anyOf(q.Types, func(t string) bool {
+		if prefix, ok := typePrefix(t); ok {
+			return strings.HasPrefix(e.Type, prefix)
+		}
+		return e.Type == t
+	}) {
+		return false
+	}
+	if len(q.Sources) > 0 && !anyOf(q.Sources, func(s string) bool { return e.Source == s }) {
+		return false
+	}
+	if len(q.Conversations) > 0 && !anyOf(q.Conversations, func(c string) bool { return e.ConversationID == c }) {
+		return false
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Timestamp.Before(q.Until) {
+		return false
+	}
+	for _, c := range q.Content {
+		v, ok := contentText(e.Content, c.keys())
+		if (ok && v == c.Value) == c.Not {
+			return false
+		}
+	}
+	return true
+}
+
+func anyOf(values []string, fn func(string) bool) bool {
+	for _, v := range values {
+		if fn(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// contentText renders the scalar at keys the way Postgres' #>> operator
+// does. ok is false if the field is missing, null, or not a scalar.
+func contentText(content map[string]any, keys []string) (string, bool) {
+	var v any = content
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if v, ok = m[k]; !ok {
+			return "", false
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// sqlQuery renders a Query for PgStore or SQLiteStore. The two differ only
+// in placeholders, how timestamps are stored, and how content is reached.
+type sqlQuery struct {
+	sqlite bool
+	args   []any
+	where  []string
+}
+
+func (b *sqlQuery) arg(v any) string {
+	b.args = append(b.args, v)
+	if b.sqlite {
+		return "?" + strconv.Itoa(len(b.args))
+	}
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *sqlQuery) timeArg(t time.Time) string {
+	if b.sqlite {
+		return b.arg(t.UnixMicro())
+	}
+	return b.arg(t)
+}
+
+// anyOf adds "(cond OR cond ...)" with one condition per value.
+func (b *sqlQuery) anyOf(values []string, cond func(v string) string) {
+	if len(values) == 0 {
+		return
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = cond(v)
+	}
+	b.where = append(b.where, "("+strings.Join(parts, " OR ")+")")
+}
+
+// contentText is the SQL for the text of a scalar content field, matching
+// contentText above.
+func (b *sqlQuery) contentText(keys []string) string {
+	if !b.sqlite {
+		p := b.arg(keys) + "::text[]"
+		return "(CASE WHEN jsonb_typeof(content #> " + p + ") IN ('object', 'array') THEN NULL" +
+			" ELSE content #>> " + p + " END)"
+	}
+	path := `$."` + strings.Join(keys, `"."`) + `"`
+	p := b.arg(path)
+	// json_extract returns SQL values: JSON booleans come back as 0/1, so
+	// render them as Postgres would.
+	return "(CASE json_type(content, " + p + ")" +
+		" WHEN 'true' THEN 'true' WHEN 'false' THEN 'false'" +
+		" WHEN 'object' THEN NULL WHEN 'array' THEN NULL" +
+		" ELSE CAST(json_extract(content, " + p + ") AS TEXT) END)"
+}
+
+// build returns the statement for q, selecting columns from events. It reads
+// one row more than the limit, as page.Build expects.
+func (b *sqlQuery) build(q Query, c page.Cursor, columns string) string {
+	b.anyOf(q.Types, func(t string) string {
+		if prefix, ok := typePrefix(t); ok {
+			if prefix == "" {
+				return "TRUE"
+			}
+			return fmt.Sprintf("substr(type, 1, %d) = %s", utf8.RuneCountInString(prefix), b.arg(prefix))
+		}
+		return "type = " + b.arg(t)
+	})
+	b.anyOf(q.Sources, func(s string) string { return "source = " + b.arg(s) })
+	b.anyOf(q.Conversations, func(c string) string { return "conversation_id = " + b.arg(c) })
+	if !q.Since.IsZero() {
+		b.where = append(b.where, "timestamp >= "+b.timeArg(q.Since))
+	}
+	if !q.Until.IsZero() {
+		b.where = append(b.where, "timestamp < "+b.timeArg(q.Until))
+	}
+	for _, c := range q.Content {
+		field := b.contentText(c.keys())
+		switch {
+		case c.Not && b.sqlite:
+			b.where = append(b.where, fmt.Sprintf("%s IS NOT %s", field, b.arg(c.Value)))
+		case c.Not:
+			b.where = append(b.where, fmt.Sprintf("%s IS DISTINCT FROM %s", field, b.arg(c.Value)))
+		default:
+			b.where = append(b.where, fmt.Sprintf("%s = %s", field, b.arg(c.Value)))
+		}
+	}
+
+	cmp, order := c.Keyset(!q.Ascending)
+	if !c.IsZero() {
+		b.where = append(b.where, fmt.Sprintf("(timestamp, id) %s (%s, %s)", cmp, b.timeArg(c.Time), b.arg(c.ID)))
+	}
+
+	stmt := "SELECT " + columns + " FROM events"
+	if len(b.where) > 0 {
+		stmt += " WHERE " + strings.Join(b.where, " AND ")
+	}
+	return stmt + fmt.Sprintf(" ORDER BY timestamp %s, id %s LIMIT %s", order, order, b.arg(q.limit()+1))
+}
@@ -0,0 +1,113 @@
+package eventgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RedactedKey is the only content field of a redacted event. It holds the
+// tombstone: the content_sha256 commitment a v2 hash covers in place
+// of the content, and the ID of the event.redacted event that recorded the
+// redaction. Append refuses content using it.
+const RedactedKey = "_redacted"
+
+// EventRedacted is the type of the event Redact appends to record a
+// redaction. It cites the redacted event as its cause.
+const EventRedacted = "event.redacted"
+
+// ErrNotRedactable is returned (wrapped) by Redact for an event that is
+// already redacted, or whose hash format covers the content itself.
+var ErrNotRedactable = errors.New("event can't be redacted")
+
+// RedactRequest says who is redacting an event and why.
+type RedactRequest struct {
+	// Source appends the event.redacted event, signed by Signer.
+	Source string
+	Signer Signer
+	Reason string
+	// RequestedBy is who asked for the redaction, if Source is recording it
+	// on their behalf, and ApprovalID the authority request approving it.
+	RequestedBy string
+	ApprovalID  string
+}
+
+// Redacted reports whether e's content has been replaced by a tombstone.
+func (e *Event) Redacted() bool {
+	return e.Content[RedactedKey] != nil
+}
+
+// tombstoneCommitment returns the content commitment kept by a redacted
+// event's content.
+func tombstoneCommitment(content map[string]any) (string, error) {
+	t, _ := content[RedactedKey].(map[string]any)
+	c, _ := t["content_sha256"].(string)
+	if len(c) != 64 || len(content) != 1 {
+		return "", fmt.Errorf("malformed %s tombstone", RedactedKey)
+	}
+	return c, nil
+}
+
+// redaction is a checked redaction: the commitment to keep and the event to
+// record it with.
+type redaction struct {
+	commitment string
+	audit      AppendRequest
+}
+
+// prepareRedaction checks that e, whose content is stored as contentJSON, can
+// be redacted, and builds the event.redacted request that records it.
+func prepareRedaction(e *Event, contentJSON []byte, req RedactRequest) (*redaction, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("redact %s: a reason is required", e.ID)
+	}
+	if e.Redacted() {
+		return nil, fmt.Errorf("redact %s: already redacted: %w", e.ID, ErrNotRedactable)
+	}
+	if e.HashVersion < HashV2 {
+		return nil, fmt.Errorf("redact %s: its v%d hash covers the content itself: %w", e.ID, e.HashVersion, ErrNotRedactable)
+	}
+	commitment, err := contentCommitment(contentJSON)
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", e.ID, err)
+	}
+	content := map[string]any{
+		"event_id":       e.ID,
+		"event_type":     e.Type,
+		"reason":         req.Reason,
+		"content_sha256": commitment,
+		"requested_by":   req.Source,
+	}
+	if req.RequestedBy != "" {
+		content["requested_by"] = req.RequestedBy
+	}
+	if req.ApprovalID != "" {
+		content["approval_id"] = req.ApprovalID
+	}
+	return &redaction{
+		commitment: commitment,
+		audit: AppendRequest{
+			Type:           EventRedacted,
+			Source:         req.Source,
+			Content:        content,
+			Causes:         []string{e.ID},
+			ConversationID: e.ConversationID,
+			Signer:         req.Signer,
+		},
+	}, nil
+}
+
+// tombstone returns the content that replaces the redacted event's, as stored
+// and as read back.
+func (r *redaction) tombstone(auditID string) ([]byte, map[string]any, error) {
+	content := map[string]any{RedactedKey: map[string]any{
+		"content_sha256": r.commitment,
+		"redaction":      auditID,
+	}}
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal tombstone: %w", err)
+	}
+	return contentJSON, content, nil
+}
@@ -0,0 +1,238 @@
+package eventgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrSchema is returned (wrapped) by Append when content doesn't conform to
+// the schema of a strict event type.
+var ErrSchema = errors.New("content does not match event type schema")
+
+// SchemaErrorsKey is the content field Append adds to events of a lenient
+// type whose content doesn't conform, listing what is wrong with it. It is
+// added before the event is hashed, so it is part of the record.
+const SchemaErrorsKey = "_schema_errors"
+
+// EventType declares the shape of one event type's content. Types that
+// aren't registered accept any content.
+type EventType struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Schema is a JSON Schema for content; see schema for the keywords
+	// supported.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// Strict types reject non-conforming content with ErrSchema. Lenient
+	// types store it, flagged with SchemaErrorsKey: losing an event is
+	// usually worse than keeping a malformed one.
+	Strict bool `json:"strict,omitempty"`
+
+	schema *schema
+}
+
+// Check returns what is wrong with content, or nil if it conforms.
+func (t *EventType) Check(content map[string]any) ([]string, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("marshal content: %w", err)
+	}
+	return t.check(raw)
+}
+
+func (t *EventType) check(contentJSON []byte) ([]string, error) {
+	if t.schema == nil {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(contentJSON, &v); err != nil {
+		return nil, fmt.Errorf("unmarshal content: %w", err)
+	}
+	return t.schema.validate(v, "", nil), nil
+}
+
+// Registry holds the declared event types. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]*EventType
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]*EventType)}
+}
+
+// DefaultRegistry is the registry Append checks content against. It starts
+// with the types this module emits.
+var DefaultRegistry = newDefaultRegistry()
+
+// Register declares t, replacing any earlier declaration of the same type at
+// the same or a lower version.
+func (r *Registry) Register(t EventType) error {
+	if t.Name == "" {
+		return errors.New("register event type: name is required")
+	}
+	if t.Version < 1 {
+		return fmt.Errorf("register event type %s: version must be at least 1", t.Name)
+	}
+	s, err := parseSchema(t.Schema)
+	if err != nil {
+		return fmt.Errorf("register event type %s: schema: %w", t.Name, err)
+	}
+	t.schema = s
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.types[t.Name]; ok && old.Version > t.Version {
+		return fmt.Errorf("register event type %s: version %d is older than registered version %d", t.Name, t.Version, old.Version)
+	}
+	r.types[t.Name] = &t
+	return nil
+}
+
+// Lookup returns the declaration of a type.
+func (r *Registry) Lookup(name string) (EventType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[name]
+	if !ok {
+		return EventType{}, false
+	}
+	return *t, true
+}
+
+// Types returns every registered type, ordered by name.
+func (r *Registry) Types() []EventType {
+	return r.Describe(nil)
+}
+
+// Describe returns every registered type plus the names in seen that aren't
+// registered, ordered by name. Unregistered types have only a Name.
+func (r *Registry) Describe(seen []string) []EventType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]EventType, 0, len(r.types)+len(seen))
+	for _, t := range r.types {
+		out = append(out, *t)
+	}
+	for _, name := range seen {
+		if _, ok := r.types[name]; !ok {
+			out = append(out, EventType{Name: name})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// conform checks p's content against its type in r. Non-conforming content
+// is refused for strict types and flagged for lenient ones.
+func (r *Registry) conform(p *pendingEvent) error {
+	t, ok := r.Lookup(p.req.Type)
+	if !ok {
+		return nil
+	}
+	problems, err := t.check(p.contentJSON)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if t.Strict {
+		return fmt.Errorf("%s v%d: %s: %w", t.Name, t.Version, strings.Join(problems, "; "), ErrSchema)
+	}
+	content := maps.Clone(p.req.Content)
+	content[SchemaErrorsKey] = problems
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("marshal content: %w", err)
+	}
+	p.req.Content, p.contentJSON = content, contentJSON
+	return nil
+}
+
+// builtinTypes are the types emitted by the mind and the API. They are
+// lenient: the mind must never lose a record of what it did because a
+// field changed shape.
+var builtinTypes = []EventType{
+	{Name: "task.created", Description: "A task was created.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id", "subject"],
+		"properties": {"task_id": {"type": "string"}, "subject": {"type": "string"}, "source": {"type": "string"}}}`)},
+	{Name: "task.claimed", Description: "The mind started working on a task.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "subject": {"type": "string"}}}`)},
+	{Name: "task.completed", Description: "A task finished successfully.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "subject": {"type": "string"}}}`)},
+	{Name: "task.blocked", Description: "A task can't proceed without help.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id", "reason"],
+		"properties": {"task_id": {"type": "string"}, "reason": {"type": "string"}}}`)},
+	{Name: "task.retried", Description: "A blocked task was put back in the queue.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "subject": {"type": "string"}, "retry_count": {"type": "integer", "minimum": 1}}}`)},
+	{Name: "task.recovered", Description: "A failed task was requeued after recovery.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "subject": {"type": "string"}}}`)},
+	{Name: "authority.requested", Description: "Approval was requested for an action.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id", "action"],
+		"properties": {"authority_id": {"type": "string"}, "action": {"type": "string"}, "task_id": {"type": "string"}, "subject": {"type": "string"}}}`)},
+	{Name: "authority.resolved", Description: "A human approved or rejected a request.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id", "approved"],
+		"properties": {"authority_id": {"type": "string"}, "action": {"type": "string"}, "approved": {"type": "boolean"}}}`)},
+	{Name: "authority.auto_approved", Description: "A request was approved when its approval window ran out.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id"],
+		"properties": {"authority_id": {"type": "string"}, "action": {"type": "string"}, "timeout": {"type": "string"}}}`)},
+	{Name: "authority.self_approved", Description: "A request was approved by policy.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id", "policy_id"],
+		"properties": {"authority_id": {"type": "string"}, "policy_id": {"type": "string"}, "action": {"type": "string"}}}`)},
+	{Name: "mind.claude.invoked", Description: "The mind invoked Claude on a task.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "prompt": {"type": "string"}, "model": {"type": "string"}, "mode": {"type": "string"}}}`)},
+	{Name: "mind.claude.completed", Description: "A Claude invocation finished.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}, "exit_code": {"type": "integer"}, "duration": {"type": "string"}, "result": {"type": "string"}}}`)},
+	{Name: "mind.claude.failed", Description: "A Claude invocation couldn't run.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id", "error"],
+		"properties": {"task_id": {"type": "string"}, "error": {"type": "string"}}}`)},
+	{Name: "mind.error", Description: "An operation in the mind failed.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["operation", "error"],
+		"properties": {"operation": {"type": "string"}, "error": {"type": "string"}, "task_id": {"type": "string"}, "authority_id": {"type": "string"}}}`)},
+	{Name: "mind.panic", Description: "The mind recovered from a panic.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["error"],
+		"properties": {"error": {"type": "string"}}}`)},
+	{Name: "build.failed", Description: "Building or testing a task's changes failed.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id", "error"],
+		"properties": {"task_id": {"type": "string"}, "error": {"type": "string"}}}`)},
+	{Name: "build.completed", Description: "Deployment binaries were built.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id"],
+		"properties": {"task_id": {"type": "string"}}}`)},
+	{Name: "code.committed", Description: "A task's changes were committed.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["task_id", "message"],
+		"properties": {"task_id": {"type": "string"}, "message": {"type": "string"}}}`)},
+	{Name: EventRedacted, Description: "An event's content was replaced by a tombstone.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["event_id", "reason", "content_sha256", "requested_by"],
+		"properties": {"event_id": {"type": "string"}, "event_type": {"type": "string"}, "reason": {"type": "string"}, "content_sha256": {"type": "string"},
+			"requested_by": {"type": "string"}, "approval_id": {"type": "string"}}}`)},
+	{Name: "deploy.started", Description: "The mind is restarting onto new binaries.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id"],
+		"properties": {"authority_id": {"type": "string"}}}`)},
+	{Name: "deploy.failed", Description: "Restarting onto new binaries failed.", Schema: json.RawMessage(`{
+		"type": "object", "required": ["authority_id", "error"],
+		"properties": {"authority_id": {"type": "string"}, "error": {"type": "string"}}}`)},
+}
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, t := range builtinTypes {
+		t.Version = 1
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
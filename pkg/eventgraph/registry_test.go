@@ -0,0 +1,143 @@
+package eventgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	r := NewRegistry()
+	err := r.Register(EventType{Name: "test.schema", Version: 1, Schema: json.RawMessage(`{
+		"type": "object",
+		"required": ["id", "count"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "minLength": 1},
+			"count": {"type": "integer", "minimum": 0},
+			"state": {"enum": ["open", "closed"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"meta": {"type": "object", "properties": {"ok": {"type": ["boolean", "null"]}}}
+		}}`)})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	typ, _ := r.Lookup("test.schema")
+
+	cases := []struct {
+		content map[string]any
+		want    []string
+	}{
+		{map[string]any{"id": "a", "count": 3, "state": "open", "tags": []string{"x"}, "meta": map[string]any{"ok": nil}}, nil},
+		{map[string]any{"count": 1.5}, []string{"id: required", "count: want integer, got number"}},
+		{map[string]any{"id": "", "count": -1}, []string{"count: less than 0", "id: shorter than 1 characters"}},
+		{map[string]any{"id": "a", "count": 0, "state": "gone", "extra": true}, []string{"extra: not allowed", "state: not one of the allowed values"}},
+		{map[string]any{"id": "a", "count": 0, "tags": []any{"x", 2}, "meta": map[string]any{"ok": "yes"}}, []string{"meta.ok: want boolean or null, got string", "tags[1]: want string, got number"}},
+	}
+	for _, c := range cases {
+		got, err := typ.Check(c.content)
+		if err != nil {
+			t.Fatalf("check %v: %v", c.content, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("check %v = %q, want %q", c.content, got, c.want)
+		}
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	for _, bad := range []EventType{
+		{Name: "", Version: 1, Schema: json.RawMessage(`{}`)},
+		{Name: "test.v0", Schema: json.RawMessage(`{}`)},
+		{Name: "test.keyword", Version: 1, Schema: json.RawMessage(`{"pattern": "^a"}`)},
+		{Name: "test.type", Version: 1, Schema: json.RawMessage(`{"type": "map"}`)},
+	} {
+		if err := r.Register(bad); err == nil {
+			t.Errorf("register %+v: expected an error", bad)
+		}
+	}
+
+	if err := r.Register(EventType{Name: "test.versioned", Version: 2, Schema: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("register v2: %v", err)
+	}
+	if err := r.Register(EventType{Name: "test.versioned", Version: 1, Schema: json.RawMessage(`{}`)}); err == nil {
+		t.Error("expected registering an older version to fail")
+	}
+	if err := r.Register(EventType{Name: "test.versioned", Version: 3, Schema: json.RawMessage(`{"type": "object"}`)}); err != nil {
+		t.Fatalf("register v3: %v", err)
+	}
+	if typ, _ := r.Lookup("test.versioned"); typ.Version != 3 {
+		t.Errorf("version = %d, want 3", typ.Version)
+	}
+
+	types := r.Describe([]string{"test.unregistered", "test.versioned", "a.first"})
+	var names []string
+	for _, typ := range types {
+		names = append(names, typ.Name)
+	}
+	if want := []string{"a.first", "test.unregistered", "test.versioned"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("describe = %v, want %v", names, want)
+	}
+	if types[0].Version != 0 || types[0].Schema != nil {
+		t.Errorf("unregistered type described as %+v", types[0])
+	}
+}
+
+func TestAppendChecksRegisteredTypes(t *testing.T) {
+	ctx := context.Background()
+	object := json.RawMessage(`{"type": "object", "required": ["n"], "properties": {"n": {"type": "integer"}}}`)
+	for _, typ := range []EventType{
+		{Name: "test.registry.strict", Version: 1, Schema: object, Strict: true},
+		{Name: "test.registry.lenient", Version: 1, Schema: object},
+	} {
+		if err := DefaultRegistry.Register(typ); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	s := NewMemStore(nil, CausesStrict)
+
+	if _, err := s.Append(ctx, "test.registry.strict", "tester", map[string]any{"n": "one"}, nil, "", nil); !errors.Is(err, ErrSchema) {
+		t.Fatalf("strict append err = %v, want ErrSchema", err)
+	}
+	_, err := s.AppendBatch(ctx, []AppendRequest{
+		{Type: "test.registry.strict", Source: "tester", Content: map[string]any{"n": 1}},
+		{Type: "test.registry.strict", Source: "tester"},
+	})
+	if !errors.Is(err, ErrSchema) {
+		t.Fatalf("batch err = %v, want ErrSchema", err)
+	}
+	if n, _ := s.Count(ctx); n != 0 {
+		t.Fatalf("rejected appends stored %d events", n)
+	}
+
+	ok := mustAppend(t, s, "test.registry.strict", "tester", map[string]any{"n": 1}, nil, "")
+	if _, flagged := ok.Content[SchemaErrorsKey]; flagged {
+		t.Errorf("conforming event flagged: %v", ok.Content)
+	}
+
+	content := map[string]any{"n": 1.5}
+	e := mustAppend(t, s, "test.registry.lenient", "tester", content, nil, "")
+	if got, want := e.Content[SchemaErrorsKey], []any{"n: want integer, got number"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", SchemaErrorsKey, got, want)
+	}
+	if _, ok := content[SchemaErrorsKey]; ok {
+		t.Error("append modified the caller's content")
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Errorf("verify after flagged append: %v", err)
+	}
+
+	// Only the registry may flag schema errors, so a conforming event can't
+	// be made to look flagged, nor a flagged one cleared.
+	for _, typ := range []string{"test.registry.lenient", "test.registry.unregistered"} {
+		_, err := s.Append(ctx, typ, "tester", map[string]any{"n": 1, SchemaErrorsKey: []string{}}, nil, "", nil)
+		if !errors.Is(err, ErrSchema) {
+			t.Errorf("%s append with %s: err = %v, want ErrSchema", typ, SchemaErrorsKey, err)
+		}
+	}
+
+	mustAppend(t, s, "test.registry.unregistered", "tester", map[string]any{"anything": true}, nil, "")
+}
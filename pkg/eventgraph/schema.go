@@ -0,0 +1,196 @@
+package eventgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// schema is the subset of JSON Schema that event types can declare: type,
+// enum, properties, required, additionalProperties, items, minLength,
+// minimum and maximum, plus annotations that don't affect validation. Other
+// keywords are refused when a type is registered rather than ignored, so a
+// schema never silently accepts more than it says.
+type schema struct {
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	never bool // the schema false: nothing is valid
+}
+
+// isSchemaKeyword reports whether a schema may use keyword k.
+func isSchemaKeyword(k string) bool {
+	switch k {
+	case "type", "enum", "properties", "required", "additionalProperties",
+		"items", "minLength", "minimum", "maximum":
+		return true
+	case "$schema", "$id", "title", "description", "default", "examples", "format":
+		return true // annotations
+	}
+	return false
+}
+
+// UnmarshalJSON accepts a schema object or the boolean schemas true and
+// false.
+func (s *schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		*s = schema{}
+		return nil
+	case "false":
+		*s = schema{never: true}
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for k := range raw {
+		if !isSchemaKeyword(k) {
+			return fmt.Errorf("unsupported keyword %q", k)
+		}
+	}
+	type plain schema
+	return json.Unmarshal(b, (*plain)(s))
+}
+
+// schemaTypes is the type keyword: one type name or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaTypes{one}
+	} else if err := json.Unmarshal(b, (*[]string)(t)); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	for _, name := range *t {
+		switch name {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return fmt.Errorf("unknown type %q", name)
+		}
+	}
+	return nil
+}
+
+// parseSchema decodes a schema declared by an event type.
+func parseSchema(raw json.RawMessage) (*schema, error) {
+	var s schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// jsonType names the JSON type of a value decoded by encoding/json.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func (s *schema) hasType(v any) bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+	got := jsonType(v)
+	for _, want := range s.Type {
+		if want == got {
+			return true
+		}
+		if n, ok := v.(float64); ok && want == "integer" && n == math.Trunc(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// validate appends a description of everything wrong with v to problems.
+// path locates v inside content, in the dotted form ContentMatch uses.
+func (s *schema) validate(v any, path string, problems []string) []string {
+	where := path
+	if where == "" {
+		where = "content"
+	}
+	if s.never {
+		return append(problems, where+": not allowed")
+	}
+	if !s.hasType(v) {
+		return append(problems, fmt.Sprintf("%s: want %s, got %s", where, strings.Join(s.Type, " or "), jsonType(v)))
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		problems = append(problems, where+": not one of the allowed values")
+	}
+
+	switch v := v.(type) {
+	case string:
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s: shorter than %d characters", where, *s.MinLength))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s: less than %v", where, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s: greater than %v", where, *s.Maximum))
+		}
+	case []any:
+		if s.Items != nil {
+			for i, x := range v {
+				problems = s.Items.validate(x, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case map[string]any:
+		for _, k := range s.Required {
+			if _, ok := v[k]; !ok {
+				problems = append(problems, joinPath(path, k)+": required")
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				problems = p.validate(v[k], joinPath(path, k), problems)
+			} else if s.AdditionalProperties != nil {
+				problems = s.AdditionalProperties.validate(v[k], joinPath(path, k), problems)
+			}
+		}
+	}
+	return problems
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,282 @@
+package eventgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ScrubbedFieldsKey is the content field Append adds to events whose content
+// had secrets masked by the store's Scrubber, listing the paths of the
+// masked values. Like SchemaErrorsKey it is added before the event is
+// hashed; like RedactedKey, callers can't set it.
+const ScrubbedFieldsKey = "_scrubbed_fields"
+
+// ScrubRule masks one kind of secret in content string values.
+type ScrubRule struct {
+	Name string `json:"name"`
+	// Pattern is an RE2 regular expression. Each match is replaced by
+	// "[SCRUBBED:<name>]", or only its "secret" group if it has one, so
+	// the context around a secret survives.
+	Pattern string `json:"pattern"`
+
+	re     *regexp.Regexp
+	secret int // index of the "secret" group, or 0 for the whole match
+}
+
+// Scrubber masks secrets in event content before it is stored. Each store
+// is given one when it is created; a nil Scrubber masks nothing. It is safe
+// for concurrent use.
+type Scrubber struct {
+	mu    sync.RWMutex
+	rules []ScrubRule
+}
+
+// NewScrubber creates a Scrubber with no rules.
+func NewScrubber() *Scrubber {
+	return &Scrubber{}
+}
+
+// Add compiles r and adds it, replacing any rule of the same name in place.
+// Rules run in the order they were first added.
+func (s *Scrubber) Add(r ScrubRule) error {
+	r, err := compileRule(r)
+	if err != nil {
+		return fmt.Errorf("add scrub rule %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(r)
+	return nil
+}
+
+// compileRule checks r and compiles its pattern.
+func compileRule(r ScrubRule) (ScrubRule, error) {
+	if r.Name == "" {
+		return r, fmt.Errorf("with pattern %q: name is required", r.Pattern)
+	}
+	if r.Pattern == "" {
+		return r, fmt.Errorf("%s: pattern is required", r.Name)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return r, fmt.Errorf("%s: %w", r.Name, err)
+	}
+	r.re, r.secret = re, max(re.SubexpIndex("secret"), 0)
+	return r, nil
+}
+
+// put adds compiled rule r or replaces the one of its name. Caller holds mu.
+func (s *Scrubber) put(r ScrubRule) {
+	for i := range s.rules {
+		if s.rules[i].Name == r.Name {
+			s.rules[i] = r
+			return
+		}
+	}
+	s.rules = append(s.rules, r)
+}
+
+// Remove drops the rule called name, reporting whether there was one.
+func (s *Scrubber) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(name)
+}
+
+// remove is Remove for a caller holding mu.
+func (s *Scrubber) remove(name string) bool {
+	for i := range s.rules {
+		if s.rules[i].Name == name {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Rules returns the rules in the order they run.
+func (s *Scrubber) Rules() []ScrubRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ScrubRule(nil), s.rules...)
+}
+
+// Load reads a JSON array of rules and applies them in order: each replaces
+// the rule of its name, or is added, and one with no pattern removes it.
+// Nothing is applied if any rule is invalid, and String sees either none of
+// the changes or all of them.
+func (s *Scrubber) Load(r io.Reader) error {
+	var rules []ScrubRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return fmt.Errorf("load scrub rules: %w", err)
+	}
+	for i, rule := range rules {
+		if rule.Pattern == "" && rule.Name == "" {
+			return fmt.Errorf("load scrub rules: rule %d has neither a name nor a pattern", i)
+		}
+		if rule.Pattern == "" {
+			continue
+		}
+		var err error
+		if rules[i], err = compileRule(rule); err != nil {
+			return fmt.Errorf("load scrub rule %w", err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rule := range rules {
+		if rule.re == nil {
+			s.remove(rule.Name)
+		} else {
+			s.put(rule)
+		}
+	}
+	return nil
+}
+
+// String masks every secret in str, reporting whether there were any.
+func (s *Scrubber) String(str string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	masked := false
+	for _, r := range s.rules {
+		var b strings.Builder
+		last := 0
+		for _, m := range r.re.FindAllStringSubmatchIndex(str, -1) {
+			start, end := m[2*r.secret], m[2*r.secret+1]
+			if start < 0 || start == end {
+				continue
+			}
+			b.WriteString(str[last:start])
+			b.WriteString("[SCRUBBED:" + r.Name + "]")
+			last = end
+		}
+		if last > 0 {
+			b.WriteString(str[last:])
+			str, masked = b.String(), true
+		}
+	}
+	return str, masked
+}
+
+// Scrub masks the secrets in content's string values, returning the
+// scrubbed content and the paths of the values it changed, in the dotted
+// form ContentMatch uses. content is returned as is if nothing matched.
+func (s *Scrubber) Scrub(content map[string]any) (map[string]any, []string) {
+	out, paths := s.scrubValue(content, "", nil)
+	if len(paths) == 0 {
+		return content, nil
+	}
+	return out.(map[string]any), paths
+}
+
+func (s *Scrubber) scrubValue(v any, path string, paths []string) (any, []string) {
+	switch v := v.(type) {
+	case string:
+		if masked, ok := s.String(v); ok {
+			return masked, append(paths, path)
+		}
+	case map[string]any:
+		var out map[string]any
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			n := len(paths)
+			var x any
+			if x, paths = s.scrubValue(v[k], joinPath(path, k), paths); len(paths) > n {
+				if out == nil {
+					out = maps.Clone(v)
+				}
+				out[k] = x
+			}
+		}
+		if out != nil {
+			return out, paths
+		}
+	case []any:
+		var out []any
+		for i := range v {
+			n := len(paths)
+			var x any
+			if x, paths = s.scrubValue(v[i], fmt.Sprintf("%s[%d]", path, i), paths); len(paths) > n {
+				if out == nil {
+					out = append([]any(nil), v...)
+				}
+				out[i] = x
+			}
+		}
+		if out != nil {
+			return out, paths
+		}
+	}
+	return v, paths
+}
+
+// scrub masks secrets in p's content and returns the paths it masked, for
+// markScrubbed. Content is scrubbed as it will be stored, in its JSON form,
+// so values of any Go type are covered. Numbers are decoded as json.Number
+// so that they are written back exactly.
+func (s *Scrubber) scrub(p *pendingEvent) ([]string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(p.contentJSON))
+	d.UseNumber()
+	var stored map[string]any
+	if err := d.Decode(&stored); err != nil {
+		return nil, fmt.Errorf("unmarshal content: %w", err)
+	}
+	content, paths := s.Scrub(stored)
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("marshal content: %w", err)
+	}
+	p.req.Content, p.contentJSON = content, contentJSON
+	return paths, nil
+}
+
+// markScrubbed lists the paths scrub masked in p's content.
+func markScrubbed(p *pendingEvent, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	content := maps.Clone(p.req.Content)
+	content[ScrubbedFieldsKey] = paths
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("marshal content: %w", err)
+	}
+	p.req.Content, p.contentJSON = content, contentJSON
+	return nil
+}
+
+// defaultScrubRules catch the credentials most likely to end up in prompts,
+// command output and git errors.
+var defaultScrubRules = []ScrubRule{
+	{Name: "private-key", Pattern: `(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?(?:-----END [A-Z0-9 ]*PRIVATE KEY-----|\z)`},
+	{Name: "api-key", Pattern: `\b(?:sk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|glpat-[A-Za-z0-9_-]{20,}|xox[abposr]-[A-Za-z0-9-]{10,}|AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_-]{35})`},
+	{Name: "bearer-token", Pattern: `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]{8,}=*)`},
+	{Name: "url-userinfo", Pattern: `\b[A-Za-z][A-Za-z0-9+.-]*://(?P<secret>[^/?#\s@]+)@`},
+	{Name: "secret-assignment", Pattern: `\b[A-Z][A-Z0-9_]*(?:API_KEY|APIKEY|SECRET|TOKEN|PASSWORD|PASSWD)[A-Z0-9_]*=["']?(?P<secret>[^\s"']+)`},
+}
+
+// NewDefaultScrubber creates a Scrubber with rules for common credentials:
+// API keys, bearer tokens, credentials in URLs and environment assignments,
+// and private keys.
+func NewDefaultScrubber() *Scrubber {
+	s := NewScrubber()
+	for _, r := range defaultScrubRules {
+		if err := s.Add(r); err != nil {
+			panic(err)
+		}
+	}
+	return s
+}
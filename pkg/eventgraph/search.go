@@ -0,0 +1,218 @@
+package eventgraph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is one event matched by Search, with its relevance and a
+// snippet of its content with the matching words wrapped in <b></b>.
+type SearchResult struct {
+	Event   Event   `json:"event"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// Search query syntax: whitespace-separated terms that must all match. A
+// term is a word, a "quoted phrase", or either with a trailing * to match a
+// word prefix; a leading - excludes events that match it. Words are runs of
+// letters and digits, compared case-insensitively; type, source and the
+// string values in content are searched, JSON keys are not.
+type searchTerm struct {
+	words  []string // consecutive words; one for a plain word
+	prefix bool     // the last word matches as a prefix
+	not    bool
+}
+
+// parseSearch splits a search query into terms. It fails with
+// ErrInvalidQuery if nothing is left to match.
+func parseSearch(query string) ([]searchTerm, error) {
+	var terms []searchTerm
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		var t searchTerm
+		if rest[0] == '-' {
+			t.not = true
+			rest = rest[1:]
+		}
+		var raw string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			raw, rest = rest[1:end+1], rest[min(end+2, len(rest)):]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			raw, rest = rest[:end], rest[end:]
+		}
+		if strings.HasPrefix(rest, "*") {
+			rest = rest[1:]
+			raw += "*"
+		}
+		t.prefix = strings.HasSuffix(raw, "*")
+		t.words = searchWords(raw)
+		if len(t.words) > 0 {
+			terms = append(terms, t)
+		}
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+	for _, t := range terms {
+		if !t.not {
+			return terms, nil
+		}
+	}
+	return nil, fmt.Errorf("search %q: nothing to match: %w", query, ErrInvalidQuery)
+}
+
+// searchWords lowercases s and splits it into words.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tsquery renders terms for Postgres' to_tsquery. Words contain only
+// letters and digits, so nothing needs quoting.
+func tsquery(terms []searchTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		p := strings.Join(t.words, " <-> ")
+		if t.prefix {
+			p += ":*"
+		}
+		if len(t.words) > 1 {
+			p = "(" + p + ")"
+		}
+		if t.not {
+			p = "!" + p
+		}
+		parts[i] = p
+	}
+	return strings.Join(parts, " & ")
+}
+
+// Fallback search, for stores without a full-text index. Ranking follows
+// Postgres' weights: a match in type or source counts 1, in content 0.4.
+const (
+	searchWeightHeader  = 1.0
+	searchWeightContent = 0.4
+	snippetWords        = 20
+)
+
+// contentStrings appends the string values in v, depth first, in key order.
+func contentStrings(v any, out []string) []string {
+	switch v := v.(type) {
+	case string:
+		return append(out, v)
+	case []any:
+		for _, x := range v {
+			out = contentStrings(x, out)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = contentStrings(v[k], out)
+		}
+	}
+	return out
+}
+
+// hits returns the positions in words where t starts.
+func (t *searchTerm) hits(words []string) []int {
+	var out []int
+	for i := 0; i+len(t.words) <= len(words); i++ {
+		ok := true
+		for j, w := range t.words {
+			if words[i+j] != w && !(t.prefix && j == len(t.words)-1 && strings.HasPrefix(words[i+j], w)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// searchEvent matches e against terms without an index, returning its rank
+// and snippet. ok is false if e doesn't match.
+func searchEvent(e *Event, terms []searchTerm) (rank float64, snip string, ok bool) {
+	header := searchWords(e.Type + " " + e.Source)
+	text := strings.Join(contentStrings(e.Content, nil), " ")
+	body := searchWords(text)
+
+	first := -1
+	marked := make(map[int]bool)
+	for i := range terms {
+		t := &terms[i]
+		h, b := t.hits(header), t.hits(body)
+		if t.not {
+			if len(h)+len(b) > 0 {
+				return 0, "", false
+			}
+			continue
+		}
+		if len(h)+len(b) == 0 {
+			return 0, "", false
+		}
+		rank += searchWeightHeader*float64(len(h)) + searchWeightContent*float64(len(b))
+		for _, p := range b {
+			if first < 0 || p < first {
+				first = p
+			}
+			for j := range t.words {
+				marked[p+j] = true
+			}
+		}
+	}
+	return rank, makeSnippet(body, first, marked), true
+}
+
+// makeSnippet shows up to snippetWords words of body around the first hit,
+// with matched words in <b></b>. Words are shown lowercased and without
+// punctuation, as the fallback sees them.
+func makeSnippet(body []string, first int, marked map[int]bool) string {
+	start := max(0, first-snippetWords/4)
+	end := min(len(body), start+snippetWords)
+	parts := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		w := body[i]
+		if marked[i] {
+			w = "<b>" + w + "</b>"
+		}
+		parts = append(parts, w)
+	}
+	return strings.Join(parts, " ")
+}
+
+// rankResults orders results by rank, newest first among equals, and keeps
+// the first limit. Ranks are rounded so float noise doesn't beat recency.
+func rankResults(results []SearchResult, limit int) []SearchResult {
+	round := func(r float64) float64 { return math.Round(r * 1e6) }
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := &results[i], &results[j]
+		if round(a.Rank) != round(b.Rank) {
+			return a.Rank > b.Rank
+		}
+		if !a.Event.Timestamp.Equal(b.Event.Timestamp) {
+			return a.Event.Timestamp.After(b.Event.Timestamp)
+		}
+		return a.Event.ID > b.Event.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,1016 @@
+package eventgraph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mind-zero-five/pkg/page"
+)
+
+// SQLiteStore is a SQLite-backed EventStore with hash-chained integrity.
+// Timestamps are stored as Unix microseconds and causes as a JSON array.
+type SQLiteStore struct {
+	db       sqlConn
+	merkle   merkleLog
+	scrubber *Scrubber
+	causes   CauseMode
+}
+
+// NewSQLiteStore creates a SQLiteStore that masks secrets in appended
+// content with scrubber and treats causes that aren't on the chain as causes
+// says. The caller registers the driver.
+func NewSQLiteStore(db *sql.DB, scrubber *Scrubber, causes CauseMode) *SQLiteStore {
+	return &SQLiteStore{db: db, scrubber: scrubber, causes: causes}
+}
+
+// NewSQLiteStoreTx creates a SQLiteStore whose statements all run in tx, so
+// its writes commit or roll back with the caller's. It is only usable until
+// tx ends.
+func NewSQLiteStoreTx(tx *sql.Tx, scrubber *Scrubber, causes CauseMode) *SQLiteStore {
+	return &SQLiteStore{db: tx, scrubber: scrubber, causes: causes}
+}
+
+// sqlConn is the part of *sql.DB the store uses. *sql.Tx has it too, so a
+// store can run inside a caller's transaction (see NewSQLiteStoreTx).
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const sqliteEventColumns = `id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash, hash_version, signature`
+
+// Append creates and stores a new event, computing the hash chain.
+func (s *SQLiteStore) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer Signer) (*Event, error) {
+	events, err := s.AppendBatch(ctx, []AppendRequest{{
+		Type: eventType, Source: source, Content: content, Causes: causes, ConversationID: conversationID, Signer: signer,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// AppendBatch appends events consecutively in one transaction: either all
+// are stored, adjacent on the chain in order, or none are. The transaction
+// takes SQLite's write lock up front (see db.ConnectSQLite), which serializes
+// appenders across processes.
+func (s *SQLiteStore) AppendBatch(ctx context.Context, reqs []AppendRequest) ([]*Event, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	pending, err := prepareBatch(reqs, s.scrubber)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, owned, err := s.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	if owned {
+		defer tx.Rollback()
+	}
+
+	var head chainHead
+	var headMicros int64
+	err = tx.QueryRowContext(ctx, `SELECT hash, timestamp FROM events ORDER BY timestamp DESC, id DESC LIMIT 1`).Scan(&head.hash, &headMicros)
+	switch {
+	case err == nil:
+		head.timestamp = time.UnixMicro(headMicros)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("read chain head: %w", err)
+	}
+	exists, err := sqliteExisting(ctx, tx, causeIDs(pending))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCauses(pending, exists, s.causes); err != nil {
+		return nil, err
+	}
+
+	insert, err := tx.PrepareContext(ctx, `
+		INSERT INTO events (`+sqliteEventColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("prepare insert: %w", err)
+	}
+	defer insert.Close()
+
+	events := make([]*Event, len(pending))
+	for i := range pending {
+		e, err := pending[i].link(&head)
+		if err != nil {
+			return nil, err
+		}
+		causesJSON, err := json.Marshal(e.Causes)
+		if err != nil {
+			return nil, fmt.Errorf("marshal causes: %w", err)
+		}
+		_, err = insert.ExecContext(ctx,
+			e.ID, e.Type, e.Timestamp.UnixMicro(), e.Source, string(pending[i].contentJSON), string(causesJSON), e.ConversationID, e.Hash, e.PrevHash, e.HashVersion, e.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("insert event: %w", err)
+		}
+		events[i] = e
+	}
+
+	if owned {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit event: %w", err)
+		}
+	}
+	return events, nil
+}
+
+func (s *SQLiteStore) importEvents(ctx context.Context, events []StoredEvent) error {
+	tx, owned, err := s.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if owned {
+		defer tx.Rollback()
+	}
+	var held bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM events) OR EXISTS (SELECT 1 FROM archived_events)`).Scan(&held)
+	if err != nil {
+		return fmt.Errorf("import events: %w", err)
+	}
+	if held {
+		return fmt.Errorf("import events: the store already holds events")
+	}
+	if err := sqliteInsertStored(ctx, tx, events); err != nil {
+		return fmt.Errorf("import events: %w", err)
+	}
+	if owned {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("import events: commit: %w", err)
+		}
+	}
+	return nil
+}
+
+// sqliteInsertStored inserts events as they are, hashes and all.
+func sqliteInsertStored(ctx context.Context, tx *sql.Tx, events []StoredEvent) error {
+	insert, err := tx.PrepareContext(ctx, `
+		INSERT INTO events (`+sqliteEventColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer insert.Close()
+	for _, e := range events {
+		causesJSON, err := json.Marshal(e.Causes)
+		if err != nil {
+			return fmt.Errorf("marshal causes: %w", err)
+		}
+		_, err = insert.ExecContext(ctx,
+			e.ID, e.Type, e.Timestamp.UnixMicro(), e.Source, string(e.Content), string(causesJSON), e.ConversationID, e.Hash, e.PrevHash, e.HashVersion, e.Signature)
+		if err != nil {
+			return fmt.Errorf("insert event %s: %w", e.ID, err)
+		}
+	}
+	return nil
+}
+
+// sqliteExisting reports which of ids are events in tx, live or archived.
+func sqliteExisting(ctx context.Context, tx *sql.Tx, ids []string) (map[string]bool, error) {
+	exists := make(map[string]bool)
+	if len(ids) == 0 {
+		return exists, nil
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal causes: %w", err)
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM events WHERE id IN (SELECT value FROM json_each(?1))
+		UNION ALL
+		SELECT id FROM archived_events WHERE id IN (SELECT value FROM json_each(?1))`, string(idsJSON))
+	if err != nil {
+		return nil, fmt.Errorf("look up causes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("look up causes: %w", err)
+		}
+		exists[id] = true
+	}
+	return exists, rows.Err()
+}
+
+// begin starts a transaction, or joins the caller's if the store came from
+// NewSQLiteStoreTx. owned reports whether the transaction is ours to commit.
+func (s *SQLiteStore) begin(ctx context.Context) (tx *sql.Tx, owned bool, err error) {
+	if tx, ok := s.db.(*sql.Tx); ok {
+		return tx, false, nil
+	}
+	tx, err = s.db.(*sql.DB).BeginTx(ctx, nil)
+	return tx, err == nil, err
+}
+
+// Get retrieves a single event by ID.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Event, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sqliteEventColumns+` FROM events WHERE id = ?`, id)
+	e, _, err := scanSQLiteEvent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get event %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get event %s: %w", id, err)
+	}
+	return e, nil
+}
+
+// Redact replaces an event's content with a tombstone and records it.
+func (s *SQLiteStore) Redact(ctx context.Context, id string, req RedactRequest) (*Event, error) {
+	tx, owned, err := s.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	if owned {
+		defer tx.Rollback()
+	}
+	e, contentJSON, err := scanSQLiteEvent(tx.QueryRowContext(ctx, `SELECT `+sqliteEventColumns+` FROM events WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("redact %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	r, err := prepareRedaction(e, contentJSON, req)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := NewSQLiteStoreTx(tx, s.scrubber, s.causes).AppendBatch(ctx, []AppendRequest{r.audit})
+	if err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	raw, _, err := r.tombstone(audit[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE events SET content = ? WHERE id = ?`, string(raw), id); err != nil {
+		return nil, fmt.Errorf("redact %s: %w", id, err)
+	}
+	if owned {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("redact %s: commit: %w", id, err)
+		}
+	}
+	return audit[0], nil
+}
+
+// Recent returns the most recent events in reverse chronological order.
+func (s *SQLiteStore) Recent(ctx context.Context, limit int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events ORDER BY timestamp DESC, id DESC LIMIT ?`, limit)
+}
+
+// ByType returns events filtered by type.
+func (s *SQLiteStore) ByType(ctx context.Context, eventType string, limit int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE type = ? ORDER BY timestamp DESC, id DESC LIMIT ?`, eventType, limit)
+}
+
+// BySource returns events filtered by source.
+func (s *SQLiteStore) BySource(ctx context.Context, source string, limit int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE source = ? ORDER BY timestamp DESC, id DESC LIMIT ?`, source, limit)
+}
+
+// ByConversation returns events in a conversation in chronological order.
+func (s *SQLiteStore) ByConversation(ctx context.Context, conversationID string, limit int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE conversation_id = ? ORDER BY timestamp ASC, id ASC LIMIT ?`, conversationID, limit)
+}
+
+// Since returns events created after the given ID, for polling/SSE.
+func (s *SQLiteStore) Since(ctx context.Context, afterID string, limit int) ([]Event, error) {
+	if afterID == "" {
+		return s.scanMany(ctx, `
+			SELECT `+sqliteEventColumns+`
+			FROM events ORDER BY timestamp ASC, id ASC LIMIT ?`, limit)
+	}
+	return s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE (timestamp, id) > (
+			SELECT timestamp, id FROM events WHERE id = ?
+			UNION ALL SELECT timestamp, id FROM archived_events WHERE id = ?)
+		ORDER BY timestamp ASC, id ASC LIMIT ?`, afterID, afterID, limit)
+}
+
+// Query returns a page of the events matching q.
+func (s *SQLiteStore) Query(ctx context.Context, q Query) (*page.Page[Event], error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	c, _ := page.Parse(q.Cursor)
+	b := &sqlQuery{sqlite: true}
+	events, err := s.scanMany(ctx, b.build(q, c, sqliteEventColumns), b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	return page.Build(events, q.limit(), c, eventKey), nil
+}
+
+// Count returns the total number of events.
+func (s *SQLiteStore) Count(ctx context.Context) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count events: %w", err)
+	}
+	return n, nil
+}
+
+// VerifyChain walks the entire chain chronologically and verifies hash
+// integrity and, if keys is non-nil, signatures.
+func (s *SQLiteStore) VerifyChain(ctx context.Context, keys KeyLookup) error {
+	r, err := verifyRange(ctx, s, "", "", keys)
+	if err != nil {
+		return err
+	}
+	return r.Err()
+}
+
+// VerifyRange verifies fromID..toID inclusive.
+func (s *SQLiteStore) VerifyRange(ctx context.Context, fromID, toID string, keys KeyLookup) (*VerifyReport, error) {
+	return verifyRange(ctx, s, fromID, toID, keys)
+}
+
+// VerifySince verifies every event after a checkpoint.
+func (s *SQLiteStore) VerifySince(ctx context.Context, checkpoint *Checkpoint, keys KeyLookup) (*VerifyReport, error) {
+	return verifySince(ctx, s, checkpoint, keys)
+}
+
+// SaveCheckpoint records a verification checkpoint.
+func (s *SQLiteStore) SaveCheckpoint(ctx context.Context, report *VerifyReport, source string, signer Signer) (*Checkpoint, error) {
+	c, err := newCheckpoint(uuid.Must(uuid.NewV7()).String(), report, source, signer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO verification_checkpoints (id, event_id, event_hash, verified_at, source, signature)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		c.ID, c.EventID, c.EventHash, c.VerifiedAt.UnixMicro(), c.Source, c.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("save checkpoint: %w", err)
+	}
+	return c, nil
+}
+
+// LatestCheckpoint returns the most recent checkpoint, or nil.
+func (s *SQLiteStore) LatestCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	var c Checkpoint
+	var micros int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, event_id, event_hash, verified_at, source, signature
+		FROM verification_checkpoints ORDER BY verified_at DESC, id DESC LIMIT 1`).
+		Scan(&c.ID, &c.EventID, &c.EventHash, &micros, &c.Source, &c.Signature)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("latest checkpoint: %w", err)
+	}
+	c.VerifiedAt = time.UnixMicro(micros)
+	return &c, nil
+}
+
+// AckConsumer advances a consumer's position to eventID.
+func (s *SQLiteStore) AckConsumer(ctx context.Context, name, eventID string) error {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM events WHERE id = ? UNION ALL SELECT 1 FROM archived_events WHERE id = ?)`,
+		eventID, eventID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("ack %s: %w", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("ack %s: event %s: %w", name, eventID, ErrNotFound)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		WITH chain AS (SELECT id, timestamp FROM events UNION ALL SELECT id, timestamp FROM archived_events)
+		INSERT INTO consumer_offsets (name, event_id, acked_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET event_id = excluded.event_id, acked_at = excluded.acked_at
+		WHERE NOT EXISTS (
+			SELECT 1 FROM chain cur, chain acked
+			WHERE cur.id = consumer_offsets.event_id AND acked.id = excluded.event_id
+			  AND (acked.timestamp, acked.id) <= (cur.timestamp, cur.id))`,
+		name, eventID, time.Now().UnixMicro())
+	if err != nil {
+		return fmt.Errorf("ack %s: %w", name, err)
+	}
+	return nil
+}
+
+// ConsumerPosition returns the last event a consumer acked, or "".
+func (s *SQLiteStore) ConsumerPosition(ctx context.Context, name string) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, `SELECT event_id FROM consumer_offsets WHERE name = ?`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("consumer %s position: %w", name, err)
+	}
+	return id, nil
+}
+
+// Consumers lists consumer positions by name, with their lag.
+func (s *SQLiteStore) Consumers(ctx context.Context) ([]ConsumerState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH chain AS (SELECT id, timestamp FROM events UNION ALL SELECT id, timestamp FROM archived_events)
+		SELECT c.name, c.event_id, c.acked_at,
+			(SELECT COUNT(*) FROM chain e WHERE p.id IS NULL OR (e.timestamp, e.id) > (p.timestamp, p.id))
+		FROM consumer_offsets c LEFT JOIN chain p ON p.id = c.event_id
+		ORDER BY c.name`)
+	if err != nil {
+		return nil, fmt.Errorf("list consumers: %w", err)
+	}
+	defer rows.Close()
+	out := []ConsumerState{}
+	for rows.Next() {
+		var c ConsumerState
+		var micros int64
+		if err := rows.Scan(&c.Name, &c.EventID, &micros, &c.Lag); err != nil {
+			return nil, fmt.Errorf("scan consumer: %w", err)
+		}
+		c.AckedAt = time.UnixMicro(micros)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// CreateConversation starts a conversation.
+func (s *SQLiteStore) CreateConversation(ctx context.Context, id, title, createdBy string) (*Conversation, error) {
+	c := newConversation(id, title, createdBy)
+	if err := s.insertConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("create conversation %s: %w", c.ID, err)
+	}
+	return s.GetConversation(ctx, c.ID)
+}
+
+// ImportConversation inserts a conversation as given.
+func (s *SQLiteStore) ImportConversation(ctx context.Context, c *Conversation) error {
+	if err := s.insertConversation(ctx, c); err != nil {
+		return fmt.Errorf("import conversation %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) insertConversation(ctx context.Context, c *Conversation) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO conversations (id, title, created_by, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		c.ID, c.Title, c.CreatedBy, c.CreatedAt.UnixMicro())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return ErrConversationExists
+	}
+	return nil
+}
+
+// GetConversation returns a conversation with its participants and activity.
+func (s *SQLiteStore) GetConversation(ctx context.Context, id string) (*Conversation, error) {
+	cs, err := s.conversations(ctx, id, page.Cursor{}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation %s: %w", id, err)
+	}
+	if len(cs) == 0 {
+		return nil, conversationNotFound(id)
+	}
+	return &cs[0], nil
+}
+
+// RetitleConversation changes a conversation's title.
+func (s *SQLiteStore) RetitleConversation(ctx context.Context, id, title string) (*Conversation, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE conversations SET title = ? WHERE id = ?`, title, id)
+	if err != nil {
+		return nil, fmt.Errorf("retitle conversation %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, conversationNotFound(id)
+	}
+	return s.GetConversation(ctx, id)
+}
+
+// Conversations lists conversations, most recently active first.
+func (s *SQLiteStore) Conversations(ctx context.Context, limit int) ([]Conversation, error) {
+	cs, err := s.conversations(ctx, "", page.Cursor{}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	return cs, nil
+}
+
+// ConversationsPage returns a page of conversations, most recently active
+// first.
+func (s *SQLiteStore) ConversationsPage(ctx context.Context, cursor string, limit int) (*page.Page[Conversation], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := s.conversations(ctx, "", c, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	return page.Build(cs, limit, c, conversationKey), nil
+}
+
+// conversations reads conversation id, or all of them if id is "", with
+// what their events say about them, from cursor c on.
+func (s *SQLiteStore) conversations(ctx context.Context, id string, c page.Cursor, limit int) ([]Conversation, error) {
+	cmp, order := c.Keyset(true)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT * FROM (
+			SELECT c.id, c.title, c.created_by, c.created_at, count(e.id),
+				coalesce(max(e.timestamp), c.created_at) AS last_activity,
+				json_group_array(DISTINCT e.source) FILTER (WHERE e.source IS NOT NULL)
+			FROM conversations c
+			LEFT JOIN events e ON e.conversation_id = c.id AND e.conversation_id != ''
+			WHERE ?1 = '' OR c.id = ?1
+			GROUP BY c.id)
+		WHERE ?4 = '' OR (last_activity, id) `+cmp+` (?3, ?4)
+		ORDER BY last_activity `+order+`, id `+order+` LIMIT ?2`, id, limit, c.Time.UnixMicro(), c.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Conversation{}
+	for rows.Next() {
+		var c Conversation
+		var created, last int64
+		var sourcesJSON string
+		if err := rows.Scan(&c.ID, &c.Title, &c.CreatedBy, &created, &c.EventCount, &last, &sourcesJSON); err != nil {
+			return nil, err
+		}
+		var sources []string
+		if err := json.Unmarshal([]byte(sourcesJSON), &sources); err != nil {
+			return nil, fmt.Errorf("unmarshal participants: %w", err)
+		}
+		c.CreatedAt, c.LastActivity = time.UnixMicro(created), time.UnixMicro(last)
+		c.addParticipants(sources)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// TreeHead returns the Merkle root over the first treeSize events.
+func (s *SQLiteStore) TreeHead(ctx context.Context, treeSize int) (*TreeHead, error) {
+	return treeHead(ctx, s, &s.merkle, treeSize)
+}
+
+// InclusionProof proves that an event is in the tree of treeSize events.
+func (s *SQLiteStore) InclusionProof(ctx context.Context, id string, treeSize int) (*InclusionProof, error) {
+	return inclusionProof(ctx, s, &s.merkle, id, treeSize)
+}
+
+// ConsistencyProof proves that the tree of newSize events extends the tree
+// of oldSize events.
+func (s *SQLiteStore) ConsistencyProof(ctx context.Context, oldSize, newSize int) (*ConsistencyProof, error) {
+	return consistencyProof(ctx, s, &s.merkle, oldSize, newSize)
+}
+
+func (s *SQLiteStore) hashBefore(ctx context.Context, id string) (string, error) {
+	var hash string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT hash FROM events
+		WHERE (timestamp, id) < (SELECT timestamp, id FROM events WHERE id = ?)
+		ORDER BY timestamp DESC, id DESC LIMIT 1`, id).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		hash, _, err = s.archivedHead(ctx)
+	}
+	return hash, err
+}
+
+func (s *SQLiteStore) archivedHead(ctx context.Context) (string, int, error) {
+	var hash string
+	var n int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT coalesce((SELECT hash FROM archived_events ORDER BY timestamp DESC, id DESC LIMIT 1), ''),
+			(SELECT count(*) FROM archived_events)`).Scan(&hash, &n)
+	if err != nil {
+		return "", 0, fmt.Errorf("archived head: %w", err)
+	}
+	return hash, n, nil
+}
+
+func (s *SQLiteStore) scanArchived(ctx context.Context, fn func(id, hash string)) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, hash FROM archived_events ORDER BY timestamp ASC, id ASC`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return err
+		}
+		fn(id, hash)
+	}
+	return rows.Err()
+}
+
+// Segments lists the archived segments in chain order.
+func (s *SQLiteStore) Segments(ctx context.Context) ([]Segment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, first_id, last_id, first_timestamp, last_timestamp, count,
+			prev_hash, first_hash, last_hash, sha256, archived_at
+		FROM event_segments ORDER BY first_timestamp ASC, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	defer rows.Close()
+	out := []Segment{}
+	for rows.Next() {
+		var seg Segment
+		var first, last, archived int64
+		if err := rows.Scan(&seg.Name, &seg.FirstID, &seg.LastID, &first, &last, &seg.Count,
+			&seg.PrevHash, &seg.FirstHash, &seg.LastHash, &seg.SHA256, &archived); err != nil {
+			return nil, fmt.Errorf("scan segment: %w", err)
+		}
+		seg.FirstTimestamp, seg.LastTimestamp, seg.ArchivedAt = time.UnixMicro(first), time.UnixMicro(last), time.UnixMicro(archived)
+		out = append(out, seg)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) archiveSegment(ctx context.Context, seg *Segment) error {
+	tx, owned, err := s.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if owned {
+		defer tx.Rollback()
+	}
+
+	var firstID, lastHash string
+	var n int
+	var later bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT coalesce((SELECT id FROM events ORDER BY timestamp ASC, id ASC LIMIT 1), ''),
+			(SELECT count(*) FROM events WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = ?1)),
+			coalesce((SELECT hash FROM events WHERE id = ?1), ''),
+			EXISTS (SELECT 1 FROM events WHERE (timestamp, id) > (SELECT timestamp, id FROM events WHERE id = ?1))`,
+		seg.LastID).Scan(&firstID, &n, &lastHash, &later)
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", seg.Name, err)
+	}
+	if err := seg.checkPrefix(firstID, n, lastHash, later); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO event_segments (name, first_id, last_id, first_timestamp, last_timestamp, count,
+			prev_hash, first_hash, last_hash, sha256, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		seg.Name, seg.FirstID, seg.LastID, seg.FirstTimestamp.UnixMicro(), seg.LastTimestamp.UnixMicro(), seg.Count,
+		seg.PrevHash, seg.FirstHash, seg.LastHash, seg.SHA256, seg.ArchivedAt.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("archive %s: record segment: %w", seg.Name, err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO archived_events (id, timestamp, hash, segment)
+		SELECT id, timestamp, hash, ?1 FROM events
+		WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = ?2)`, seg.Name, seg.LastID)
+	if err != nil {
+		return fmt.Errorf("archive %s: stub events: %w", seg.Name, err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM events WHERE (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = ?)`, seg.LastID)
+	if err != nil {
+		return fmt.Errorf("archive %s: delete events: %w", seg.Name, err)
+	}
+
+	if owned {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("archive %s: commit: %w", seg.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) restoreSegment(ctx context.Context, seg *Segment, events []StoredEvent) error {
+	tx, owned, err := s.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if owned {
+		defer tx.Rollback()
+	}
+
+	var newest string
+	err = tx.QueryRowContext(ctx, `SELECT name FROM event_segments ORDER BY first_timestamp DESC, name DESC LIMIT 1`).Scan(&newest)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("restore %s: %w", seg.Name, err)
+	}
+	if newest != seg.Name {
+		return fmt.Errorf("restore %s: not the newest segment: %w", seg.Name, ErrSegment)
+	}
+
+	if err := sqliteInsertStored(ctx, tx, events); err != nil {
+		return fmt.Errorf("restore %s: %w", seg.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM archived_events WHERE segment = ?`, seg.Name); err != nil {
+		return fmt.Errorf("restore %s: delete stubs: %w", seg.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_segments WHERE name = ?`, seg.Name); err != nil {
+		return fmt.Errorf("restore %s: %w", seg.Name, err)
+	}
+
+	if owned {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("restore %s: commit: %w", seg.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) scanChain(ctx context.Context, fromID string, inclusive bool, toID string, fn func(e *Event, contentJSON []byte)) error {
+	query := `SELECT ` + sqliteEventColumns + ` FROM events WHERE 1`
+	var args []any
+	if fromID != "" {
+		op := ">"
+		if inclusive {
+			op = ">="
+		}
+		query += " AND (timestamp, id) " + op + " (SELECT timestamp, id FROM events WHERE id = ?)"
+		args = append(args, fromID)
+	}
+	if toID != "" {
+		query += " AND (timestamp, id) <= (SELECT timestamp, id FROM events WHERE id = ?)"
+		args = append(args, toID)
+	}
+	query += ` ORDER BY timestamp ASC, id ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, contentJSON, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return err
+		}
+		fn(e, contentJSON)
+	}
+	return rows.Err()
+}
+
+// Ancestors walks up the causes chain recursively.
+func (s *SQLiteStore) Ancestors(ctx context.Context, id string, maxDepth int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		WITH RECURSIVE ancestors(id, depth) AS (
+			SELECT c.value, 1
+			FROM events e, json_each(e.causes) c
+			WHERE e.id = ?1
+			UNION
+			SELECT c.value, a.depth + 1
+			FROM ancestors a
+			JOIN events e ON e.id = a.id, json_each(e.causes) c
+			WHERE a.depth < ?2
+		)
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM ancestors)
+		ORDER BY timestamp ASC, id ASC`, id, maxDepth)
+}
+
+// Descendants finds events that cite the given ID in their causes.
+func (s *SQLiteStore) Descendants(ctx context.Context, id string, maxDepth int) ([]Event, error) {
+	return s.scanMany(ctx, `
+		WITH RECURSIVE descendants(id, depth) AS (
+			SELECT e.id, 1
+			FROM events e, json_each(e.causes) c
+			WHERE c.value = ?1
+			UNION
+			SELECT e.id, d.depth + 1
+			FROM descendants d, events e, json_each(e.causes) c
+			WHERE c.value = d.id AND d.depth < ?2
+		)
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM descendants)
+		ORDER BY timestamp ASC, id ASC`, id, maxDepth)
+}
+
+// PathBetween finds the shortest causes path from from to to. The recursive
+// CTEs collect the events that are both ancestors of to and descendants of
+// from, pruned by timestamp since causes precede their effects; the path
+// through them is found in Go.
+func (s *SQLiteStore) PathBetween(ctx context.Context, from, to string) ([]Event, error) {
+	start, err := s.Get(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+	end, err := s.Get(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+	nodes, err := s.scanMany(ctx, `
+		WITH RECURSIVE
+		up(id) AS (
+			SELECT ?2
+			UNION
+			SELECT c.value
+			FROM up JOIN events e ON e.id = up.id AND e.timestamp > ?3, json_each(e.causes) c
+		),
+		down(id) AS (
+			SELECT ?1
+			UNION
+			SELECT e.id
+			FROM down, events e, json_each(e.causes) c
+			WHERE c.value = down.id AND e.timestamp <= ?4
+		)
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE id IN (SELECT id FROM up INTERSECT SELECT id FROM down)
+		ORDER BY timestamp ASC, id ASC`, from, to, start.Timestamp.UnixMicro(), end.Timestamp.UnixMicro())
+	if err != nil {
+		return nil, fmt.Errorf("path %s to %s: %w", from, to, err)
+	}
+	path := shortestPath(nodes, from, to)
+	if path == nil {
+		path = []Event{}
+	}
+	return path, nil
+}
+
+// CommonAncestors finds the events every one of ids descends from, walking
+// each one's ancestors in a single recursive CTE tagged by origin.
+func (s *SQLiteStore) CommonAncestors(ctx context.Context, ids ...string) ([]Event, error) {
+	ids, err := commonIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, err := s.Get(ctx, id); err != nil {
+			return nil, fmt.Errorf("common ancestors: %w", err)
+		}
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal ids: %w", err)
+	}
+	events, err := s.scanMany(ctx, `
+		WITH RECURSIVE ancestors(origin, id) AS (
+			SELECT o.value, c.value
+			FROM json_each(?1) o JOIN events e ON e.id = o.value, json_each(e.causes) c
+			UNION
+			SELECT a.origin, c.value
+			FROM ancestors a JOIN events e ON e.id = a.id, json_each(e.causes) c
+		)
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE id IN (
+			SELECT id FROM ancestors GROUP BY id HAVING count(DISTINCT origin) = ?2
+		)
+		ORDER BY timestamp ASC, id ASC`, string(idsJSON), len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("common ancestors: %w", err)
+	}
+	if events == nil {
+		events = []Event{}
+	}
+	return events, nil
+}
+
+// AuditCauses reports causes that aren't in the store or don't come before
+// the event citing them.
+func (s *SQLiteStore) AuditCauses(ctx context.Context) ([]CauseProblem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.type, c.value, CASE WHEN p.id IS NULL THEN ? ELSE ? END
+		FROM events e, json_each(e.causes) c
+		LEFT JOIN events p ON p.id = c.value
+		LEFT JOIN archived_events a ON a.id = c.value
+		WHERE (p.id IS NULL AND a.id IS NULL) OR p.timestamp > e.timestamp
+			OR (p.timestamp = e.timestamp AND p.id >= e.id)
+		ORDER BY e.timestamp ASC, e.id ASC, c.key ASC`, CauseDangling, CauseFuture)
+	if err != nil {
+		return nil, fmt.Errorf("audit causes: %w", err)
+	}
+	defer rows.Close()
+	problems := []CauseProblem{}
+	for rows.Next() {
+		var p CauseProblem
+		if err := rows.Scan(&p.EventID, &p.Type, &p.Cause, &p.Problem); err != nil {
+			return nil, fmt.Errorf("audit causes: %w", err)
+		}
+		problems = append(problems, p)
+	}
+	return problems, rows.Err()
+}
+
+// Search ranks events against a full-text query. SQLite has no index for
+// it: a LIKE per word narrows the candidates (words are letters and digits
+// only, so need no escaping), which are then matched and ranked like
+// MemStore does.
+func (s *SQLiteStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, err
+	}
+	var where []string
+	var args []any
+	for _, t := range terms {
+		if t.not {
+			continue
+		}
+		for _, w := range t.words {
+			where = append(where, "(type || ' ' || source || ' ' || content) LIKE ?")
+			args = append(args, "%"+w+"%")
+		}
+	}
+	candidates, err := s.scanMany(ctx, `
+		SELECT `+sqliteEventColumns+`
+		FROM events WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("search events: %w", err)
+	}
+	var results []SearchResult
+	for i := range candidates {
+		if rank, snip, ok := searchEvent(&candidates[i], terms); ok {
+			results = append(results, SearchResult{Event: candidates[i], Rank: rank, Snippet: snip})
+		}
+	}
+	return rankResults(results, limit), nil
+}
+
+// DistinctTypes returns all unique event types.
+func (s *SQLiteStore) DistinctTypes(ctx context.Context) ([]string, error) {
+	return s.distinct(ctx, `SELECT DISTINCT type FROM events ORDER BY type`)
+}
+
+// DistinctSources returns all unique event sources.
+func (s *SQLiteStore) DistinctSources(ctx context.Context) ([]string, error) {
+	return s.distinct(ctx, `SELECT DISTINCT source FROM events ORDER BY source`)
+}
+
+func (s *SQLiteStore) distinct(ctx context.Context, query string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("distinct: %w", err)
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+func (s *SQLiteStore) scanMany(ctx context.Context, query string, args ...any) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		e, _, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration: %w", err)
+	}
+	return events, nil
+}
+
+// scanSQLiteEvent scans one event row, also returning the stored content JSON
+// exactly as it was hashed.
+func scanSQLiteEvent(row interface{ Scan(dest ...any) error }) (*Event, []byte, error) {
+	var e Event
+	var micros int64
+	var contentJSON, causesJSON string
+	if err := row.Scan(&e.ID, &e.Type, &micros, &e.Source, &contentJSON, &causesJSON, &e.ConversationID, &e.Hash, &e.PrevHash, &e.HashVersion, &e.Signature); err != nil {
+		return nil, nil, err
+	}
+	e.Timestamp = time.UnixMicro(micros)
+	if err := json.Unmarshal([]byte(contentJSON), &e.Content); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal content: %w", err)
+	}
+	if err := json.Unmarshal([]byte(causesJSON), &e.Causes); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal causes: %w", err)
+	}
+	return &e, []byte(contentJSON), nil
+}
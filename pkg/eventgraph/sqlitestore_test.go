@@ -0,0 +1,147 @@
+package eventgraph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"mind-zero-five/internal/db/migrations"
+)
+
+func newTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.db")
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := migrations.NewSQLite(db).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteStoreConformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T, causes CauseMode) EventStore {
+		return NewSQLiteStore(newTestSQLite(t), NewDefaultScrubber(), causes)
+	})
+}
+
+func TestSQLiteStoreVerifiesMixedHashVersions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+	s := NewSQLiteStore(db, nil, CausesStrict)
+
+	// A row written before hash versioning: v1 hash, hash_version defaulted.
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := `{"legacy":true}`
+	legacyHash := computeHashV1("", "legacy-1", "test.legacy", "tester", "", ts, []byte(content))
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO events (id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash)
+		VALUES ('legacy-1', 'test.legacy', ?, 'tester', ?, '[]', '', ?, '')`,
+		ts.UnixMicro(), content, legacyHash); err != nil {
+		t.Fatalf("insert legacy event: %v", err)
+	}
+
+	e := mustAppend(t, s, "test.current", "tester", map[string]any{"n": 1}, []string{"legacy-1"}, "")
+	if e.HashVersion != CurrentHashVersion || e.PrevHash != legacyHash {
+		t.Fatalf("appended event: version %d prev %s", e.HashVersion, e.PrevHash)
+	}
+	legacy, err := s.Get(ctx, "legacy-1")
+	if err != nil || legacy.HashVersion != HashV1 {
+		t.Fatalf("legacy event: %+v, %v", legacy, err)
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify mixed chain: %v", err)
+	}
+	if _, err := s.Redact(ctx, "legacy-1", RedactRequest{Source: "admin", Reason: "why"}); !errors.Is(err, ErrNotRedactable) {
+		t.Fatalf("redact a v1 event: err = %v, want ErrNotRedactable", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE events SET causes = '[]' WHERE id = ?`, e.ID); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if err := s.VerifyChain(ctx, nil); err == nil {
+		t.Fatal("expected hash mismatch after rewriting causes of a v2 event")
+	}
+}
+
+func TestSQLiteStoreAuditCauses(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+	s := NewSQLiteStore(db, nil, CausesStrict)
+	root := mustAppend(t, s, "test.root", "tester", nil, nil, "")
+
+	// Rows written before Append checked causes: one cites an event that
+	// doesn't exist, the other one that comes after it.
+	ts := root.Timestamp.Add(time.Second).UnixMicro()
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO events (id, type, timestamp, source, content, causes, conversation_id, hash, prev_hash)
+		VALUES ('old-1', 'test.old', ?, 'tester', '{}', ?, '', 'h1', ''),
+			('old-2', 'test.old', ?, 'tester', '{}', '["old-3"]', '', 'h2', 'h1'),
+			('old-3', 'test.old', ?, 'tester', '{}', '[]', '', 'h3', 'h2')`,
+		ts, `["`+root.ID+`","gone"]`, ts+1, ts+2); err != nil {
+		t.Fatalf("insert old events: %v", err)
+	}
+
+	problems, err := s.AuditCauses(ctx)
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	want := []CauseProblem{
+		{"old-1", "test.old", "gone", CauseDangling},
+		{"old-2", "test.old", "old-3", CauseFuture},
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("audit = %+v, want %+v", problems, want)
+	}
+}
+
+func TestSQLiteStoreTxCommitsWithCaller(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+	s := NewSQLiteStore(db, nil, CausesStrict)
+	first := mustAppend(t, s, "test.before", "tester", nil, nil, "")
+
+	// Rolled back: the event never lands, and the chain head doesn't move.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := NewSQLiteStoreTx(tx, nil, CausesStrict).Append(ctx, "test.rolled_back", "tester", nil, nil, "", nil); err != nil {
+		t.Fatalf("append in tx: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if n, _ := s.Count(ctx); n != 1 {
+		t.Fatalf("count after rollback: got %d, want 1", n)
+	}
+
+	// Committed: the event lands and links to the head as usual.
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	e, err := NewSQLiteStoreTx(tx, nil, CausesStrict).Append(ctx, "test.committed", "tester", nil, nil, "", nil)
+	if err != nil {
+		t.Fatalf("append in tx: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if e.PrevHash != first.Hash {
+		t.Fatalf("event appended in tx does not link to the head")
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
@@ -0,0 +1,1308 @@
+package eventgraph
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mind-zero-five/pkg/page"
+)
+
+// testStoreConformance runs the shared EventStore conformance suite. Every
+// EventStore implementation must pass it; newStore returns an empty store
+// treating invalid causes as causes says.
+func testStoreConformance(t *testing.T, newStore func(t *testing.T, causes CauseMode) EventStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s EventStore)
+	}{
+		{"AppendChainsHashes", testAppendChainsHashes},
+		{"AppendDefaults", testAppendDefaults},
+		{"GetMissing", testGetMissing},
+		{"RecentOrdering", testRecentOrdering},
+		{"FilteredQueries", testFilteredQueries},
+		{"ByConversationChronological", testByConversationChronological},
+		{"Since", testSince},
+		{"AncestorsAndDescendants", testAncestorsAndDescendants},
+		{"PathAndCommonAncestors", testPathAndCommonAncestors},
+		{"CauseChecks", testCauseChecks},
+		{"Search", testSearch},
+		{"Distinct", testDistinct},
+		{"ContentRoundTrip", testContentRoundTrip},
+		{"Signatures", testSignatures},
+		{"VerifyRangeAndCheckpoints", testVerifyRangeAndCheckpoints},
+		{"MerkleProofs", testMerkleProofs},
+		{"AppendBatch", testAppendBatch},
+		{"ConcurrentAppendNoForks", testConcurrentAppendNoForks},
+		{"ConsumerPositions", testConsumerPositions},
+		{"Query", testQuery},
+		{"Conversations", testConversations},
+		{"ArchiveAndRestore", testArchiveAndRestore},
+		{"ConsumerAcrossArchive", testConsumerAcrossArchive},
+		{"ExportAndImport", testExportAndImport},
+		{"Redact", testRedact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t, CausesStrict))
+		})
+	}
+	t.Run("LenientCauses", func(t *testing.T) {
+		testLenientCauses(t, newStore(t, CausesLenient))
+	})
+}
+
+func mustAppend(t *testing.T, s EventStore, eventType, source string, content map[string]any, causes []string, conv string) *Event {
+	t.Helper()
+	e, err := s.Append(context.Background(), eventType, source, content, causes, conv, nil)
+	if err != nil {
+		t.Fatalf("append %s: %v", eventType, err)
+	}
+	return e
+}
+
+// testKeys is a KeyLookup over fixed keys.
+type testKeys map[string]testKey
+
+type testKey struct {
+	pub   ed25519.PublicKey
+	since time.Time
+}
+
+func (k testKeys) PublicKey(_ context.Context, source string) (ed25519.PublicKey, time.Time, error) {
+	return k[source].pub, k[source].since, nil
+}
+
+type testSigner ed25519.PrivateKey
+
+func (s testSigner) Sign(message []byte) []byte {
+	return ed25519.Sign(ed25519.PrivateKey(s), message)
+}
+
+func eventIDs(events []Event) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, what string, got []Event, want ...*Event) {
+	t.Helper()
+	wantIDs := make([]string, len(want))
+	for i, e := range want {
+		wantIDs[i] = e.ID
+	}
+	if strings.Join(eventIDs(got), ",") != strings.Join(wantIDs, ",") {
+		t.Fatalf("%s: got %v, want %v", what, eventIDs(got), wantIDs)
+	}
+}
+
+func testAppendChainsHashes(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	e1 := mustAppend(t, s, "test.one", "tester", map[string]any{"n": 1}, nil, "")
+	e2 := mustAppend(t, s, "test.two", "tester", map[string]any{"n": 2}, []string{e1.ID}, "")
+	e3 := mustAppend(t, s, "test.three", "tester", nil, nil, "conv-1")
+
+	if e1.PrevHash != "" {
+		t.Errorf("first event prev_hash: got %q, want empty", e1.PrevHash)
+	}
+	if e2.PrevHash != e1.Hash || e3.PrevHash != e2.Hash {
+		t.Fatalf("prev_hash does not link to previous event")
+	}
+	if e1.Hash == "" || e1.Hash == e2.Hash {
+		t.Fatalf("hashes should be non-empty and distinct")
+	}
+
+	got, err := s.Get(ctx, e2.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Hash != e2.Hash || got.PrevHash != e1.Hash || got.Type != "test.two" || got.Source != "tester" {
+		t.Fatalf("get returned %+v, want %+v", got, e2)
+	}
+	if !got.Timestamp.Equal(e2.Timestamp) {
+		t.Errorf("timestamp: got %v, want %v", got.Timestamp, e2.Timestamp)
+	}
+
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify chain: %v", err)
+	}
+	if n, err := s.Count(ctx); err != nil || n != 3 {
+		t.Fatalf("count: got %d, %v; want 3", n, err)
+	}
+}
+
+func testAppendDefaults(t *testing.T, s EventStore) {
+	e := mustAppend(t, s, "test.defaults", "tester", nil, nil, "")
+	if e.Content == nil {
+		t.Error("nil content should be stored as an empty object")
+	}
+	if e.Causes == nil {
+		t.Error("nil causes should be stored as an empty list")
+	}
+	got, err := s.Get(context.Background(), e.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Content == nil || len(got.Causes) != 0 {
+		t.Errorf("stored defaults: content=%v causes=%v", got.Content, got.Causes)
+	}
+}
+
+func testGetMissing(t *testing.T, s EventStore) {
+	_, err := s.Get(context.Background(), "no-such-event")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get missing: got %v, want ErrNotFound", err)
+	}
+}
+
+func testRecentOrdering(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	var appended []*Event
+	for i := 0; i < 5; i++ {
+		appended = append(appended, mustAppend(t, s, "test.order", "tester", map[string]any{"i": i}, nil, ""))
+	}
+	recent, err := s.Recent(ctx, 3)
+	if err != nil {
+		t.Fatalf("recent: %v", err)
+	}
+	assertIDs(t, "recent", recent, appended[4], appended[3], appended[2])
+}
+
+func testFilteredQueries(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	a1 := mustAppend(t, s, "task.created", "api", nil, nil, "")
+	b1 := mustAppend(t, s, "task.claimed", "mind", nil, nil, "")
+	a2 := mustAppend(t, s, "task.created", "mind", nil, nil, "")
+
+	byType, err := s.ByType(ctx, "task.created", 10)
+	if err != nil {
+		t.Fatalf("by type: %v", err)
+	}
+	assertIDs(t, "by type", byType, a2, a1)
+
+	bySource, err := s.BySource(ctx, "mind", 10)
+	if err != nil {
+		t.Fatalf("by source: %v", err)
+	}
+	assertIDs(t, "by source", bySource, a2, b1)
+
+	limited, err := s.ByType(ctx, "task.created", 1)
+	if err != nil {
+		t.Fatalf("by type limited: %v", err)
+	}
+	assertIDs(t, "by type limited", limited, a2)
+}
+
+func testByConversationChronological(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	c1 := mustAppend(t, s, "chat.message", "matt", nil, nil, "conv-a")
+	mustAppend(t, s, "chat.message", "matt", nil, nil, "conv-b")
+	c2 := mustAppend(t, s, "chat.message", "mind", nil, nil, "conv-a")
+
+	got, err := s.ByConversation(ctx, "conv-a", 10)
+	if err != nil {
+		t.Fatalf("by conversation: %v", err)
+	}
+	assertIDs(t, "by conversation", got, c1, c2)
+}
+
+func testSince(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	e1 := mustAppend(t, s, "test.since", "tester", nil, nil, "")
+	e2 := mustAppend(t, s, "test.since", "tester", nil, nil, "")
+	e3 := mustAppend(t, s, "test.since", "tester", nil, nil, "")
+
+	got, err := s.Since(ctx, e1.ID, 10)
+	if err != nil {
+		t.Fatalf("since: %v", err)
+	}
+	assertIDs(t, "since", got, e2, e3)
+
+	got, err = s.Since(ctx, e1.ID, 1)
+	if err != nil {
+		t.Fatalf("since limited: %v", err)
+	}
+	assertIDs(t, "since limited", got, e2)
+
+	got, err = s.Since(ctx, e3.ID, 10)
+	if err != nil {
+		t.Fatalf("since head: %v", err)
+	}
+	assertIDs(t, "since head", got)
+
+	got, err = s.Since(ctx, "unknown-id", 10)
+	if err != nil {
+		t.Fatalf("since unknown: %v", err)
+	}
+	assertIDs(t, "since unknown", got)
+
+	got, err = s.Since(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("since genesis: %v", err)
+	}
+	assertIDs(t, "since genesis", got, e1, e2)
+}
+
+func testConsumerPositions(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	e1 := mustAppend(t, s, "test.consume", "tester", nil, nil, "")
+	e2 := mustAppend(t, s, "test.consume", "tester", nil, nil, "")
+	mustAppend(t, s, "test.consume", "tester", nil, nil, "")
+
+	pos, err := s.ConsumerPosition(ctx, "a")
+	if err != nil || pos != "" {
+		t.Fatalf("new consumer position = %q, %v; want empty", pos, err)
+	}
+	if err := s.AckConsumer(ctx, "a", e2.ID); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	// Acks never move a position backwards.
+	if err := s.AckConsumer(ctx, "a", e1.ID); err != nil {
+		t.Fatalf("ack older: %v", err)
+	}
+	if pos, _ := s.ConsumerPosition(ctx, "a"); pos != e2.ID {
+		t.Fatalf("position after stale ack = %s, want %s", pos, e2.ID)
+	}
+	if err := s.AckConsumer(ctx, "b", e1.ID); err != nil {
+		t.Fatalf("ack b: %v", err)
+	}
+	if err := s.AckConsumer(ctx, "a", "unknown-id"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ack unknown = %v, want ErrNotFound", err)
+	}
+
+	consumers, err := s.Consumers(ctx)
+	if err != nil {
+		t.Fatalf("consumers: %v", err)
+	}
+	if len(consumers) != 2 {
+		t.Fatalf("got %d consumers, want 2", len(consumers))
+	}
+	a, b := consumers[0], consumers[1]
+	if a.Name != "a" || a.EventID != e2.ID || a.Lag != 1 || a.AckedAt.IsZero() {
+		t.Errorf("consumer a = %+v, want at %s with lag 1", a, e2.ID)
+	}
+	if b.Name != "b" || b.EventID != e1.ID || b.Lag != 2 {
+		t.Errorf("consumer b = %+v, want at %s with lag 2", b, e1.ID)
+	}
+}
+
+func testAncestorsAndDescendants(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	// root -> mid -> leaf, plus side -> leaf
+	root := mustAppend(t, s, "test.root", "tester", nil, nil, "")
+	side := mustAppend(t, s, "test.side", "tester", nil, nil, "")
+	mid := mustAppend(t, s, "test.mid", "tester", nil, []string{root.ID}, "")
+	leaf := mustAppend(t, s, "test.leaf", "tester", nil, []string{mid.ID, side.ID}, "")
+	mustAppend(t, s, "test.unrelated", "tester", nil, nil, "")
+
+	anc, err := s.Ancestors(ctx, leaf.ID, 10)
+	if err != nil {
+		t.Fatalf("ancestors: %v", err)
+	}
+	assertIDs(t, "ancestors", anc, root, side, mid)
+
+	anc, err = s.Ancestors(ctx, leaf.ID, 1)
+	if err != nil {
+		t.Fatalf("ancestors depth 1: %v", err)
+	}
+	assertIDs(t, "ancestors depth 1", anc, side, mid)
+
+	desc, err := s.Descendants(ctx, root.ID, 10)
+	if err != nil {
+		t.Fatalf("descendants: %v", err)
+	}
+	assertIDs(t, "descendants", desc, mid, leaf)
+
+	desc, err = s.Descendants(ctx, root.ID, 1)
+	if err != nil {
+		t.Fatalf("descendants depth 1: %v", err)
+	}
+	assertIDs(t, "descendants depth 1", desc, mid)
+
+	none, err := s.Ancestors(ctx, root.ID, 10)
+	if err != nil {
+		t.Fatalf("ancestors of root: %v", err)
+	}
+	assertIDs(t, "ancestors of root", none)
+}
+
+func testPathAndCommonAncestors(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	// root -> a -> b -> c -> leaf, with a shortcut root -> short -> leaf,
+	// and two failures sharing the assessment under root.
+	root := mustAppend(t, s, "test.root", "tester", nil, nil, "")
+	a := mustAppend(t, s, "test.a", "tester", nil, []string{root.ID}, "")
+	b := mustAppend(t, s, "test.b", "tester", nil, []string{a.ID}, "")
+	c := mustAppend(t, s, "test.c", "tester", nil, []string{b.ID}, "")
+	short := mustAppend(t, s, "test.short", "tester", nil, []string{root.ID}, "")
+	leaf := mustAppend(t, s, "test.leaf", "tester", nil, []string{c.ID, short.ID}, "")
+	assess := mustAppend(t, s, "test.assess", "tester", nil, []string{root.ID}, "")
+	fail1 := mustAppend(t, s, "test.fail", "tester", nil, []string{assess.ID, a.ID}, "")
+	fail2 := mustAppend(t, s, "test.fail", "tester", nil, []string{assess.ID}, "")
+	other := mustAppend(t, s, "test.other", "tester", nil, nil, "")
+
+	path, err := s.PathBetween(ctx, root.ID, leaf.ID)
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	assertIDs(t, "shortest path", path, root, short, leaf)
+
+	path, err = s.PathBetween(ctx, a.ID, leaf.ID)
+	if err != nil {
+		t.Fatalf("path from a: %v", err)
+	}
+	assertIDs(t, "path from a", path, a, b, c, leaf)
+
+	path, err = s.PathBetween(ctx, leaf.ID, root.ID)
+	if err != nil {
+		t.Fatalf("reversed path: %v", err)
+	}
+	assertIDs(t, "reversed path", path)
+
+	path, err = s.PathBetween(ctx, c.ID, c.ID)
+	if err != nil {
+		t.Fatalf("path to itself: %v", err)
+	}
+	assertIDs(t, "path to itself", path, c)
+
+	if _, err := s.PathBetween(ctx, root.ID, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("path to missing event: err = %v, want ErrNotFound", err)
+	}
+
+	common, err := s.CommonAncestors(ctx, fail1.ID, fail2.ID)
+	if err != nil {
+		t.Fatalf("common ancestors: %v", err)
+	}
+	assertIDs(t, "common ancestors", common, root, assess)
+
+	common, err = s.CommonAncestors(ctx, fail1.ID, leaf.ID, fail1.ID)
+	if err != nil {
+		t.Fatalf("common ancestors of three: %v", err)
+	}
+	assertIDs(t, "common ancestors with a repeat", common, root, a)
+
+	common, err = s.CommonAncestors(ctx, fail2.ID, other.ID)
+	if err != nil {
+		t.Fatalf("unrelated common ancestors: %v", err)
+	}
+	assertIDs(t, "unrelated common ancestors", common)
+
+	if _, err := s.CommonAncestors(ctx, fail1.ID, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("common ancestors with a missing event: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CommonAncestors(ctx); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("common ancestors of nothing: err = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func testCauseChecks(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	a := mustAppend(t, s, "test.a", "tester", nil, nil, "")
+
+	if _, err := s.Append(ctx, "test.b", "tester", nil, []string{a.ID, "missing"}, "", nil); !errors.Is(err, ErrInvalidCause) {
+		t.Errorf("append with a missing cause: err = %v, want ErrInvalidCause", err)
+	}
+	_, err := s.AppendBatch(ctx, []AppendRequest{
+		{Type: "test.ok", Source: "tester", Causes: []string{a.ID}},
+		{Type: "test.bad", Source: "tester", Causes: []string{"missing"}},
+	})
+	if !errors.Is(err, ErrInvalidCause) {
+		t.Errorf("batch with a missing cause: err = %v, want ErrInvalidCause", err)
+	}
+	if n, _ := s.Count(ctx); n != 1 {
+		t.Errorf("count after rejected appends = %d, want 1", n)
+	}
+
+	problems, err := s.AuditCauses(ctx)
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if problems == nil || len(problems) != 0 {
+		t.Errorf("audit of a clean store = %#v, want []", problems)
+	}
+}
+
+func testLenientCauses(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	a := mustAppend(t, s, "test.a", "tester", nil, nil, "")
+
+	b := mustAppend(t, s, "test.b", "tester", map[string]any{"n": 1}, []string{a.ID, "missing"}, "")
+	got, err := s.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Causes) != 1 || got.Causes[0] != a.ID {
+		t.Errorf("lenient causes = %v, want only %s", got.Causes, a.ID)
+	}
+	if bad, _ := got.Content[InvalidCausesKey].([]any); len(bad) != 1 || bad[0] != "missing" {
+		t.Errorf("lenient content = %v, want %s listing the missing cause", got.Content, InvalidCausesKey)
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Errorf("verify after lenient append: %v", err)
+	}
+
+	// Only the store may flag invalid causes.
+	_, err = s.Append(ctx, "test.c", "tester", map[string]any{InvalidCausesKey: []string{"forged"}}, []string{a.ID}, "", nil)
+	if !errors.Is(err, ErrSchema) {
+		t.Errorf("append with %s: err = %v, want ErrSchema", InvalidCausesKey, err)
+	}
+	if n, _ := s.Count(ctx); n != 2 {
+		t.Errorf("count after rejected append = %d, want 2", n)
+	}
+}
+
+func testSearch(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	e1 := mustAppend(t, s, "build.failed", "mind", map[string]any{"error": "undefined: Foo"}, nil, "")
+	mustAppend(t, s, "task.created", "api", map[string]any{"subject": "bar"}, nil, "")
+	e3 := mustAppend(t, s, "deploy.started", "mind", map[string]any{"note": "fix for FOO"}, nil, "")
+
+	e4 := mustAppend(t, s, "task.created", "api", map[string]any{"subject": "deploy the search index", "deploy": "x"}, nil, "")
+
+	search := func(what, query string, want ...*Event) []SearchResult {
+		t.Helper()
+		results, err := s.Search(ctx, query, 10)
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+		got := make([]Event, len(results))
+		for i, r := range results {
+			got[i] = r.Event
+		}
+		assertIDs(t, what, got, want...)
+		return results
+	}
+	search("content", "foo", e3, e1)
+	search("type", "BUILD", e1)
+	// A type match outranks a content match.
+	search("ranking", "deploy", e3, e4)
+	search("prefix", "dep*", e3, e4)
+	search("phrase", `"search index"`, e4)
+	search("phrase order", `"index search"`)
+	search("negation", "foo -fix", e1)
+	search("keys not indexed", "subject")
+	results := search("snippet", "index", e4)
+	if !strings.Contains(results[0].Snippet, "<b>index</b>") || results[0].Rank <= 0 {
+		t.Errorf("snippet %q, rank %v", results[0].Snippet, results[0].Rank)
+	}
+
+	if _, err := s.Search(ctx, "-foo", 10); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("negation only: got %v, want ErrInvalidQuery", err)
+	}
+}
+
+func testDistinct(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	mustAppend(t, s, "b.type", "src-2", nil, nil, "")
+	mustAppend(t, s, "a.type", "src-1", nil, nil, "")
+	mustAppend(t, s, "b.type", "src-1", nil, nil, "")
+
+	types, err := s.DistinctTypes(ctx)
+	if err != nil {
+		t.Fatalf("distinct types: %v", err)
+	}
+	if strings.Join(types, ",") != "a.type,b.type" {
+		t.Errorf("distinct types: got %v", types)
+	}
+	sources, err := s.DistinctSources(ctx)
+	if err != nil {
+		t.Fatalf("distinct sources: %v", err)
+	}
+	if strings.Join(sources, ",") != "src-1,src-2" {
+		t.Errorf("distinct sources: got %v", sources)
+	}
+}
+
+func testContentRoundTrip(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	e := mustAppend(t, s, "test.content", "tester", map[string]any{
+		"count":  3,
+		"nested": map[string]any{"ok": true},
+		"list":   []string{"a", "b"},
+	}, nil, "")
+
+	got, err := s.Get(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	// Numbers come back as float64, exactly as they would from JSONB.
+	if n, ok := got.Content["count"].(float64); !ok || n != 3 {
+		t.Errorf("count: got %#v", got.Content["count"])
+	}
+	if nested, ok := got.Content["nested"].(map[string]any); !ok || nested["ok"] != true {
+		t.Errorf("nested: got %#v", got.Content["nested"])
+	}
+	if list, ok := got.Content["list"].([]any); !ok || len(list) != 2 {
+		t.Errorf("list: got %#v", got.Content["list"])
+	}
+
+	// Mutating a returned event must not affect the stored copy.
+	got.Content["count"] = 99.0
+	again, _ := s.Get(ctx, e.ID)
+	if again.Content["count"] != 3.0 {
+		t.Errorf("store shares content with callers: got %#v", again.Content["count"])
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify chain: %v", err)
+	}
+}
+
+func testSignatures(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	// Unsigned events are fine from sources without a key, and from keyed
+	// sources before their key existed.
+	early := mustAppend(t, s, "test.early", "mind", nil, nil, "")
+	mustAppend(t, s, "signal.human", "ui", nil, nil, "")
+	signed, err := s.Append(ctx, "test.signed", "mind", map[string]any{"n": 1}, nil, "", testSigner(priv))
+	if err != nil {
+		t.Fatalf("append signed: %v", err)
+	}
+	if signed.Signature == "" {
+		t.Fatal("signed event has no signature")
+	}
+	got, err := s.Get(ctx, signed.ID)
+	if err != nil || got.Signature != signed.Signature {
+		t.Fatalf("stored signature: got %q, %v; want %q", got.Signature, err, signed.Signature)
+	}
+
+	keys := testKeys{"mind": {pub: pub, since: early.Timestamp.Add(time.Microsecond)}}
+	if err := s.VerifyChain(ctx, keys); err != nil {
+		t.Fatalf("verify signatures: %v", err)
+	}
+	wrongKey := testKeys{"mind": {pub: otherPub, since: early.Timestamp.Add(time.Microsecond)}}
+	if err := s.VerifyChain(ctx, wrongKey); err == nil {
+		t.Fatal("expected failure verifying against the wrong public key")
+	}
+	if err := s.VerifyChain(ctx, testKeys{}); err == nil {
+		t.Fatal("expected failure for a signed event whose source has no key")
+	}
+
+	// Once mind has a key, an unsigned event claiming to be mind is rejected.
+	mustAppend(t, s, "test.forged", "mind", nil, nil, "")
+	if err := s.VerifyChain(ctx, keys); err == nil {
+		t.Fatal("expected failure for an unsigned event from a keyed source")
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("hash-only verification should still pass: %v", err)
+	}
+}
+
+func testVerifyRangeAndCheckpoints(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	var ev []*Event
+	for i := 0; i < 5; i++ {
+		ev = append(ev, mustAppend(t, s, "test.range", "tester", map[string]any{"i": i}, nil, ""))
+	}
+
+	r, err := s.VerifyRange(ctx, ev[1].ID, ev[3].ID, nil)
+	if err != nil {
+		t.Fatalf("verify range: %v", err)
+	}
+	if !r.OK() || r.Checked != 3 || r.FirstID != ev[1].ID || r.LastID != ev[3].ID || r.AtHead {
+		t.Fatalf("range report: %+v", r)
+	}
+	if _, err := s.VerifyRange(ctx, "no-such-event", "", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unknown range start: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.SaveCheckpoint(ctx, r, "verifier", nil); err == nil {
+		t.Fatal("checkpointing a range that stops short of the head should fail")
+	}
+
+	if cp, err := s.LatestCheckpoint(ctx); err != nil || cp != nil {
+		t.Fatalf("latest checkpoint on empty table: %+v, %v", cp, err)
+	}
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	full, err := s.VerifySince(ctx, nil, nil)
+	if err != nil || !full.OK() || full.Checked != 5 || !full.AtHead {
+		t.Fatalf("full verification: %+v, %v", full, err)
+	}
+	cp, err := s.SaveCheckpoint(ctx, full, "verifier", testSigner(priv))
+	if err != nil {
+		t.Fatalf("save checkpoint: %v", err)
+	}
+	if cp.EventID != ev[4].ID || cp.EventHash != ev[4].Hash || cp.Signature == "" {
+		t.Fatalf("checkpoint: %+v", cp)
+	}
+	latest, err := s.LatestCheckpoint(ctx)
+	if err != nil || latest == nil || latest.ID != cp.ID || !latest.VerifiedAt.Equal(cp.VerifiedAt) {
+		t.Fatalf("latest checkpoint: got %+v, %v; want %+v", latest, err, cp)
+	}
+
+	e6 := mustAppend(t, s, "test.range", "tester", nil, nil, "")
+	e7 := mustAppend(t, s, "test.range", "tester", nil, nil, "")
+	keys := testKeys{"verifier": {pub: pub}}
+	since, err := s.VerifySince(ctx, latest, keys)
+	if err != nil {
+		t.Fatalf("verify since: %v", err)
+	}
+	if !since.OK() || since.Checked != 2 || since.FirstID != e6.ID || since.LastID != e7.ID {
+		t.Fatalf("since report: %+v", since)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	forged, err := s.VerifySince(ctx, latest, testKeys{"verifier": {pub: otherPub}})
+	if err != nil {
+		t.Fatalf("verify since with wrong key: %v", err)
+	}
+	if forged.OK() {
+		t.Fatal("expected a break for a checkpoint signed by the wrong key")
+	}
+}
+
+func testMerkleProofs(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	empty, err := s.TreeHead(ctx, 0)
+	if err != nil || empty.TreeSize != 0 {
+		t.Fatalf("empty tree head: %+v, %v", empty, err)
+	}
+
+	var ev []*Event
+	for i := 0; i < 6; i++ {
+		ev = append(ev, mustAppend(t, s, "test.merkle", "tester", map[string]any{"i": i}, nil, ""))
+	}
+	head, err := s.TreeHead(ctx, 0)
+	if err != nil || head.TreeSize != 6 {
+		t.Fatalf("tree head: %+v, %v", head, err)
+	}
+	for i, e := range ev {
+		p, err := s.InclusionProof(ctx, e.ID, 0)
+		if err != nil {
+			t.Fatalf("inclusion proof for event %d: %v", i, err)
+		}
+		if p.LeafIndex != i || p.RootHash != head.RootHash || p.EventHash != e.Hash {
+			t.Fatalf("inclusion proof for event %d: %+v", i, p)
+		}
+		if err := p.Verify(); err != nil {
+			t.Fatalf("verify inclusion of event %d: %v", i, err)
+		}
+	}
+	if _, err := s.InclusionProof(ctx, ev[4].ID, 3); !errors.Is(err, ErrTreeSize) {
+		t.Fatalf("proof in a tree that predates the event: got %v, want ErrTreeSize", err)
+	}
+	if _, err := s.TreeHead(ctx, 7); !errors.Is(err, ErrTreeSize) {
+		t.Fatalf("tree head beyond the log: got %v, want ErrTreeSize", err)
+	}
+
+	old, err := s.TreeHead(ctx, 4)
+	if err != nil {
+		t.Fatalf("tree head at 4: %v", err)
+	}
+	// The log keeps growing; earlier tree heads stay provably consistent.
+	mustAppend(t, s, "test.merkle", "tester", nil, nil, "")
+	c, err := s.ConsistencyProof(ctx, 4, 0)
+	if err != nil {
+		t.Fatalf("consistency proof: %v", err)
+	}
+	if c.OldRoot != old.RootHash || c.NewSize != 7 {
+		t.Fatalf("consistency proof: %+v", c)
+	}
+	if err := c.Verify(); err != nil {
+		t.Fatalf("verify consistency: %v", err)
+	}
+	c.OldRoot = head.RootHash
+	if err := c.Verify(); err == nil {
+		t.Fatal("consistency proof accepted against the wrong old root")
+	}
+}
+
+func testAppendBatch(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	if events, err := s.AppendBatch(ctx, nil); err != nil || len(events) != 0 {
+		t.Fatalf("empty batch: %v, %v", events, err)
+	}
+	first := mustAppend(t, s, "test.before", "tester", nil, nil, "")
+	events, err := s.AppendBatch(ctx, []AppendRequest{
+		{Type: "test.batch", Source: "tester", Content: map[string]any{"n": 1}},
+		{Type: "test.batch", Source: "tester", Causes: []string{first.ID}},
+		{Type: "test.batch", Source: "other", ConversationID: "conv-b"},
+	})
+	if err != nil {
+		t.Fatalf("append batch: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	prev := first
+	for i, e := range events {
+		if e.PrevHash != prev.Hash || !e.Timestamp.After(prev.Timestamp) {
+			t.Fatalf("batch event %d does not follow its predecessor", i)
+		}
+		prev = e
+	}
+	stored, err := s.Get(ctx, events[0].ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if stored.Content["n"] != float64(1) || events[1].Causes[0] != first.ID || events[2].ConversationID != "conv-b" {
+		t.Fatalf("batch fields not stored: %+v %+v", stored, events)
+	}
+	recent, err := s.Recent(ctx, 4)
+	if err != nil {
+		t.Fatalf("recent: %v", err)
+	}
+	assertIDs(t, "recent after batch", recent, events[2], events[1], events[0], first)
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+// testConcurrentAppendNoForks starts from an empty store, where there's no
+// head row to lock, and has hundreds of appenders race to extend the chain.
+func testConcurrentAppendNoForks(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	const appenders, batchSize = 200, 3
+
+	var wg sync.WaitGroup
+	errs := make(chan error, appenders)
+	want := 0
+	for i := 0; i < appenders; i++ {
+		if i%4 == 0 {
+			want += batchSize
+		} else {
+			want++
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 != 0 {
+				_, err := s.Append(ctx, "test.concurrent", fmt.Sprintf("appender-%d", i), map[string]any{"i": i}, nil, "", nil)
+				errs <- err
+				return
+			}
+			reqs := make([]AppendRequest, batchSize)
+			for j := range reqs {
+				reqs[j] = AppendRequest{Type: "test.concurrent", Source: fmt.Sprintf("appender-%d", i), Content: map[string]any{"i": i, "j": j}}
+			}
+			_, err := s.AppendBatch(ctx, reqs)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	n, err := s.Count(ctx)
+	if err != nil || n != want {
+		t.Fatalf("count: got %d, %v; want %d", n, err, want)
+	}
+	events, err := s.Recent(ctx, n)
+	if err != nil {
+		t.Fatalf("recent: %v", err)
+	}
+	// A fork shows up as two events linking to the same predecessor.
+	successors := map[string]string{}
+	for _, e := range events {
+		if other, dup := successors[e.PrevHash]; dup {
+			t.Fatalf("chain forked: %s and %s both follow %q", other, e.ID, e.PrevHash)
+		}
+		successors[e.PrevHash] = e.ID
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func testQuery(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	thought := mustAppend(t, s, "mind.thought", "mind", map[string]any{"task_id": "t1"}, nil, "c1")
+	failed := mustAppend(t, s, "mind.claude.failed", "mind", map[string]any{"task_id": "t2", "attempt": 2}, nil, "c2")
+	created := mustAppend(t, s, "task.created", "api", map[string]any{"task_id": "t1", "meta": map[string]any{"urgent": true}}, nil, "c1")
+	mustAppend(t, s, "minder.x", "api", nil, nil, "")
+
+	query := func(what string, q Query, want ...*Event) *page.Page[Event] {
+		t.Helper()
+		got, err := s.Query(ctx, q)
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+		assertIDs(t, what, got.Items, want...)
+		return got
+	}
+	query("wildcard", Query{Types: []string{"mind.*"}}, failed, thought)
+	query("types or", Query{Types: []string{"mind.claude.failed", "task.*"}}, created, failed)
+	query("type and source", Query{Types: []string{"mind.*", "task.*"}, Sources: []string{"api"}}, created)
+	query("conversation", Query{Conversations: []string{"c1"}, Ascending: true}, thought, created)
+	query("content", Query{Content: []ContentMatch{{Path: "task_id", Value: "t1"}}}, created, thought)
+	query("content number", Query{Content: []ContentMatch{{Path: "attempt", Value: "2"}}}, failed)
+	query("content nested bool", Query{Content: []ContentMatch{{Path: "meta.urgent", Value: "true"}}}, created)
+	query("content not", Query{Types: []string{"mind.*", "task.*"}, Content: []ContentMatch{{Path: "task_id", Value: "t1", Not: true}}}, failed)
+	query("content object", Query{Content: []ContentMatch{{Path: "meta", Value: "{}"}}})
+	query("time range", Query{Since: failed.Timestamp, Until: created.Timestamp}, failed)
+
+	paged := Query{Types: []string{"mind.*", "task.*"}, Limit: 2}
+	p1 := query("page 1", paged, created, failed)
+	if p1.Next == "" || p1.Prev != "" {
+		t.Fatalf("page 1 cursors: next %q, prev %q", p1.Next, p1.Prev)
+	}
+	paged.Cursor = p1.Next
+	p2 := query("page 2", paged, thought)
+	if p2.Next != "" || p2.Prev == "" {
+		t.Fatalf("page 2 cursors: next %q, prev %q", p2.Next, p2.Prev)
+	}
+	paged.Cursor = p2.Prev
+	back := query("back to page 1", paged, created, failed)
+	if back.Next == "" || back.Prev != "" {
+		t.Fatalf("back to page 1 cursors: next %q, prev %q", back.Next, back.Prev)
+	}
+	asc := query("ascending page", Query{Ascending: true, Limit: 1}, thought)
+	query("ascending next", Query{Ascending: true, Limit: 1, Cursor: asc.Next}, failed)
+
+	if _, err := s.Query(ctx, Query{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad cursor: got %v, want ErrInvalidQuery", err)
+	}
+	if _, err := s.Query(ctx, Query{Content: []ContentMatch{{Path: "a..b", Value: "x"}}}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad content path: got %v, want ErrInvalidQuery", err)
+	}
+}
+
+func testConversations(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	quiet, err := s.CreateConversation(ctx, "", "Quiet", "ui")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if quiet.ID == "" || quiet.EventCount != 0 || !quiet.LastActivity.Equal(quiet.CreatedAt) ||
+		strings.Join(quiet.Participants, ",") != "ui" {
+		t.Errorf("new conversation = %+v", quiet)
+	}
+	task, err := s.CreateConversation(ctx, "task-1", "Fix the build", "mind")
+	if err != nil {
+		t.Fatalf("create with an ID: %v", err)
+	}
+	if _, err := s.CreateConversation(ctx, "task-1", "Again", "mind"); !errors.Is(err, ErrConversationExists) {
+		t.Errorf("create a taken ID: err = %v, want ErrConversationExists", err)
+	}
+
+	mustAppend(t, s, "test.a", "mind", nil, nil, task.ID)
+	mustAppend(t, s, "test.b", "api", nil, nil, "")
+	last := mustAppend(t, s, "test.c", "human", nil, nil, task.ID)
+
+	got, err := s.GetConversation(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Title != "Fix the build" || got.EventCount != 2 || !got.LastActivity.Equal(last.Timestamp) ||
+		strings.Join(got.Participants, ",") != "human,mind" {
+		t.Errorf("conversation with events = %+v", got)
+	}
+
+	renamed, err := s.RetitleConversation(ctx, quiet.ID, "Renamed")
+	if err != nil || renamed.Title != "Renamed" {
+		t.Errorf("retitle = %+v, %v", renamed, err)
+	}
+	if _, err := s.RetitleConversation(ctx, "missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("retitle missing: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetConversation(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get missing: err = %v, want ErrNotFound", err)
+	}
+
+	list, err := s.Conversations(ctx, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != task.ID || list[1].ID != quiet.ID {
+		t.Errorf("list = %+v, want the busy conversation first", list)
+	}
+	if list, _ := s.Conversations(ctx, 1); len(list) != 1 {
+		t.Errorf("list with limit 1 returned %d", len(list))
+	}
+
+	first, err := s.ConversationsPage(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(first.Items) != 1 || first.Items[0].ID != task.ID || first.Next == "" || first.Prev != "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	second, err := s.ConversationsPage(ctx, first.Next, 1)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(second.Items) != 1 || second.Items[0].ID != quiet.ID || second.Next != "" || second.Prev == "" {
+		t.Fatalf("second page = %+v", second)
+	}
+	back, err := s.ConversationsPage(ctx, second.Prev, 1)
+	if err != nil {
+		t.Fatalf("page back: %v", err)
+	}
+	if len(back.Items) != 1 || back.Items[0].ID != task.ID {
+		t.Errorf("page back = %+v, want the busy conversation", back)
+	}
+	if _, err := s.ConversationsPage(ctx, "!!", 1); !errors.Is(err, page.ErrInvalidCursor) {
+		t.Errorf("bad cursor: err = %v, want ErrInvalidCursor", err)
+	}
+
+	created := time.UnixMicro(1700000000123456)
+	imported := &Conversation{ID: "imported", Title: "From elsewhere", CreatedBy: "mind", CreatedAt: created, EventCount: 9}
+	if err := s.ImportConversation(ctx, imported); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got, err = s.GetConversation(ctx, "imported")
+	if err != nil {
+		t.Fatalf("get imported: %v", err)
+	}
+	if got.Title != "From elsewhere" || got.CreatedBy != "mind" || !got.CreatedAt.Equal(created) ||
+		got.EventCount != 0 || !got.LastActivity.Equal(created) {
+		t.Errorf("imported conversation = %+v", got)
+	}
+	if err := s.ImportConversation(ctx, imported); !errors.Is(err, ErrConversationExists) {
+		t.Errorf("import a taken ID: err = %v, want ErrConversationExists", err)
+	}
+}
+
+func testArchiveAndRestore(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	a := mustAppend(t, s, "test.old", "tester", map[string]any{"n": 1}, nil, "")
+	b := mustAppend(t, s, "test.old", "tester", nil, []string{a.ID}, "")
+	c := mustAppend(t, s, "test.kept", "tester", nil, []string{b.ID}, "")
+	d := mustAppend(t, s, "test.kept", "tester", nil, nil, "")
+	before, err := s.TreeHead(ctx, 0)
+	if err != nil {
+		t.Fatalf("tree head: %v", err)
+	}
+
+	// A consumer at c keeps c and everything after it.
+	if err := s.AckConsumer(ctx, "reader", c.ID); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	segs, err := Archive(ctx, s, dir, RetentionPolicy{})
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if len(segs) != 1 || segs[0].FirstID != a.ID || segs[0].LastID != b.ID || segs[0].Count != 2 || segs[0].LastHash != b.Hash {
+		t.Fatalf("archived %+v, want one segment of a..b", segs)
+	}
+	if listed, err := s.Segments(ctx); err != nil || len(listed) != 1 || listed[0].SHA256 != segs[0].SHA256 {
+		t.Fatalf("segments = %+v, %v", listed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, segs[0].ManifestFile())); err != nil {
+		t.Errorf("manifest: %v", err)
+	}
+	if _, err := s.Get(ctx, a.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get archived event: err = %v, want ErrNotFound", err)
+	}
+	if n, _ := s.Count(ctx); n != 2 {
+		t.Errorf("count after archive = %d, want 2", n)
+	}
+
+	// Consumer positions may be archived events; lag still counts them.
+	if err := s.AckConsumer(ctx, "reader", a.ID); err != nil {
+		t.Errorf("stale ack of an archived event: %v", err)
+	}
+	if err := s.AckConsumer(ctx, "old", a.ID); err != nil {
+		t.Fatalf("ack an archived event: %v", err)
+	}
+	consumers, err := s.Consumers(ctx)
+	if err != nil || len(consumers) != 2 {
+		t.Fatalf("consumers after archive = %+v, %v", consumers, err)
+	}
+	if old := consumers[0]; old.Name != "old" || old.EventID != a.ID || old.Lag != 3 {
+		t.Errorf("consumer at an archived event = %+v, want at %s with lag 3", old, a.ID)
+	}
+	if reader := consumers[1]; reader.EventID != c.ID || reader.Lag != 1 {
+		t.Errorf("reader = %+v, want at %s with lag 1", reader, c.ID)
+	}
+
+	// The chain and the tree still run from genesis, through the stubs.
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Errorf("verify after archive: %v", err)
+	}
+	r, err := s.VerifyRange(ctx, "", "", nil)
+	if err != nil || !r.OK() || r.Archived != 2 || r.FirstID != c.ID || r.Checked != 2 {
+		t.Errorf("verify range after archive = %+v, %v", r, err)
+	}
+	if r, err := s.VerifyRange(ctx, c.ID, d.ID, nil); err != nil || !r.OK() {
+		t.Errorf("verify from the first live event = %+v, %v", r, err)
+	}
+	if after, err := s.TreeHead(ctx, 0); err != nil || *after != *before {
+		t.Errorf("tree head after archive = %+v, %v; want %+v", after, err, before)
+	}
+
+	// Archived events are still on the chain for causes.
+	if _, err := s.Append(ctx, "test.new", "tester", nil, []string{a.ID}, "", nil); err != nil {
+		t.Fatalf("append citing an archived event: %v", err)
+	}
+	if problems, err := s.AuditCauses(ctx); err != nil || len(problems) != 0 {
+		t.Errorf("audit causes = %+v, %v; want none", problems, err)
+	}
+	grown, err := s.TreeHead(ctx, 0)
+	if err != nil {
+		t.Fatalf("tree head: %v", err)
+	}
+
+	if _, err := Restore(ctx, s, dir, "events-nope"); !errors.Is(err, ErrSegment) {
+		t.Errorf("restore a segment that isn't the newest: err = %v, want ErrSegment", err)
+	}
+	seg, err := Restore(ctx, s, dir, "")
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if seg.Name != segs[0].Name {
+		t.Errorf("restored %s, want %s", seg.Name, segs[0].Name)
+	}
+	got, err := s.Get(ctx, a.ID)
+	if err != nil || got.Hash != a.Hash || got.Content["n"] != float64(1) {
+		t.Errorf("restored event = %+v, %v", got, err)
+	}
+	if n, _ := s.Count(ctx); n != 5 {
+		t.Errorf("count after restore = %d, want 5", n)
+	}
+	if err := s.VerifyChain(ctx, nil); err != nil {
+		t.Errorf("verify after restore: %v", err)
+	}
+	if head, err := s.TreeHead(ctx, 0); err != nil || *head != *grown {
+		t.Errorf("tree head after restore = %+v, %v; want %+v", head, err, grown)
+	}
+	if _, err := Restore(ctx, s, dir, ""); !errors.Is(err, ErrSegment) {
+		t.Errorf("restore with nothing archived: err = %v, want ErrSegment", err)
+	}
+
+	// A segment file that changed on disk is refused.
+	if err := s.AckConsumer(ctx, "old", c.ID); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	segs, err = Archive(ctx, s, dir, RetentionPolicy{})
+	if err != nil || len(segs) != 1 {
+		t.Fatalf("archive again = %+v, %v", segs, err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, segs[0].DataFile()), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("x"))
+	f.Close()
+	if _, err := Restore(ctx, s, dir, ""); !errors.Is(err, ErrSegment) {
+		t.Errorf("restore a tampered segment: err = %v, want ErrSegment", err)
+	}
+	if n, _ := s.Count(ctx); n != 3 {
+		t.Errorf("count after a refused restore = %d, want 3", n)
+	}
+}
+
+func testConsumerAcrossArchive(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	bus := NewBus(s)
+	f := Filter{Types: []string{"test.work"}}
+	e1 := mustAppend(t, bus, "test.work", "tester", nil, nil, "")
+
+	runCtx, stop := context.WithCancel(ctx)
+	worker := NewConsumer(bus, "worker", f)
+	ch, err := worker.Start(runCtx)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	e2 := mustAppend(t, bus, "test.work", "tester", nil, nil, "")
+	e3 := mustAppend(t, bus, "test.work", "tester", nil, nil, "")
+	receive(t, ch)
+	receive(t, ch)
+	if err := worker.Ack(ctx, e2); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+
+	// Archiving stops short of the worker's position.
+	segs, err := Archive(ctx, s, t.TempDir(), RetentionPolicy{})
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if len(segs) != 1 || segs[0].LastID != e1.ID {
+		t.Fatalf("archived %+v, want one segment ending at %s", segs, e1.ID)
+	}
+	e4 := mustAppend(t, bus, "test.work", "tester", nil, nil, "")
+	if got := receive(t, ch); got.ID != e4.ID {
+		t.Fatalf("delivery after archive = %s, want %s", got.ID, e4.ID)
+	}
+	stop()
+	for range ch {
+	}
+
+	// Restarted, it resumes from its position.
+	runCtx, stop = context.WithCancel(ctx)
+	defer stop()
+	if ch, err = worker.Start(runCtx); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if got := receive(t, ch); got.ID != e3.ID {
+		t.Fatalf("redelivery after archive = %s, want unacked %s", got.ID, e3.ID)
+	}
+
+	// A consumer whose position is archived reads on from the first live
+	// event.
+	if err := bus.AckConsumer(ctx, "late", e1.ID); err != nil {
+		t.Fatalf("ack an archived event: %v", err)
+	}
+	late, err := NewConsumer(bus, "late", f).Start(runCtx)
+	if err != nil {
+		t.Fatalf("start late: %v", err)
+	}
+	if got := receive(t, late); got.ID != e2.ID {
+		t.Fatalf("late delivery = %s, want %s", got.ID, e2.ID)
+	}
+
+	consumers, err := bus.Consumers(ctx)
+	if err != nil || len(consumers) != 2 {
+		t.Fatalf("consumers = %+v, %v", consumers, err)
+	}
+	if c := consumers[0]; c.Name != "late" || c.Lag != 3 {
+		t.Errorf("late = %+v, want lag 3", c)
+	}
+	if c := consumers[1]; c.Name != "worker" || c.EventID != e2.ID || c.Lag != 2 {
+		t.Errorf("worker = %+v, want at %s with lag 2", c, e2.ID)
+	}
+}
+
+func testExportAndImport(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := testKeys{"mind": {pub: pub}}
+	src := NewMemStore(nil, CausesStrict)
+	a := mustAppend(t, src, "test.a", "tester", map[string]any{"price": 2.50, "nested": map[string]any{"z": 1, "a": []any{"x"}}}, nil, "conv")
+	b, err := src.Append(ctx, "test.b", "mind", map[string]any{"n": 1}, []string{a.ID}, "", testSigner(priv))
+	if err != nil {
+		t.Fatalf("append signed: %v", err)
+	}
+	want, err := src.TreeHead(ctx, 0)
+	if err != nil {
+		t.Fatalf("tree head: %v", err)
+	}
+
+	exported, err := ExportEvents(ctx, src)
+	if err != nil || len(exported) != 2 {
+		t.Fatalf("export = %d events, %v", len(exported), err)
+	}
+	// Through JSON and back, as a bundle file would carry them.
+	data, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []StoredEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatalf("unmarshal exported events: %v", err)
+	}
+
+	// A tampered event, or one signed with the wrong key, stops the import.
+	tampered := slices.Clone(events)
+	tampered[0].Content = json.RawMessage(`{"nested":{"a":["x"],"z":1},"price":3}`)
+	if err := ImportEvents(ctx, s, tampered, nil); err == nil {
+		t.Error("import of a tampered event succeeded")
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if err := ImportEvents(ctx, s, events, testKeys{"mind": {pub: otherPub}}); err == nil {
+		t.Error("import with the wrong signing key succeeded")
+	}
+	if n, _ := s.Count(ctx); n != 0 {
+		t.Fatalf("failed imports stored %d events", n)
+	}
+
+	if err := ImportEvents(ctx, s, events, keys); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got, err := s.Get(ctx, b.ID)
+	if err != nil || got.Hash != b.Hash || got.PrevHash != a.Hash || !got.Timestamp.Equal(b.Timestamp) || got.Signature != b.Signature {
+		t.Errorf("imported event = %+v, %v; want %+v", got, err, b)
+	}
+	if err := s.VerifyChain(ctx, keys); err != nil {
+		t.Errorf("verify after import: %v", err)
+	}
+	if head, err := s.TreeHead(ctx, 0); err != nil || *head != *want {
+		t.Errorf("tree head after import = %+v, %v; want %+v", head, err, want)
+	}
+	if again, err := ExportEvents(ctx, s); err != nil || len(again) != 2 || string(again[0].Content) != string(exported[0].Content) {
+		t.Errorf("re-export = %+v, %v", again, err)
+	}
+
+	// Appends carry on from the imported head.
+	if c := mustAppend(t, s, "test.c", "tester", nil, []string{b.ID}, ""); c.PrevHash != b.Hash {
+		t.Errorf("append after import: prev_hash = %s, want %s", c.PrevHash, b.Hash)
+	}
+	if err := ImportEvents(ctx, s, events, nil); err == nil {
+		t.Error("import into a store holding events succeeded")
+	}
+}
+
+func testRedact(t *testing.T, s EventStore) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := testKeys{"mind": {pub: pub}}
+	leak, err := s.Append(ctx, "mind.claude.completed", "mind", map[string]any{"task_id": "t1", "result": "token sk-secret"}, nil, "conv", testSigner(priv))
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	after := mustAppend(t, s, "test.after", "tester", nil, []string{leak.ID}, "")
+	before, err := s.TreeHead(ctx, 0)
+	if err != nil {
+		t.Fatalf("tree head: %v", err)
+	}
+
+	if _, err := s.Redact(ctx, leak.ID, RedactRequest{Source: "admin"}); err == nil {
+		t.Error("redact without a reason succeeded")
+	}
+	audit, err := s.Redact(ctx, leak.ID, RedactRequest{Source: "admin", Reason: "leaked API key", RequestedBy: "alice", ApprovalID: "auth-1"})
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	if audit.Type != EventRedacted || audit.Source != "admin" || audit.Content["event_id"] != leak.ID ||
+		audit.Content["reason"] != "leaked API key" || audit.Content["requested_by"] != "alice" || audit.Content["approval_id"] != "auth-1" ||
+		len(audit.Causes) != 1 || audit.Causes[0] != leak.ID ||
+		audit.ConversationID != "conv" || audit.PrevHash != after.Hash {
+		t.Errorf("audit event = %+v", audit)
+	}
+
+	got, err := s.Get(ctx, leak.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	tomb, _ := got.Content[RedactedKey].(map[string]any)
+	if !got.Redacted() || len(got.Content) != 1 || tomb["redaction"] != audit.ID || tomb["content_sha256"] != audit.Content["content_sha256"] {
+		t.Errorf("redacted content = %v", got.Content)
+	}
+	if got.Hash != leak.Hash || got.Signature != leak.Signature {
+		t.Errorf("redaction changed the hash or signature")
+	}
+
+	// The chain still verifies, signatures included, and the tree is as it
+	// was plus the audit event.
+	if err := s.VerifyChain(ctx, keys); err != nil {
+		t.Errorf("verify after redact: %v", err)
+	}
+	if head, err := s.TreeHead(ctx, 0); err != nil || head.TreeSize != before.TreeSize+1 {
+		t.Errorf("tree head after redact = %+v, %v", head, err)
+	} else if old, err := s.TreeHead(ctx, before.TreeSize); err != nil || *old != *before {
+		t.Errorf("tree head at the old size = %+v, %v; want %+v", old, err, before)
+	}
+
+	if _, err := s.Redact(ctx, leak.ID, RedactRequest{Source: "admin", Reason: "again"}); !errors.Is(err, ErrNotRedactable) {
+		t.Errorf("redact twice: err = %v, want ErrNotRedactable", err)
+	}
+	if _, err := s.Redact(ctx, "missing", RedactRequest{Source: "admin", Reason: "why"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("redact missing: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Append(ctx, "test.forged", "tester", map[string]any{RedactedKey: map[string]any{}}, nil, "", nil); !errors.Is(err, ErrSchema) {
+		t.Errorf("append a tombstone: err = %v, want ErrSchema", err)
+	}
+}
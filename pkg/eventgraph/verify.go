@@ -0,0 +1,219 @@
+package eventgraph
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Break is one problem found while verifying the chain.
+type Break struct {
+	Position int    `json:"position"` // offset from the first event checked
+	EventID  string `json:"event_id"`
+	Reason   string `json:"reason"`
+}
+
+// VerifyReport is the result of verifying part or all of the chain. Unlike
+// VerifyChain, verification doesn't stop at the first problem: every broken
+// link is listed.
+type VerifyReport struct {
+	Since    *Checkpoint `json:"since,omitempty"` // checkpoint verification resumed from
+	FirstID  string      `json:"first_id"`        // first event checked
+	LastID   string      `json:"last_id"`         // last event checked
+	LastHash string      `json:"last_hash"`       // its stored hash
+	Checked  int         `json:"checked"`         // number of events checked
+	AtHead   bool        `json:"at_head"`         // the range ran to the chain head
+	Archived int         `json:"archived"`        // events archived before FirstID, linked to by their stubs
+	Breaks   []Break     `json:"breaks"`
+}
+
+// OK reports whether no problems were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Breaks) == 0
+}
+
+// Err summarizes the first problem as an error, or returns nil if there
+// were none.
+func (r *VerifyReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	b := r.Breaks[0]
+	err := fmt.Errorf("event %d (%s): %s", b.Position, b.EventID, b.Reason)
+	if n := len(r.Breaks) - 1; n > 0 {
+		err = fmt.Errorf("%w (and %d more)", err, n)
+	}
+	return err
+}
+
+// Checkpoint records that the chain was verified up to an event, so later
+// verification can start from there instead of from genesis.
+type Checkpoint struct {
+	ID         string    `json:"id"`
+	EventID    string    `json:"event_id"`   // last event verified
+	EventHash  string    `json:"event_hash"` // its hash when verified
+	VerifiedAt time.Time `json:"verified_at"`
+	Source     string    `json:"source"`    // actor that verified the chain
+	Signature  string    `json:"signature"` // hex Ed25519 signature by Source; empty if unsigned
+}
+
+// checkpointMessage is what a checkpoint's signature covers.
+func checkpointMessage(c *Checkpoint) []byte {
+	return []byte(fmt.Sprintf("mind-zero-five/checkpoint/v1\n%s\n%s\n%s\n%d", c.EventID, c.EventHash, c.Source, c.VerifiedAt.UnixNano()))
+}
+
+// chainScanner is implemented by each store so the verification logic can be
+// shared.
+type chainScanner interface {
+	EventStore
+
+	// hashBefore returns the hash of the event immediately before id in chain
+	// order, archived or not, or "" if id is the first event.
+	hashBefore(ctx context.Context, id string) (string, error)
+
+	// scanChain calls fn for each event in chain order, starting at fromID
+	// (inclusive, or exclusive if !inclusive; "" = genesis) and ending at toID
+	// (inclusive; "" = head). contentJSON is the content as stored.
+	scanChain(ctx context.Context, fromID string, inclusive bool, toID string, fn func(e *Event, contentJSON []byte)) error
+
+	// archivedHead returns the hash of the last archived event and how many
+	// are archived, or "" and 0 if none are (see Archive). scanArchived calls
+	// fn with each archived event's stub in chain order.
+	archivedHead(ctx context.Context) (hash string, n int, err error)
+	scanArchived(ctx context.Context, fn func(id, hash string)) error
+}
+
+// chainWalk accumulates a VerifyReport from events fed in chain order.
+type chainWalk struct {
+	report   VerifyReport
+	sigs     *signatureVerifier
+	prevHash string
+}
+
+func newChainWalk(sigs *signatureVerifier, prevHash string) *chainWalk {
+	return &chainWalk{report: VerifyReport{Breaks: []Break{}}, sigs: sigs, prevHash: prevHash}
+}
+
+func (w *chainWalk) add(e *Event, contentJSON []byte) {
+	r := &w.report
+	if r.Checked == 0 {
+		r.FirstID = e.ID
+	}
+	reasons := linkProblems(e, w.prevHash, contentJSON)
+	if p := w.sigs.problem(e); p != "" {
+		reasons = append(reasons, p)
+	}
+	for _, reason := range reasons {
+		r.Breaks = append(r.Breaks, Break{Position: r.Checked, EventID: e.ID, Reason: reason})
+	}
+	// Continue from the stored hash, so one tampered event is reported once
+	// rather than breaking every link after it.
+	w.prevHash = e.Hash
+	r.LastID = e.ID
+	r.LastHash = e.Hash
+	r.Checked++
+}
+
+func (w *chainWalk) fail(eventID, reason string) {
+	w.report.Breaks = append(w.report.Breaks, Break{Position: w.report.Checked, EventID: eventID, Reason: reason})
+}
+
+// verifyRange implements EventStore.VerifyRange.
+func verifyRange(ctx context.Context, s chainScanner, fromID, toID string, keys KeyLookup) (*VerifyReport, error) {
+	for _, id := range []string{fromID, toID} {
+		if id == "" {
+			continue
+		}
+		if _, err := s.Get(ctx, id); err != nil {
+			return nil, fmt.Errorf("verify range: %w", err)
+		}
+	}
+	sigs, err := newSignatureVerifier(ctx, s, keys)
+	if err != nil {
+		return nil, err
+	}
+	w := newChainWalk(sigs, "")
+	if fromID != "" {
+		w.prevHash, err = s.hashBefore(ctx, fromID)
+	} else {
+		// The live chain starts where the archived events end.
+		w.prevHash, w.report.Archived, err = s.archivedHead(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verify range: %w", err)
+	}
+	if err := s.scanChain(ctx, fromID, true, toID, w.add); err != nil {
+		return nil, fmt.Errorf("verify range: %w", err)
+	}
+	w.report.AtHead = toID == ""
+	return &w.report, nil
+}
+
+// verifySince implements EventStore.VerifySince.
+func verifySince(ctx context.Context, s chainScanner, cp *Checkpoint, keys KeyLookup) (*VerifyReport, error) {
+	if cp == nil {
+		return verifyRange(ctx, s, "", "", keys)
+	}
+	sigs, err := newSignatureVerifier(ctx, s, keys)
+	if err != nil {
+		return nil, err
+	}
+	w := newChainWalk(sigs, cp.EventHash)
+	w.report.Since = cp
+
+	// The checkpoint itself must still hold: signed by its source, and the
+	// event it vouches for unchanged.
+	if keys != nil {
+		key, _, err := keys.PublicKey(ctx, cp.Source)
+		if err != nil {
+			return nil, fmt.Errorf("verify since: look up key for %q: %w", cp.Source, err)
+		}
+		if key == nil || !verifySignature(key, checkpointMessage(cp), cp.Signature) {
+			w.fail(cp.EventID, fmt.Sprintf("checkpoint %s is not validly signed by %q", cp.ID, cp.Source))
+		}
+	}
+	e, err := s.Get(ctx, cp.EventID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		w.fail(cp.EventID, fmt.Sprintf("checkpoint %s: event no longer exists", cp.ID))
+		return &w.report, nil
+	case err != nil:
+		return nil, fmt.Errorf("verify since: %w", err)
+	case e.Hash != cp.EventHash:
+		w.fail(cp.EventID, fmt.Sprintf("checkpoint %s: event hash changed from %s to %s", cp.ID, cp.EventHash, e.Hash))
+	}
+
+	if err := s.scanChain(ctx, cp.EventID, false, "", w.add); err != nil {
+		return nil, fmt.Errorf("verify since: %w", err)
+	}
+	w.report.AtHead = true
+	return &w.report, nil
+}
+
+// newCheckpoint builds a checkpoint for the last event of a clean report
+// that ran to the chain head.
+func newCheckpoint(id string, r *VerifyReport, source string, signer Signer) (*Checkpoint, error) {
+	if !r.OK() || !r.AtHead {
+		return nil, fmt.Errorf("checkpoint: only a clean verification up to the head can be checkpointed")
+	}
+	c := &Checkpoint{
+		ID:         id,
+		EventID:    r.LastID,
+		EventHash:  r.LastHash,
+		VerifiedAt: time.Now().Truncate(time.Microsecond),
+		Source:     source,
+	}
+	if c.EventID == "" && r.Since != nil {
+		// Nothing new since the last checkpoint; vouch for the same event.
+		c.EventID, c.EventHash = r.Since.EventID, r.Since.EventHash
+	}
+	if c.EventID == "" {
+		return nil, fmt.Errorf("checkpoint: no events verified")
+	}
+	if signer != nil {
+		c.Signature = hex.EncodeToString(signer.Sign(checkpointMessage(c)))
+	}
+	return c, nil
+}
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"mind-zero-five/pkg/payload"
 )
 
 // Proposal is a self-improvement proposal produced by assessment.
@@ -115,13 +117,7 @@ func (m *Mind) gatherAssessmentContext(ctx context.Context) string {
 	} else if len(blocked) > 0 {
 		sb.WriteString("## Blocked Tasks\n\n")
 		for _, t := range blocked {
-			reason := ""
-			if t.Metadata != nil {
-				if r, ok := t.Metadata["blocked_reason"].(string); ok {
-					reason = r
-				}
-			}
-			if reason != "" {
+			if reason := payload.ReadTaskMeta(t.Metadata).BlockedReason; reason != "" {
 				sb.WriteString(fmt.Sprintf("- %s (source=%s, assignee=%s) — REASON: %s\n", t.Subject, t.Source, t.Assignee, reason))
 			} else {
 				sb.WriteString(fmt.Sprintf("- %s (source=%s, assignee=%s)\n", t.Subject, t.Source, t.Assignee))
@@ -213,23 +209,8 @@ func (m *Mind) diagTaskHealth(ctx context.Context, sb *strings.Builder) {
 		// Check for abandoned tasks (blocked, retries exhausted)
 		if status == "blocked" {
 			for _, t := range tasks {
-				retryCount := 0
-				if t.Metadata != nil {
-					switch v := t.Metadata["retry_count"].(type) {
-					case float64:
-						retryCount = int(v)
-					case int:
-						retryCount = v
-					}
-				}
-				if retryCount >= 3 {
-					reason := ""
-					if t.Metadata != nil {
-						if r, ok := t.Metadata["blocked_reason"].(string); ok {
-							reason = r
-						}
-					}
-					sb.WriteString(fmt.Sprintf("  - **ABANDONED**: %q (retries exhausted, reason: %s)\n", t.Subject, reason))
+				if tm := payload.ReadTaskMeta(t.Metadata); tm.RetryCount >= 3 {
+					sb.WriteString(fmt.Sprintf("  - **ABANDONED**: %q (retries exhausted, reason: %s)\n", t.Subject, tm.BlockedReason))
 				}
 			}
 		}
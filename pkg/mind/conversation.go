@@ -0,0 +1,42 @@
+package mind
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/task"
+)
+
+// conversationKey is the context key for the conversation logEvent files
+// events under.
+type conversationKey struct{}
+
+// conversationOf returns the conversation set by taskConversation, or "".
+func conversationOf(ctx context.Context) string {
+	id, _ := ctx.Value(conversationKey{}).(string)
+	return id
+}
+
+// taskConversation returns ctx carrying the conversation for t's work, so
+// everything logged while working on it lands in one thread. A task's
+// conversation has the task's ID; subtasks join their parent's.
+func (m *Mind) taskConversation(ctx context.Context, t *task.Task) context.Context {
+	id, title := t.ID, t.Subject
+	if t.ParentID != "" {
+		id = t.ParentID
+		if parent, err := m.tasks.Get(ctx, t.ParentID); err == nil {
+			title = parent.Subject
+		}
+	}
+	if conversationOf(ctx) == id {
+		return ctx
+	}
+	_, err := m.events.CreateConversation(ctx, id, title, "mind")
+	if err != nil && !errors.Is(err, eventgraph.ErrConversationExists) {
+		log.Printf("mind: create conversation for task %s: %v", t.ID, err)
+		return ctx
+	}
+	return context.WithValue(ctx, conversationKey{}, id)
+}
@@ -17,6 +17,7 @@ import (
 
 	"mind-zero-five/pkg/authority"
 	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/payload"
 	"mind-zero-five/pkg/task"
 )
 
@@ -28,14 +29,18 @@ var (
 	gitCommitAndPushFn = GitCommitAndPush
 )
 
+// consumerName is the durable event-log consumer the mind reads through.
+const consumerName = "mind"
+
 // Mind is the autonomous loop that picks up tasks, invokes Claude Code CLI,
 // builds, commits, deploys, and — when idle — assesses itself for improvements.
 type Mind struct {
-	bus     *eventgraph.Bus
+	bus     eventgraph.EventBus
 	events  eventgraph.EventStore
 	tasks   task.Store
 	auth    authority.Store
-	actorID string // this mind's actor ID, for policy matching
+	actorID string            // this mind's actor ID, for policy matching
+	signer  eventgraph.Signer // signs every event the mind emits; nil leaves them unsigned
 	repoDir string
 
 	// pendingRestart holds the authority request ID when waiting for
@@ -51,15 +56,17 @@ type Mind struct {
 	preflightFailed bool
 }
 
-// New creates a Mind. The Bus is used both as the EventStore (for reading/writing
-// events) and for subscribing to real-time event notifications.
-func New(bus *eventgraph.Bus, tasks task.Store, auth authority.Store, actorID, repoDir string) *Mind {
+// New creates a Mind. The bus is used both as the EventStore (for reading/writing
+// events) and for subscribing to real-time event notifications. The signer
+// should hold the mind actor's key.
+func New(bus eventgraph.EventBus, tasks task.Store, auth authority.Store, actorID string, signer eventgraph.Signer, repoDir string) *Mind {
 	return &Mind{
 		bus:            bus,
-		events:         bus, // Bus satisfies EventStore
+		events:         bus, // EventBus satisfies EventStore
 		tasks:          tasks,
 		auth:           auth,
 		actorID:        actorID,
+		signer:         signer,
 		repoDir:        repoDir,
 		assessInterval: 5 * time.Minute,
 	}
@@ -72,24 +79,24 @@ func (m *Mind) recoverState(ctx context.Context) {
 	// sweeping crash leftovers into the next task's commit.
 	if files, err := CleanWorkingTree(ctx, m.repoDir); err != nil {
 		log.Printf("mind: cleanWorkingTree: %v", err)
-		m.logEvent(ctx, "mind.recovery.dirty_tree_failed", map[string]any{
-			"error": err.Error(),
-			"files": files,
+		m.logEvent(ctx, payload.DirtyTreeFailed{
+			Error: err.Error(),
+			Files: files,
 		}, nil)
 	} else if len(files) > 0 {
 		log.Printf("mind: committed %d orphaned files from crash recovery", len(files))
-		m.logEvent(ctx, "mind.recovery.dirty_tree_cleaned", map[string]any{
-			"file_count": len(files),
-			"files":      files,
+		m.logEvent(ctx, payload.DirtyTreeCleaned{
+			FileCount: len(files),
+			Files:     files,
 		}, nil)
 	}
 
 	pending, err := m.auth.Pending(ctx)
 	if err != nil {
 		log.Printf("mind: recoverState: list pending authority: %v", err)
-		m.logEvent(ctx, "mind.error", map[string]any{
-			"operation": "recoverState.list_pending",
-			"error":     err.Error(),
+		m.logEvent(ctx, payload.MindError{
+			Operation: "recoverState.list_pending",
+			Error:     err.Error(),
 		}, nil)
 		return
 	}
@@ -112,8 +119,8 @@ func (m *Mind) recoverState(ctx context.Context) {
 	}
 
 	if len(recoveredIDs) > 0 {
-		m.logEvent(ctx, "mind.state.recovered", map[string]any{
-			"recovered_ids": recoveredIDs,
+		m.logEvent(ctx, payload.StateRecovered{
+			RecoveredIDs: recoveredIDs,
 		}, nil)
 	}
 }
@@ -125,10 +132,24 @@ func (m *Mind) Run(ctx context.Context) {
 
 	m.recoverState(ctx)
 
-	ch := m.bus.Subscribe()
-	defer m.bus.Unsubscribe(ch)
+	// A durable consumer: events that arrive during a long invocation, or
+	// while the mind is down, are delivered afterwards rather than lost.
+	consumer := eventgraph.NewConsumer(m.bus, consumerName, eventgraph.Filter{Types: []string{
+		payload.TaskCreated{}.EventType(),
+		payload.AuthorityResolved{}.EventType(),
+	}})
+	ch, err := consumer.Start(ctx)
+	for err != nil {
+		log.Printf("mind: start consumer: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+		ch, err = consumer.Start(ctx)
+	}
 
-	// Catch up on anything pending from before startup
+	// Housekeeping from before startup: stale and blocked tasks
 	m.poll(ctx)
 
 	// Maintenance ticker — much slower than old 5s poll, only for housekeeping
@@ -140,8 +161,15 @@ func (m *Mind) Run(ctx context.Context) {
 		case <-ctx.Done():
 			log.Println("mind: shutting down")
 			return
-		case e := <-ch:
+		case e, ok := <-ch:
+			if !ok {
+				log.Println("mind: shutting down")
+				return
+			}
 			m.handleEvent(ctx, e)
+			if err := consumer.Ack(ctx, e); err != nil {
+				log.Printf("mind: ack %s: %v", e.ID, err)
+			}
 		case <-ticker.C:
 			m.maintenance(ctx)
 		}
@@ -151,17 +179,22 @@ func (m *Mind) Run(ctx context.Context) {
 // handleEvent reacts to relevant bus events. Ignores events that don't require action.
 func (m *Mind) handleEvent(ctx context.Context, e *eventgraph.Event) {
 	switch e.Type {
-	case "task.created":
+	case payload.TaskCreated{}.EventType():
 		if err := m.preflight(ctx); err != nil {
 			log.Printf("mind: preflight failed: %v", err)
 			return
 		}
 		m.checkPendingTasks(ctx)
-	case "authority.resolved":
-		if m.pendingRestart != "" {
+	case payload.AuthorityResolved{}.EventType():
+		resolved, err := payload.Decode[payload.AuthorityResolved](e)
+		if err != nil {
+			log.Printf("mind: %v", err)
+			return
+		}
+		if m.pendingRestart != "" && resolved.AuthorityID == m.pendingRestart {
 			m.checkRestart(ctx)
 		}
-		if m.pendingProposal != "" {
+		if m.pendingProposal != "" && resolved.AuthorityID == m.pendingProposal {
 			m.checkProposal(ctx)
 		}
 	}
@@ -172,8 +205,8 @@ func (m *Mind) maintenance(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("mind: panic in maintenance: %v", r)
-			m.logEvent(ctx, "mind.panic", map[string]any{
-				"error": fmt.Sprintf("%v", r),
+			m.logEvent(ctx, payload.MindPanic{
+				Error: fmt.Sprintf("%v", r),
 			}, nil)
 		}
 	}()
@@ -186,7 +219,7 @@ func (m *Mind) maintenance(ctx context.Context) {
 	}
 	if m.preflightFailed {
 		m.preflightFailed = false
-		m.logEvent(ctx, "mind.preflight.restored", map[string]any{}, nil)
+		m.logEvent(ctx, payload.PreflightRestored{}, nil)
 	}
 
 	m.retryBlockedTasks(ctx)
@@ -223,9 +256,9 @@ func (m *Mind) preflight(ctx context.Context) error {
 		// Only emit event on state change to avoid flooding the eventgraph.
 		if !m.preflightFailed {
 			m.preflightFailed = true
-			m.logEvent(ctx, "mind.preflight.failed", map[string]any{
-				"missing": missing,
-				"error":   err.Error(),
+			m.logEvent(ctx, payload.PreflightFailed{
+				Missing: missing,
+				Error:   err.Error(),
 			}, nil)
 		}
 		return err
@@ -238,8 +271,8 @@ func (m *Mind) poll(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("mind: panic in poll: %v", r)
-			m.logEvent(ctx, "mind.panic", map[string]any{
-				"error": fmt.Sprintf("%v", r),
+			m.logEvent(ctx, payload.MindPanic{
+				Error: fmt.Sprintf("%v", r),
 			}, nil)
 		}
 	}()
@@ -270,9 +303,9 @@ func (m *Mind) checkPendingTasks(ctx context.Context) bool {
 	tasks, err := m.tasks.List(ctx, "pending", 10)
 	if err != nil {
 		log.Printf("mind: poll pending tasks: %v", err)
-		m.logEvent(ctx, "mind.error", map[string]any{
-			"operation": "checkPendingTasks",
-			"error":     err.Error(),
+		m.logEvent(ctx, payload.MindError{
+			Operation: "checkPendingTasks",
+			Error:     err.Error(),
 		}, nil)
 		return false
 	}
@@ -294,16 +327,17 @@ func (m *Mind) checkPendingTasks(ctx context.Context) bool {
 			continue
 		}
 
-		claimEvent, _ := m.logEvent(ctx, "task.claimed", map[string]any{
-			"task_id": t.ID,
-			"subject": t.Subject,
+		taskCtx := m.taskConversation(ctx, t)
+		claimEvent, _ := m.logEvent(taskCtx, payload.TaskClaimed{
+			TaskID:  t.ID,
+			Subject: t.Subject,
 		}, nil)
 
 		// Subtasks (have a parent) execute directly — no planning phase
 		if t.ParentID != "" {
-			m.executeSubtask(ctx, t, claimEvent)
+			m.executeSubtask(taskCtx, t, claimEvent)
 		} else {
-			m.executeTask(ctx, t, claimEvent)
+			m.executeTask(taskCtx, t, claimEvent)
 		}
 
 		// Process one task per poll cycle
@@ -348,7 +382,7 @@ func (m *Mind) maybeAssess(ctx context.Context) {
 	m.lastAssessment = time.Now()
 	log.Println("mind: idle — running self-assessment")
 
-	assessEvent, _ := m.logEvent(ctx, "mind.assess.started", map[string]any{}, nil)
+	assessEvent, _ := m.logEvent(ctx, payload.AssessStarted{}, nil)
 
 	causes := []string{}
 	if assessEvent != nil {
@@ -358,24 +392,24 @@ func (m *Mind) maybeAssess(ctx context.Context) {
 	proposal, err := m.Assess(ctx)
 	if err != nil {
 		log.Printf("mind: assessment failed: %v", err)
-		m.logEvent(ctx, "mind.assess.failed", map[string]any{
-			"error": err.Error(),
+		m.logEvent(ctx, payload.AssessFailed{
+			Error: err.Error(),
 		}, causes)
 		return
 	}
 
 	if proposal == nil {
 		log.Println("mind: assessment found no improvements needed")
-		m.logEvent(ctx, "mind.assess.completed", map[string]any{
-			"result": "ok",
+		m.logEvent(ctx, payload.AssessCompleted{
+			Result: "ok",
 		}, causes)
 		return
 	}
 
 	log.Printf("mind: proposing improvement: %s", proposal.Subject)
-	m.logEvent(ctx, "mind.assess.completed", map[string]any{
-		"result":  "proposal",
-		"subject": proposal.Subject,
+	m.logEvent(ctx, payload.AssessCompleted{
+		Result:  "proposal",
+		Subject: proposal.Subject,
 	}, causes)
 
 	// Encode proposal as JSON in the authority request description
@@ -388,10 +422,10 @@ func (m *Mind) maybeAssess(ctx context.Context) {
 		return
 	}
 
-	m.logEvent(ctx, "authority.requested", map[string]any{
-		"authority_id": req.ID,
-		"action":       "self-improve",
-		"subject":      proposal.Subject,
+	m.logEvent(ctx, payload.AuthorityRequested{
+		AuthorityID: req.ID,
+		Action:      "self-improve",
+		Subject:     proposal.Subject,
 	}, causes)
 
 	m.pendingProposal = req.ID
@@ -418,10 +452,10 @@ func (m *Mind) checkProposal(ctx context.Context) {
 				return
 			}
 			log.Printf("mind: auto-approved proposal %s after %s", req.ID, authority.RecommendedTimeout)
-			m.logEvent(ctx, "authority.auto_approved", map[string]any{
-				"authority_id": req.ID,
-				"action":       "self-improve",
-				"timeout":      authority.RecommendedTimeout.String(),
+			m.logEvent(ctx, payload.AuthorityAutoApproved{
+				AuthorityID: req.ID,
+				Action:      "self-improve",
+				Timeout:     authority.RecommendedTimeout.String(),
 			}, nil)
 			req.Status = "approved" // fall through to approved handling
 		} else {
@@ -436,10 +470,10 @@ func (m *Mind) checkProposal(ctx context.Context) {
 		var proposal Proposal
 		if err := json.Unmarshal([]byte(req.Description), &proposal); err != nil {
 			log.Printf("mind: parse proposal from authority %s: %v", req.ID, err)
-			m.logEvent(ctx, "mind.error", map[string]any{
-				"operation":    "checkProposal.parse",
-				"authority_id": req.ID,
-				"error":        err.Error(),
+			m.logEvent(ctx, payload.MindError{
+				Operation:   "checkProposal.parse",
+				AuthorityID: req.ID,
+				Error:       err.Error(),
 			}, nil)
 			m.pendingProposal = ""
 			return
@@ -458,26 +492,26 @@ func (m *Mind) checkProposal(ctx context.Context) {
 		})
 		if err != nil {
 			log.Printf("mind: create improvement task: %v", err)
-			m.logEvent(ctx, "mind.error", map[string]any{
-				"operation":    "checkProposal.create_task",
-				"authority_id": req.ID,
-				"error":        err.Error(),
+			m.logEvent(ctx, payload.MindError{
+				Operation:   "checkProposal.create_task",
+				AuthorityID: req.ID,
+				Error:       err.Error(),
 			}, nil)
 			m.pendingProposal = ""
 			return
 		}
 
-		m.logEvent(ctx, "self-improve.task.created", map[string]any{
-			"task_id":      t.ID,
-			"authority_id": req.ID,
-			"subject":      proposal.Subject,
+		m.logEvent(ctx, payload.SelfImproveTaskCreated{
+			TaskID:      t.ID,
+			AuthorityID: req.ID,
+			Subject:     proposal.Subject,
 		}, nil)
 
 		log.Printf("mind: created improvement task %s: %s", t.ID, proposal.Subject)
 	} else {
 		log.Printf("mind: improvement rejected (authority %s)", req.ID)
-		m.logEvent(ctx, "self-improve.rejected", map[string]any{
-			"authority_id": req.ID,
+		m.logEvent(ctx, payload.SelfImproveRejected{
+			AuthorityID: req.ID,
 		}, nil)
 	}
 
@@ -499,35 +533,35 @@ func (m *Mind) executeTask(ctx context.Context, t *task.Task, causeEvent *eventg
 	}
 
 	// --- PLAN ---
-	m.logEvent(ctx, "mind.plan.started", map[string]any{
-		"task_id": t.ID,
-		"subject": t.Subject,
+	m.logEvent(ctx, payload.PlanStarted{
+		TaskID:  t.ID,
+		Subject: t.Subject,
 	}, causes)
 
 	subtaskSpecs, err := m.Plan(ctx, t)
 	if err != nil {
 		if errors.Is(err, ErrAlreadyDone) {
 			log.Printf("mind: task %s already done, auto-completing", t.ID)
-			m.logEvent(ctx, "mind.plan.already_done", map[string]any{
-				"task_id": t.ID,
-				"subject": t.Subject,
+			m.logEvent(ctx, payload.PlanAlreadyDone{
+				TaskID:  t.ID,
+				Subject: t.Subject,
 			}, causes)
 			m.finishTask(ctx, t, causes)
 			return
 		}
 		log.Printf("mind: plan failed for task %s: %v", t.ID, err)
 		// Fall back to direct execution (single-shot, like before)
-		m.logEvent(ctx, "mind.plan.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   err.Error(),
+		m.logEvent(ctx, payload.PlanFailed{
+			TaskID: t.ID,
+			Error:  err.Error(),
 		}, causes)
 		m.executeDirectly(ctx, t, causes)
 		return
 	}
 
-	planEvent, _ := m.logEvent(ctx, "mind.plan.completed", map[string]any{
-		"task_id":       t.ID,
-		"subtask_count": len(subtaskSpecs),
+	planEvent, _ := m.logEvent(ctx, payload.PlanCompleted{
+		TaskID:       t.ID,
+		SubtaskCount: len(subtaskSpecs),
 	}, causes)
 
 	planCauses := causes
@@ -552,26 +586,26 @@ func (m *Mind) executeTask(ctx context.Context, t *task.Task, causeEvent *eventg
 	// --- REVIEW (max 2 rounds) ---
 	reviewCauses := planCauses
 	for round := 0; round < 2; round++ {
-		m.logEvent(ctx, "mind.review.started", map[string]any{
-			"task_id": t.ID,
-			"round":   round + 1,
+		m.logEvent(ctx, payload.ReviewStarted{
+			TaskID: t.ID,
+			Round:  round + 1,
 		}, reviewCauses)
 
 		issues, err := m.Review(ctx, t, startCommit)
 		if err != nil {
 			log.Printf("mind: review failed for task %s: %v", t.ID, err)
-			m.logEvent(ctx, "mind.review.failed", map[string]any{
-				"task_id": t.ID,
-				"error":   err.Error(),
+			m.logEvent(ctx, payload.ReviewFailed{
+				TaskID: t.ID,
+				Error:  err.Error(),
 			}, reviewCauses)
 			break // proceed to finish — review failure shouldn't block
 		}
 
-		reviewEvent, _ := m.logEvent(ctx, "mind.review.completed", map[string]any{
-			"task_id":     t.ID,
-			"round":       round + 1,
-			"issue_count": len(issues),
-			"clean":       len(issues) == 0,
+		reviewEvent, _ := m.logEvent(ctx, payload.ReviewCompleted{
+			TaskID:     t.ID,
+			Round:      round + 1,
+			IssueCount: len(issues),
+			Clean:      len(issues) == 0,
 		}, reviewCauses)
 
 		if len(issues) == 0 {
@@ -608,16 +642,14 @@ func (m *Mind) executeDirectly(ctx context.Context, t *task.Task, causes []strin
 	prompt += "\nAfter making changes, verify with: go build ./... && go test ./...\n"
 	prompt += "Do NOT commit — just make the code changes and verify they build.\n"
 
-	if t.Metadata != nil {
-		if reason, ok := t.Metadata["prev_failure_reason"].(string); ok && reason != "" {
-			prompt = "IMPORTANT — Previous attempt failed with: " + reason + ". Avoid repeating this mistake.\n\n" + prompt
-		}
+	if reason := payload.ReadTaskMeta(t.Metadata).PrevFailureReason; reason != "" {
+		prompt = "IMPORTANT — Previous attempt failed with: " + reason + ". Avoid repeating this mistake.\n\n" + prompt
 	}
 
-	invokeEvent, _ := m.logEvent(ctx, "mind.claude.invoked", map[string]any{
-		"task_id": t.ID,
-		"prompt":  truncate(prompt, 500),
-		"mode":    "direct",
+	invokeEvent, _ := m.logEvent(ctx, payload.ClaudeInvoked{
+		TaskID: t.ID,
+		Prompt: truncate(prompt, 500),
+		Mode:   "direct",
 	}, causes)
 
 	invokeCauses := causes
@@ -627,19 +659,19 @@ func (m *Mind) executeDirectly(ctx context.Context, t *task.Task, causes []strin
 
 	result, err := InvokeClaude(ctx, m.repoDir, prompt, "")
 	if err != nil {
-		m.logEvent(ctx, "mind.claude.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   err.Error(),
+		m.logEvent(ctx, payload.ClaudeFailed{
+			TaskID: t.ID,
+			Error:  err.Error(),
 		}, invokeCauses)
 		m.handleFailure(ctx, t, "mind.claude.failed", "claude invocation failed: "+err.Error(), invokeCauses)
 		return
 	}
 
-	completedEvent, _ := m.logEvent(ctx, "mind.claude.completed", map[string]any{
-		"task_id":   t.ID,
-		"exit_code": result.ExitCode,
-		"duration":  result.Duration.String(),
-		"result":    truncate(result.Result, 1000),
+	completedEvent, _ := m.logEvent(ctx, payload.ClaudeCompleted{
+		TaskID:   t.ID,
+		ExitCode: result.ExitCode,
+		Duration: result.Duration.String(),
+		Result:   truncate(result.Result, 1000),
 	}, invokeCauses)
 
 	completedCauses := invokeCauses
@@ -653,9 +685,9 @@ func (m *Mind) executeDirectly(ctx context.Context, t *task.Task, causes []strin
 	}
 
 	if err := BuildAndTest(ctx, m.repoDir); err != nil {
-		m.logEvent(ctx, "build.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   truncate(err.Error(), 1000),
+		m.logEvent(ctx, payload.BuildFailed{
+			TaskID: t.ID,
+			Error:  truncate(err.Error(), 1000),
 		}, completedCauses)
 		m.handleFailure(ctx, t, "build.failed", "build/test failed: "+truncate(err.Error(), 200), completedCauses)
 		return
@@ -671,10 +703,10 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 		causes = []string{causeEvent.ID}
 	}
 
-	m.logEvent(ctx, "mind.subtask.started", map[string]any{
-		"task_id":   t.ID,
-		"parent_id": t.ParentID,
-		"subject":   t.Subject,
+	m.logEvent(ctx, payload.SubtaskStarted{
+		TaskID:   t.ID,
+		ParentID: t.ParentID,
+		Subject:  t.Subject,
 	}, causes)
 
 	// Determine model from metadata
@@ -693,16 +725,14 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 	prompt += "After making changes, verify with: go build ./... && go test ./...\n"
 	prompt += "Do NOT commit — just make the code changes and verify they build.\n"
 
-	if t.Metadata != nil {
-		if reason, ok := t.Metadata["prev_failure_reason"].(string); ok && reason != "" {
-			prompt = "IMPORTANT — Previous attempt failed with: " + reason + ". Avoid repeating this mistake.\n\n" + prompt
-		}
+	if reason := payload.ReadTaskMeta(t.Metadata).PrevFailureReason; reason != "" {
+		prompt = "IMPORTANT — Previous attempt failed with: " + reason + ". Avoid repeating this mistake.\n\n" + prompt
 	}
 
-	invokeEvent, _ := m.logEvent(ctx, "mind.claude.invoked", map[string]any{
-		"task_id": t.ID,
-		"model":   model,
-		"prompt":  truncate(prompt, 500),
+	invokeEvent, _ := m.logEvent(ctx, payload.ClaudeInvoked{
+		TaskID: t.ID,
+		Model:  model,
+		Prompt: truncate(prompt, 500),
 	}, causes)
 
 	invokeCauses := causes
@@ -712,19 +742,19 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 
 	result, err := invokeClaudeFn(ctx, m.repoDir, prompt, model)
 	if err != nil {
-		m.logEvent(ctx, "mind.claude.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   err.Error(),
+		m.logEvent(ctx, payload.ClaudeFailed{
+			TaskID: t.ID,
+			Error:  err.Error(),
 		}, invokeCauses)
 		m.handleFailure(ctx, t, "mind.claude.failed", "claude failed: "+err.Error(), invokeCauses)
 		return
 	}
 
-	completedEvent, _ := m.logEvent(ctx, "mind.claude.completed", map[string]any{
-		"task_id":   t.ID,
-		"exit_code": result.ExitCode,
-		"duration":  result.Duration.String(),
-		"result":    truncate(result.Result, 1000),
+	completedEvent, _ := m.logEvent(ctx, payload.ClaudeCompleted{
+		TaskID:   t.ID,
+		ExitCode: result.ExitCode,
+		Duration: result.Duration.String(),
+		Result:   truncate(result.Result, 1000),
 	}, invokeCauses)
 
 	completedCauses := invokeCauses
@@ -737,8 +767,8 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 		retryPrompt := fmt.Sprintf("The previous attempt failed (exit code %d).\n\nOriginal task: %s\n\nOutput:\n%s\n\nPlease fix and verify with: go build ./... && go test ./...",
 			result.ExitCode, t.Subject, truncate(result.Result, 2000))
 
-		m.logEvent(ctx, "mind.claude.retry", map[string]any{
-			"task_id": t.ID,
+		m.logEvent(ctx, payload.ClaudeRetry{
+			TaskID: t.ID,
 		}, completedCauses)
 
 		result, err = invokeClaudeFn(ctx, m.repoDir, retryPrompt, model)
@@ -754,9 +784,9 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 
 	// Build and test
 	if err := buildAndTestFn(ctx, m.repoDir); err != nil {
-		m.logEvent(ctx, "build.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   truncate(err.Error(), 1000),
+		m.logEvent(ctx, payload.BuildFailed{
+			TaskID: t.ID,
+			Error:  truncate(err.Error(), 1000),
 		}, completedCauses)
 		m.handleFailure(ctx, t, "build.failed", "build/test failed: "+truncate(err.Error(), 200), completedCauses)
 		return
@@ -769,17 +799,17 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 			// No-op: nothing to commit, continue normally
 		} else {
 			log.Printf("mind: git commit/push for subtask %s: %v", t.ID, err)
-			m.logEvent(ctx, "git.commit_push.failed", map[string]any{
-				"task_id": t.ID,
-				"error":   truncate(err.Error(), 500),
+			m.logEvent(ctx, payload.CommitPushFailed{
+				TaskID: t.ID,
+				Error:  truncate(err.Error(), 500),
 			}, completedCauses)
 			m.handleFailure(ctx, t, "git.commit_push.failed", "git push failed — unpushed work at risk: "+truncate(err.Error(), 200), completedCauses)
 			return
 		}
 	} else {
-		m.logEvent(ctx, "code.committed", map[string]any{
-			"task_id": t.ID,
-			"message": commitMsg,
+		m.logEvent(ctx, payload.CodeCommitted{
+			TaskID:  t.ID,
+			Message: commitMsg,
 		}, completedCauses)
 	}
 
@@ -788,10 +818,10 @@ func (m *Mind) executeSubtask(ctx context.Context, t *task.Task, causeEvent *eve
 		log.Printf("mind: complete subtask %s: %v", t.ID, err)
 	}
 
-	m.logEvent(ctx, "mind.subtask.completed", map[string]any{
-		"task_id":   t.ID,
-		"parent_id": t.ParentID,
-		"subject":   t.Subject,
+	m.logEvent(ctx, payload.SubtaskCompleted{
+		TaskID:   t.ID,
+		ParentID: t.ParentID,
+		Subject:  t.Subject,
 	}, completedCauses)
 }
 
@@ -843,9 +873,9 @@ func (m *Mind) implementSubtasks(ctx context.Context, parentID string, subtaskID
 			continue
 		}
 
-		claimEvent, _ := m.logEvent(ctx, "task.claimed", map[string]any{
-			"task_id": stID,
-			"subject": st.Subject,
+		claimEvent, _ := m.logEvent(ctx, payload.TaskClaimed{
+			TaskID:  stID,
+			Subject: st.Subject,
 		}, causes)
 
 		m.executeSubtask(ctx, st, claimEvent)
@@ -874,18 +904,18 @@ func (m *Mind) finishTask(ctx context.Context, t *task.Task, causes []string) {
 			log.Printf("mind: task %s: nothing to push (already clean)", t.ID)
 		} else {
 			log.Printf("mind: final commit/push for task %s: %v", t.ID, err)
-			m.logEvent(ctx, "git.commit_push.failed", map[string]any{
-				"task_id": t.ID,
-				"error":   truncate(err.Error(), 500),
+			m.logEvent(ctx, payload.CommitPushFailed{
+				TaskID: t.ID,
+				Error:  truncate(err.Error(), 500),
 			}, causes)
 			// Block — do NOT complete or restart. Unpushed work dies on restart.
 			m.handleFailure(ctx, t, "git.commit_push.failed", "git push failed — unpushed work at risk: "+truncate(err.Error(), 200), causes)
 			return
 		}
 	} else {
-		m.logEvent(ctx, "code.committed", map[string]any{
-			"task_id": t.ID,
-			"message": commitMsg,
+		m.logEvent(ctx, payload.CodeCommitted{
+			TaskID:  t.ID,
+			Message: commitMsg,
 		}, causes)
 	}
 
@@ -893,23 +923,23 @@ func (m *Mind) finishTask(ctx context.Context, t *task.Task, causes []string) {
 	if _, err := m.tasks.Complete(ctx, t.ID); err != nil {
 		log.Printf("mind: complete task %s: %v", t.ID, err)
 	}
-	m.logEvent(ctx, "task.completed", map[string]any{
-		"task_id": t.ID,
-		"subject": t.Subject,
+	m.logEvent(ctx, payload.TaskCompleted{
+		TaskID:  t.ID,
+		Subject: t.Subject,
 	}, causes)
 
 	// Build deployment binaries
 	if err := Build(ctx, m.repoDir); err != nil {
 		log.Printf("mind: build deploy binaries for task %s: %v", t.ID, err)
-		m.logEvent(ctx, "build.deploy.failed", map[string]any{
-			"task_id": t.ID,
-			"error":   truncate(err.Error(), 500),
+		m.logEvent(ctx, payload.BuildDeployFailed{
+			TaskID: t.ID,
+			Error:  truncate(err.Error(), 500),
 		}, causes)
 		return
 	}
 
-	m.logEvent(ctx, "build.completed", map[string]any{
-		"task_id": t.ID,
+	m.logEvent(ctx, payload.BuildCompleted{
+		TaskID: t.ID,
 	}, causes)
 
 	// Skip restart — run single-threaded. New binaries take effect on next
@@ -926,10 +956,10 @@ func (m *Mind) requestRestart(ctx context.Context, t *task.Task, causes []string
 		return
 	}
 
-	reqEvent, _ := m.logEvent(ctx, "authority.requested", map[string]any{
-		"task_id":      t.ID,
-		"authority_id": req.ID,
-		"action":       "restart",
+	reqEvent, _ := m.logEvent(ctx, payload.AuthorityRequested{
+		TaskID:      t.ID,
+		AuthorityID: req.ID,
+		Action:      "restart",
 	}, causes)
 
 	reqCauses := causes
@@ -953,10 +983,10 @@ func (m *Mind) requestRestart(ctx context.Context, t *task.Task, causes []string
 			return
 		}
 		log.Printf("mind: self-approved restart (policy: %s)", policy.Action)
-		m.logEvent(ctx, "authority.self_approved", map[string]any{
-			"authority_id": req.ID,
-			"policy_id":    policy.ID,
-			"action":       "restart",
+		m.logEvent(ctx, payload.AuthoritySelfApproved{
+			AuthorityID: req.ID,
+			PolicyID:    policy.ID,
+			Action:      "restart",
 		}, reqCauses)
 
 		m.doRestart(ctx, req.ID, reqCauses)
@@ -968,46 +998,42 @@ func (m *Mind) requestRestart(ctx context.Context, t *task.Task, causes []string
 }
 
 func (m *Mind) doRestart(ctx context.Context, authID string, causes []string) {
-	m.logEvent(ctx, "deploy.started", map[string]any{
-		"authority_id": authID,
+	m.logEvent(ctx, payload.DeployStarted{
+		AuthorityID: authID,
 	}, causes)
 
 	if err := RestartSelf(); err != nil {
 		log.Printf("mind: restart failed: %v", err)
-		m.logEvent(ctx, "deploy.failed", map[string]any{
-			"authority_id": authID,
-			"error":        err.Error(),
+		m.logEvent(ctx, payload.DeployFailed{
+			AuthorityID: authID,
+			Error:       err.Error(),
 		}, causes)
 	}
 }
 
 func (m *Mind) markBlocked(ctx context.Context, taskID, reason string, causes []string) {
 	// Read existing metadata to preserve retry_count and other fields.
-	meta := map[string]any{}
-	if t, err := m.tasks.Get(ctx, taskID); err == nil && t.Metadata != nil {
-		for k, v := range t.Metadata {
-			meta[k] = v
-		}
-	}
-	meta["blocked_reason"] = reason
-	if _, exists := meta["retry_count"]; !exists {
-		meta["retry_count"] = 0
+	var meta map[string]any
+	if t, err := m.tasks.Get(ctx, taskID); err == nil {
+		meta = t.Metadata
 	}
+	tm := payload.ReadTaskMeta(meta)
+	tm.BlockedReason = reason
 
 	if _, err := m.tasks.Update(ctx, taskID, map[string]any{
 		"status":   "blocked",
-		"metadata": meta,
+		"metadata": tm.Merge(meta),
 	}); err != nil {
 		log.Printf("mind: mark task %s blocked: %v", taskID, err)
-		m.logEvent(ctx, "mind.error", map[string]any{
-			"operation": "markBlocked",
-			"task_id":   taskID,
-			"error":     err.Error(),
+		m.logEvent(ctx, payload.MindError{
+			Operation: "markBlocked",
+			TaskID:    taskID,
+			Error:     err.Error(),
 		}, causes)
 	}
-	m.logEvent(ctx, "task.blocked", map[string]any{
-		"task_id": taskID,
-		"reason":  reason,
+	m.logEvent(ctx, payload.TaskBlocked{
+		TaskID: taskID,
+		Reason: reason,
 	}, causes)
 }
 
@@ -1035,22 +1061,22 @@ func (m *Mind) recoverStaleTasks(ctx context.Context) bool {
 		}
 
 		// Build updated metadata, preserving existing fields
-		meta := copyMeta(t.Metadata)
-		meta["prev_failure_reason"] = "stale in_progress — recovered automatically"
+		tm := payload.ReadTaskMeta(t.Metadata)
+		tm.PrevFailureReason = "stale in_progress — recovered automatically"
 
 		if _, err := m.tasks.Update(ctx, t.ID, map[string]any{
 			"status":   "pending",
 			"assignee": "",
-			"metadata": meta,
+			"metadata": tm.Merge(t.Metadata),
 		}); err != nil {
 			log.Printf("mind: recover stale task %s: %v", t.ID, err)
 			continue
 		}
 
-		m.logEvent(ctx, "task.stale.recovered", map[string]any{
-			"task_id":   t.ID,
-			"subject":   t.Subject,
-			"stale_for": time.Since(t.UpdatedAt).Round(time.Minute).String(),
+		m.logEvent(ctx, payload.TaskStaleRecovered{
+			TaskID:   t.ID,
+			Subject:  t.Subject,
+			StaleFor: time.Since(t.UpdatedAt).Round(time.Minute).String(),
 		}, nil)
 
 		log.Printf("mind: recovered stale in_progress task %s: %s", t.ID, t.Subject)
@@ -1078,16 +1104,8 @@ func (m *Mind) retryBlockedTasks(ctx context.Context) bool {
 			continue
 		}
 
-		// Read retry_count from metadata (default 0)
-		retryCount := 0
-		if t.Metadata != nil {
-			switch v := t.Metadata["retry_count"].(type) {
-			case int:
-				retryCount = v
-			case float64:
-				retryCount = int(v)
-			}
-		}
+		tm := payload.ReadTaskMeta(t.Metadata)
+		retryCount := tm.RetryCount
 		if retryCount >= 3 {
 			continue
 		}
@@ -1098,32 +1116,25 @@ func (m *Mind) retryBlockedTasks(ctx context.Context) bool {
 			continue // updated too recently
 		}
 
-		// Build updated metadata, preserving existing fields
-		meta := map[string]any{}
-		if t.Metadata != nil {
-			for k, v := range t.Metadata {
-				meta[k] = v
-			}
-		}
 		// Copy blocked_reason to prev_failure_reason before clearing
-		if br, ok := meta["blocked_reason"].(string); ok && br != "" {
-			meta["prev_failure_reason"] = br
+		if tm.BlockedReason != "" {
+			tm.PrevFailureReason = tm.BlockedReason
 		}
-		meta["retry_count"] = retryCount + 1
+		tm.RetryCount = retryCount + 1
 
 		if _, err := m.tasks.Update(ctx, t.ID, map[string]any{
 			"status":   "pending",
 			"assignee": "",
-			"metadata": meta,
+			"metadata": tm.Merge(t.Metadata),
 		}); err != nil {
 			log.Printf("mind: retry task %s: %v", t.ID, err)
 			continue
 		}
 
-		m.logEvent(ctx, "task.retried", map[string]any{
-			"task_id":     t.ID,
-			"subject":     t.Subject,
-			"retry_count": retryCount + 1,
+		m.logEvent(ctx, payload.TaskRetried{
+			TaskID:     t.ID,
+			Subject:    t.Subject,
+			RetryCount: retryCount + 1,
 		}, nil)
 
 		log.Printf("mind: retried task %s (retry %d): %s", t.ID, retryCount+1, t.Subject)
@@ -1133,10 +1144,12 @@ func (m *Mind) retryBlockedTasks(ctx context.Context) bool {
 	return retried
 }
 
-func (m *Mind) logEvent(ctx context.Context, eventType string, content map[string]any, causes []string) (*eventgraph.Event, error) {
-	e, err := m.events.Append(ctx, eventType, "mind", content, causes, "")
+// logEvent appends p as an event from the mind, signed with its key. Errors
+// are logged; callers only need the event to link later events to it.
+func (m *Mind) logEvent(ctx context.Context, p payload.Payload, causes []string) (*eventgraph.Event, error) {
+	e, err := payload.Append(ctx, m.events, "mind", p, causes, conversationOf(ctx), m.signer)
 	if err != nil {
-		log.Printf("mind: log event %s: %v", eventType, err)
+		log.Printf("mind: log event %s: %v", p.EventType(), err)
 	}
 	return e, err
 }
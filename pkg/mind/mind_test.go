@@ -11,6 +11,7 @@ import (
 
 	"mind-zero-five/pkg/authority"
 	"mind-zero-five/pkg/eventgraph"
+	"mind-zero-five/pkg/page"
 	"mind-zero-five/pkg/task"
 )
 
@@ -86,6 +87,11 @@ func (s *mockTaskStore) List(_ context.Context, status string, limit int) ([]tas
 	return result, nil
 }
 
+func (s *mockTaskStore) ListPage(ctx context.Context, status, cursor string, limit int) (*page.Page[task.Task], error) {
+	tasks, err := s.List(ctx, status, limit)
+	return &page.Page[task.Task]{Items: tasks}, err
+}
+
 func (s *mockTaskStore) ByParent(_ context.Context, parentID string) ([]task.Task, error) {
 	var result []task.Task
 	for _, t := range s.tasks {
@@ -98,56 +104,26 @@ func (s *mockTaskStore) ByParent(_ context.Context, parentID string) ([]task.Tas
 
 func (s *mockTaskStore) Count(_ context.Context) (int, error)        { return len(s.tasks), nil }
 func (s *mockTaskStore) PendingCount(_ context.Context) (int, error) { return 0, nil }
-func (s *mockTaskStore) EnsureTable(_ context.Context) error         { return nil }
-
-// --- Mock event store ---
+func (s *mockTaskStore) Import(_ context.Context, _ *task.Task) error {
+	return nil
+}
 
-type mockEventStore struct{}
+// --- Event stores ---
 
-// trackingEventStore wraps mockEventStore and records the type of every appended event.
+// trackingEventStore wraps an in-memory store and records the type of every appended event.
 type trackingEventStore struct {
-	mockEventStore
+	*eventgraph.MemStore
 	emitted []string
 }
 
-func (s *trackingEventStore) Append(_ context.Context, eventType, source string, content map[string]any, causes []string, conversationID string) (*eventgraph.Event, error) {
-	s.emitted = append(s.emitted, eventType)
-	return &eventgraph.Event{ID: "evt-1", Type: eventType}, nil
+func newTrackingEventStore() *trackingEventStore {
+	return &trackingEventStore{MemStore: eventgraph.NewMemStore(nil, eventgraph.CausesStrict)}
 }
 
-func (s *mockEventStore) Append(_ context.Context, eventType, source string, content map[string]any, causes []string, conversationID string) (*eventgraph.Event, error) {
-	return &eventgraph.Event{ID: "evt-1", Type: eventType}, nil
-}
-func (s *mockEventStore) Get(_ context.Context, id string) (*eventgraph.Event, error) { return nil, nil }
-func (s *mockEventStore) Recent(_ context.Context, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) ByType(_ context.Context, eventType string, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) BySource(_ context.Context, source string, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) ByConversation(_ context.Context, conversationID string, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) Since(_ context.Context, afterID string, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) Count(_ context.Context) (int, error)      { return 0, nil }
-func (s *mockEventStore) VerifyChain(_ context.Context) error        { return nil }
-func (s *mockEventStore) EnsureTable(_ context.Context) error        { return nil }
-func (s *mockEventStore) Ancestors(_ context.Context, id string, maxDepth int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) Descendants(_ context.Context, id string, maxDepth int) ([]eventgraph.Event, error) {
-	return nil, nil
-}
-func (s *mockEventStore) Search(_ context.Context, query string, limit int) ([]eventgraph.Event, error) {
-	return nil, nil
+func (s *trackingEventStore) Append(ctx context.Context, eventType, source string, content map[string]any, causes []string, conversationID string, signer eventgraph.Signer) (*eventgraph.Event, error) {
+	s.emitted = append(s.emitted, eventType)
+	return s.MemStore.Append(ctx, eventType, source, content, causes, conversationID, signer)
 }
-func (s *mockEventStore) DistinctTypes(_ context.Context) ([]string, error)   { return nil, nil }
-func (s *mockEventStore) DistinctSources(_ context.Context) ([]string, error) { return nil, nil }
 
 // --- Mock authority store ---
 
@@ -166,7 +142,10 @@ func (s *mockAuthStore) Pending(_ context.Context) ([]authority.Request, error)
 func (s *mockAuthStore) Recent(_ context.Context, limit int) ([]authority.Request, error) {
 	return nil, nil
 }
-func (s *mockAuthStore) PendingCount(_ context.Context) (int, error)     { return 0, nil }
+func (s *mockAuthStore) RecentPage(_ context.Context, cursor string, limit int) (*page.Page[authority.Request], error) {
+	return &page.Page[authority.Request]{}, nil
+}
+func (s *mockAuthStore) PendingCount(_ context.Context) (int, error) { return 0, nil }
 func (s *mockAuthStore) CreatePolicy(_ context.Context, action, approverID string, level authority.Level) (*authority.Policy, error) {
 	return nil, nil
 }
@@ -174,7 +153,12 @@ func (s *mockAuthStore) MatchPolicy(_ context.Context, action string) (*authorit
 	return nil, nil
 }
 func (s *mockAuthStore) ListPolicies(_ context.Context) ([]authority.Policy, error) { return nil, nil }
-func (s *mockAuthStore) EnsureTable(_ context.Context) error                        { return nil }
+func (s *mockAuthStore) ImportRequest(_ context.Context, _ *authority.Request) error {
+	return nil
+}
+func (s *mockAuthStore) ImportPolicy(_ context.Context, _ *authority.Policy) error {
+	return nil
+}
 
 // mockAuthStoreWithPending extends mockAuthStore with a configurable Pending list.
 type mockAuthStoreWithPending struct {
@@ -201,7 +185,7 @@ func (s *trackingAuthStore) Create(_ context.Context, action, description, sourc
 
 func newTestMind(ts task.Store) *Mind {
 	return &Mind{
-		events:         &mockEventStore{},
+		events:         eventgraph.NewMemStore(nil, eventgraph.CausesStrict),
 		tasks:          ts,
 		auth:           &mockAuthStore{},
 		actorID:        "mind",
@@ -489,7 +473,7 @@ func TestRecoverStateSetsFields(t *testing.T) {
 		},
 	}
 	m := &Mind{
-		events:         &mockEventStore{},
+		events:         eventgraph.NewMemStore(nil, eventgraph.CausesStrict),
 		tasks:          newMockTaskStore(),
 		auth:           auth,
 		actorID:        "mind",
@@ -604,7 +588,7 @@ func TestPreflightDebounce(t *testing.T) {
 	}
 	t.Setenv("PATH", dir)
 
-	tracker := &trackingEventStore{}
+	tracker := newTrackingEventStore()
 	m := &Mind{
 		events:         tracker,
 		tasks:          newMockTaskStore(),
@@ -653,7 +637,7 @@ func TestPreflightRestoredInMaintenance(t *testing.T) {
 	t.Setenv("PATH", dir)
 
 	m := &Mind{
-		events:          &mockEventStore{},
+		events:          eventgraph.NewMemStore(nil, eventgraph.CausesStrict),
 		tasks:           newMockTaskStore(),
 		auth:            &mockAuthStore{},
 		actorID:         "mind",
@@ -676,7 +660,7 @@ func TestRecoverStateNoMatch(t *testing.T) {
 	// No pending requests at all.
 	auth := &mockAuthStoreWithPending{pending: nil}
 	m := &Mind{
-		events:         &mockEventStore{},
+		events:         eventgraph.NewMemStore(nil, eventgraph.CausesStrict),
 		tasks:          newMockTaskStore(),
 		auth:           auth,
 		actorID:        "mind",
@@ -700,7 +684,7 @@ func TestRecoverStateNoMatch(t *testing.T) {
 		},
 	}
 	m2 := &Mind{
-		events:         &mockEventStore{},
+		events:         eventgraph.NewMemStore(nil, eventgraph.CausesStrict),
 		tasks:          newMockTaskStore(),
 		auth:           auth2,
 		actorID:        "mind",
@@ -784,6 +768,47 @@ func TestExecuteSubtaskContinuesOnNothingToPush(t *testing.T) {
 	}
 }
 
+// TestCheckPendingTasksThreadsConversation verifies that everything logged
+// while working on a subtask lands in its parent task's conversation.
+func TestCheckPendingTasksThreadsConversation(t *testing.T) {
+	origGit, origInvoke, origBuild := gitCommitAndPushFn, invokeClaudeFn, buildAndTestFn
+	defer restoreGitFns(origGit, origInvoke, origBuild)
+
+	invokeClaudeFn = func(_ context.Context, _, _, _ string) (*ClaudeResult, error) {
+		return &ClaudeResult{ExitCode: 0, Result: "ok"}, nil
+	}
+	buildAndTestFn = func(_ context.Context, _ string) error { return nil }
+	gitCommitAndPushFn = func(_ context.Context, _, _ string) error { return ErrNothingToPush }
+
+	ts := newMockTaskStore()
+	addTask(ts, "parent-conv", "in_progress", "mind", time.Now(), nil)
+	addTask(ts, "st-conv", "pending", "", time.Now(), nil)
+	ts.tasks["st-conv"].ParentID = "parent-conv"
+
+	m := newTestMind(ts)
+	if !m.checkPendingTasks(context.Background()) {
+		t.Fatal("expected the pending subtask to be picked up")
+	}
+
+	ctx := context.Background()
+	conv, err := m.events.GetConversation(ctx, "parent-conv")
+	if err != nil {
+		t.Fatalf("parent conversation: %v", err)
+	}
+	if conv.Title != "parent-conv" || conv.CreatedBy != "mind" {
+		t.Errorf("conversation = %+v", conv)
+	}
+	events, _ := m.events.Recent(ctx, 50)
+	if len(events) == 0 || conv.EventCount != len(events) {
+		t.Errorf("conversation holds %d of %d events", conv.EventCount, len(events))
+	}
+	for _, e := range events {
+		if e.ConversationID != "parent-conv" {
+			t.Errorf("%s logged in conversation %q, want parent-conv", e.Type, e.ConversationID)
+		}
+	}
+}
+
 // TestFinishTaskBlocksOnGitError verifies that finishTask marks the task blocked
 // and does not complete or request restart when GitCommitAndPush fails.
 func TestFinishTaskBlocksOnGitError(t *testing.T) {
@@ -883,7 +908,7 @@ func TestFinishTaskContinuesOnNothingToPush(t *testing.T) {
 // immediately without invoking Assess or creating an authority request when
 // pendingProposal is already set.
 func TestMaybeAssessGuardWhenPendingProposalSet(t *testing.T) {
-	tracker := &trackingEventStore{}
+	tracker := newTrackingEventStore()
 	authTracker := &trackingAuthStore{}
 
 	m := &Mind{
@@ -893,7 +918,7 @@ func TestMaybeAssessGuardWhenPendingProposalSet(t *testing.T) {
 		actorID:         "mind",
 		repoDir:         "/tmp",
 		assessInterval:  1 * time.Millisecond, // very short — assessment would be due immediately
-		lastAssessment:  time.Time{},           // zero — would trigger assessment if not guarded
+		lastAssessment:  time.Time{},          // zero — would trigger assessment if not guarded
 		pendingProposal: "existing-proposal-id",
 	}
 
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"mind-zero-five/pkg/payload"
 	"mind-zero-five/pkg/task"
 )
 
@@ -42,21 +43,19 @@ Reason: %s
 // Returns true if recovery succeeded (build/test pass after fix).
 func (m *Mind) attemptRecovery(ctx context.Context, t *task.Task, errorType, reason string, causes []string) bool {
 	// Guard: one recovery attempt per task per failure cycle.
-	if t.Metadata != nil {
-		if attempted, ok := t.Metadata["recovery_attempted"].(bool); ok && attempted {
-			return false
-		}
+	tm := payload.ReadTaskMeta(t.Metadata)
+	if tm.RecoveryAttempted {
+		return false
 	}
 
 	// Mark that we're attempting recovery (prevents loops on retry).
-	meta := copyMeta(t.Metadata)
-	meta["recovery_attempted"] = true
-	m.tasks.Update(ctx, t.ID, map[string]any{"metadata": meta})
-
-	startEvent, _ := m.logEvent(ctx, "mind.recovery.started", map[string]any{
-		"task_id":    t.ID,
-		"error_type": errorType,
-		"reason":     truncate(reason, 500),
+	tm.RecoveryAttempted = true
+	m.tasks.Update(ctx, t.ID, map[string]any{"metadata": tm.Merge(t.Metadata)})
+
+	startEvent, _ := m.logEvent(ctx, payload.RecoveryStarted{
+		TaskID:    t.ID,
+		ErrorType: errorType,
+		Reason:    truncate(reason, 500),
 	}, causes)
 
 	recoveryCauses := causes
@@ -78,21 +77,21 @@ func (m *Mind) attemptRecovery(ctx context.Context, t *task.Task, errorType, rea
 	result, err := InvokeClaude(ctx, m.repoDir, prompt, "sonnet")
 	if err != nil {
 		log.Printf("mind: recovery invocation failed for task %s: %v", t.ID, err)
-		m.logEvent(ctx, "mind.recovery.failed", map[string]any{
-			"task_id": t.ID,
-			"stage":   "invocation",
-			"error":   err.Error(),
+		m.logEvent(ctx, payload.RecoveryFailed{
+			TaskID: t.ID,
+			Stage:  "invocation",
+			Error:  err.Error(),
 		}, recoveryCauses)
 		return false
 	}
 
 	if result.ExitCode != 0 {
 		log.Printf("mind: recovery claude exited %d for task %s", result.ExitCode, t.ID)
-		m.logEvent(ctx, "mind.recovery.failed", map[string]any{
-			"task_id":   t.ID,
-			"stage":     "claude_exit",
-			"exit_code": result.ExitCode,
-			"result":    truncate(result.Result, 500),
+		m.logEvent(ctx, payload.RecoveryFailed{
+			TaskID:   t.ID,
+			Stage:    "claude_exit",
+			ExitCode: result.ExitCode,
+			Result:   truncate(result.Result, 500),
 		}, recoveryCauses)
 		return false
 	}
@@ -100,19 +99,19 @@ func (m *Mind) attemptRecovery(ctx context.Context, t *task.Task, errorType, rea
 	// Verify the fix compiles and tests pass.
 	if err := BuildAndTest(ctx, m.repoDir); err != nil {
 		log.Printf("mind: recovery build/test failed for task %s: %v", t.ID, err)
-		m.logEvent(ctx, "mind.recovery.failed", map[string]any{
-			"task_id": t.ID,
-			"stage":   "build_test",
-			"error":   truncate(err.Error(), 500),
+		m.logEvent(ctx, payload.RecoveryFailed{
+			TaskID: t.ID,
+			Stage:  "build_test",
+			Error:  truncate(err.Error(), 500),
 		}, recoveryCauses)
 		return false
 	}
 
 	log.Printf("mind: recovery succeeded for task %s", t.ID)
-	m.logEvent(ctx, "mind.recovery.succeeded", map[string]any{
-		"task_id":    t.ID,
-		"error_type": errorType,
-		"result":     truncate(result.Result, 500),
+	m.logEvent(ctx, payload.RecoverySucceeded{
+		TaskID:    t.ID,
+		ErrorType: errorType,
+		Result:    truncate(result.Result, 500),
 	}, recoveryCauses)
 
 	return true
@@ -191,39 +190,30 @@ func (m *Mind) handleFailure(ctx context.Context, t *task.Task, errorType, reaso
 
 // requeueAfterRecovery resets a task to pending so the mind picks it up again.
 func (m *Mind) requeueAfterRecovery(ctx context.Context, t *task.Task, causes []string) {
-	meta := copyMeta(t.Metadata)
+	tm := payload.ReadTaskMeta(t.Metadata)
 	// Clear recovery flag so next attempt starts fresh.
-	delete(meta, "recovery_attempted")
+	tm.RecoveryAttempted = false
 	// Preserve retry_count — recovery doesn't count as a retry.
-	meta["recovered"] = true
-	meta["recovered_at"] = time.Now().Format(time.RFC3339)
+	tm.Recovered = true
+	tm.RecoveredAt = time.Now().Format(time.RFC3339)
 
 	if _, err := m.tasks.Update(ctx, t.ID, map[string]any{
 		"status":   "pending",
 		"assignee": "",
-		"metadata": meta,
+		"metadata": tm.Merge(t.Metadata),
 	}); err != nil {
 		log.Printf("mind: requeue after recovery %s: %v", t.ID, err)
-		m.logEvent(ctx, "mind.error", map[string]any{
-			"operation": "requeue_after_recovery",
-			"task_id":   t.ID,
-			"error":     err.Error(),
+		m.logEvent(ctx, payload.MindError{
+			Operation: "requeue_after_recovery",
+			TaskID:    t.ID,
+			Error:     err.Error(),
 		}, causes)
 	}
 
-	m.logEvent(ctx, "task.recovered", map[string]any{
-		"task_id": t.ID,
-		"subject": t.Subject,
+	m.logEvent(ctx, payload.TaskRecovered{
+		TaskID:  t.ID,
+		Subject: t.Subject,
 	}, causes)
 
 	log.Printf("mind: task %s requeued after recovery", t.ID)
 }
-
-// copyMeta creates a shallow copy of task metadata, or an empty map if nil.
-func copyMeta(meta map[string]any) map[string]any {
-	m := make(map[string]any)
-	for k, v := range meta {
-		m[k] = v
-	}
-	return m
-}
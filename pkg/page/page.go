@@ -0,0 +1,110 @@
+// Package page implements keyset pagination over listings ordered by
+// (timestamp, id), with opaque cursors clients pass back unchanged.
+package page
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned (wrapped) for a cursor that doesn't decode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one page of a listing, in listing order, with cursors for the
+// pages either side of it. A cursor is empty when there is nothing that way.
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// Cursor is a decoded position: the (Time, ID) key of the row a page
+// starts beyond. The zero Cursor is the start of the listing.
+type Cursor struct {
+	Time time.Time
+	ID   string
+	Back bool // read toward the start of the listing, as from a Prev cursor
+}
+
+// String encodes c as an opaque cursor.
+func (c Cursor) String() string {
+	dir := "n"
+	if c.Back {
+		dir = "p"
+	}
+	raw := dir + strconv.FormatInt(c.Time.UnixMicro(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// IsZero reports whether c is the start of the listing.
+func (c Cursor) IsZero() bool {
+	return c.ID == ""
+}
+
+// Parse decodes a cursor from String. An empty string is the zero Cursor.
+func Parse(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(raw) < 2 || (raw[0] != 'n' && raw[0] != 'p') {
+		return Cursor{}, fmt.Errorf("cursor %q: %w", s, ErrInvalidCursor)
+	}
+	micros, id, ok := strings.Cut(string(raw[1:]), ":")
+	n, err := strconv.ParseInt(micros, 10, 64)
+	if !ok || err != nil || id == "" {
+		return Cursor{}, fmt.Errorf("cursor %q: %w", s, ErrInvalidCursor)
+	}
+	return Cursor{Time: time.UnixMicro(n), ID: id, Back: raw[0] == 'p'}, nil
+}
+
+// Keyset returns how to read the rows after c from a listing sorted newest
+// first (desc) or oldest first: the operator to compare (timestamp, id)
+// against c's key, and the direction to sort them in. Fetch limit+1 rows
+// that way and hand them to Build.
+func (c Cursor) Keyset(desc bool) (cmp, order string) {
+	if desc != c.Back {
+		return "<", "DESC"
+	}
+	return ">", "ASC"
+}
+
+// Build makes a page from rows read with Keyset: up to limit+1 of them, the
+// extra one only showing that there are more. key returns a row's
+// (timestamp, id).
+func Build[T any](rows []T, limit int, c Cursor, key func(*T) (time.Time, string)) *Page[T] {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if c.Back {
+		// Read backwards from the cursor; put them back in listing order.
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	p := &Page[T]{Items: rows}
+	if len(rows) == 0 {
+		return p
+	}
+	hasNext, hasPrev := more, !c.IsZero()
+	if c.Back {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		t, id := key(&rows[len(rows)-1])
+		p.Next = Cursor{Time: t, ID: id}.String()
+	}
+	if hasPrev {
+		t, id := key(&rows[0])
+		p.Prev = Cursor{Time: t, ID: id, Back: true}.String()
+	}
+	return p
+}
@@ -0,0 +1,58 @@
+package page
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type row struct {
+	at time.Time
+	id string
+}
+
+func rowKey(r *row) (time.Time, string) { return r.at, r.id }
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{Time: time.UnixMicro(1700000000123456), ID: "abc:def", Back: true}
+	got, err := Parse(c.String())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !got.Time.Equal(c.Time) || got.ID != c.ID || !got.Back {
+		t.Fatalf("round trip = %+v, want %+v", got, c)
+	}
+	if c, err := Parse(""); err != nil || !c.IsZero() {
+		t.Fatalf("empty cursor = %+v, %v", c, err)
+	}
+	for _, bad := range []string{"!!", "eDEyMzph", Cursor{ID: "x"}.String()[:3]} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	base := time.UnixMicro(1000)
+	r := func(n int) row { return row{base.Add(time.Duration(n) * time.Microsecond), string(rune('a' + n))} }
+
+	// First page of a newest-first listing: limit+1 rows means there's more.
+	p := Build([]row{r(5), r(4), r(3)}, 2, Cursor{}, rowKey)
+	if len(p.Items) != 2 || p.Items[1] != r(4) || p.Next == "" || p.Prev != "" {
+		t.Fatalf("first page = %+v", p)
+	}
+	next, _ := Parse(p.Next)
+	if next.ID != r(4).id || next.Back {
+		t.Fatalf("next cursor = %+v", next)
+	}
+
+	// Reading back from a Prev cursor fetches rows in reverse.
+	back := Build([]row{r(6), r(7)}, 2, Cursor{Time: r(5).at, ID: r(5).id, Back: true}, rowKey)
+	if back.Items[0] != r(7) || back.Items[1] != r(6) || back.Prev != "" || back.Next == "" {
+		t.Fatalf("previous page = %+v", back)
+	}
+
+	if empty := Build[row](nil, 2, next, rowKey); empty.Items == nil || empty.Next != "" || empty.Prev != "" {
+		t.Fatalf("empty page = %+v", empty)
+	}
+}
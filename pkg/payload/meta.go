@@ -0,0 +1,66 @@
+package payload
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// TaskMeta is the part of a task's metadata the mind keeps: how often the
+// task has been retried, why it last failed and whether recovery was tried.
+// Other metadata keys belong to whoever set them and are left alone.
+type TaskMeta struct {
+	RetryCount        int    `json:"retry_count"`
+	BlockedReason     string `json:"blocked_reason,omitempty"`
+	PrevFailureReason string `json:"prev_failure_reason,omitempty"`
+	RecoveryAttempted bool   `json:"recovery_attempted,omitempty"`
+	Recovered         bool   `json:"recovered,omitempty"`
+	RecoveredAt       string `json:"recovered_at,omitempty"` // RFC 3339
+}
+
+// taskMetaKeys are the metadata keys of TaskMeta's fields, in field order.
+var taskMetaKeys = func() []string {
+	t := reflect.TypeFor[TaskMeta]()
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i], _, _ = strings.Cut(t.Field(i).Tag.Get("json"), ",")
+	}
+	return keys
+}()
+
+// ReadTaskMeta reads the mind's fields from a task's metadata. A field that
+// is missing or holds a value of the wrong type is left zero.
+func ReadTaskMeta(meta map[string]any) TaskMeta {
+	var m TaskMeta
+	v := reflect.ValueOf(&m).Elem()
+	for i, key := range taskMetaKeys {
+		raw, err := json.Marshal(meta[key])
+		if err != nil {
+			continue
+		}
+		field := v.Field(i)
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			field.SetZero()
+		}
+	}
+	return m
+}
+
+// Merge returns a copy of meta with the mind's keys set from m. Keys of
+// fields m leaves zero are removed, except retry_count, which is always set.
+func (m TaskMeta) Merge(meta map[string]any) map[string]any {
+	out := maps.Clone(meta)
+	if out == nil {
+		out = map[string]any{}
+	}
+	v := reflect.ValueOf(m)
+	for i, key := range taskMetaKeys {
+		if field := v.Field(i); field.IsZero() && key != "retry_count" {
+			delete(out, key)
+		} else {
+			out[key] = field.Interface()
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+// Package payload defines typed content for the event types the mind and
+// the API emit, so producers and consumers agree on field names and types
+// instead of building and picking apart map[string]any by hand.
+package payload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"mind-zero-five/pkg/eventgraph"
+)
+
+// ErrWrongType is returned (wrapped) by Decode for an event of another type.
+var ErrWrongType = errors.New("wrong event type")
+
+// Payload is the content of one event type.
+type Payload interface {
+	// EventType is the type of the events this payload is the content of.
+	EventType() string
+}
+
+// Encode renders p as event content, in the form it has once stored.
+func Encode(p Payload) (map[string]any, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", p.EventType(), err)
+	}
+	var content map[string]any
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("encode %s: %w", p.EventType(), err)
+	}
+	return content, nil
+}
+
+// Decode reads e's content as a T. Fields missing from the content are left
+// zero; it fails with ErrWrongType if e isn't a T's event type.
+func Decode[T Payload](e *eventgraph.Event) (T, error) {
+	var p T
+	if e.Type != p.EventType() {
+		return p, fmt.Errorf("decode %s event %s as %s: %w", e.Type, e.ID, p.EventType(), ErrWrongType)
+	}
+	raw, err := json.Marshal(e.Content)
+	if err != nil {
+		return p, fmt.Errorf("decode %s event %s: %w", e.Type, e.ID, err)
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return p, fmt.Errorf("decode %s event %s: %w", e.Type, e.ID, err)
+	}
+	return p, nil
+}
+
+// Append encodes p and appends it to store as an event of p's type.
+func Append(ctx context.Context, store eventgraph.EventStore, source string, p Payload, causes []string, conversationID string, signer eventgraph.Signer) (*eventgraph.Event, error) {
+	content, err := Encode(p)
+	if err != nil {
+		return nil, err
+	}
+	return store.Append(ctx, p.EventType(), source, content, causes, conversationID, signer)
+}
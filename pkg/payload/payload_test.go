@@ -0,0 +1,208 @@
+package payload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"mind-zero-five/pkg/eventgraph"
+)
+
+// roundTrip appends p and decodes it back, checking nothing changed and that
+// p matches its registered schema, if its type has one.
+func roundTrip[T Payload](p T) func(*testing.T, eventgraph.EventStore) {
+	return func(t *testing.T, store eventgraph.EventStore) {
+		if typ, ok := eventgraph.DefaultRegistry.Lookup(p.EventType()); ok {
+			checkSchema(t, reflect.TypeFor[T](), typ)
+		}
+		e, err := Append(context.Background(), store, "tester", p, nil, "", nil)
+		if err != nil {
+			t.Fatalf("append %s: %v", p.EventType(), err)
+		}
+		if e.Type != p.EventType() {
+			t.Errorf("appended as %s, want %s", e.Type, p.EventType())
+		}
+		if errs, ok := e.Content[eventgraph.SchemaErrorsKey]; ok {
+			t.Errorf("%s doesn't match its schema: %v", e.Type, errs)
+		}
+		got, err := Decode[T](e)
+		if err != nil {
+			t.Fatalf("decode %s: %v", e.Type, err)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("%s round trip = %+v, want %+v", e.Type, got, p)
+		}
+	}
+}
+
+// samples has one filled-in value of every payload type.
+var samples = map[string]func(*testing.T, eventgraph.EventStore){
+	"TaskCreated":            roundTrip(TaskCreated{TaskID: "t1", Subject: "s", Source: "ui"}),
+	"TaskClaimed":            roundTrip(TaskClaimed{TaskID: "t1", Subject: "s"}),
+	"TaskCompleted":          roundTrip(TaskCompleted{TaskID: "t1", Subject: "s"}),
+	"TaskBlocked":            roundTrip(TaskBlocked{TaskID: "t1", Reason: "r"}),
+	"TaskRetried":            roundTrip(TaskRetried{TaskID: "t1", Subject: "s", RetryCount: 2}),
+	"TaskRecovered":          roundTrip(TaskRecovered{TaskID: "t1", Subject: "s"}),
+	"TaskStaleRecovered":     roundTrip(TaskStaleRecovered{TaskID: "t1", Subject: "s", StaleFor: "1h0m0s"}),
+	"AuthorityRequested":     roundTrip(AuthorityRequested{AuthorityID: "a1", Action: "restart", TaskID: "t1"}),
+	"AuthorityResolved":      roundTrip(AuthorityResolved{AuthorityID: "a1", Action: "restart", Approved: true}),
+	"AuthorityAutoApproved":  roundTrip(AuthorityAutoApproved{AuthorityID: "a1", Action: "self-improve", Timeout: "15m0s"}),
+	"AuthoritySelfApproved":  roundTrip(AuthoritySelfApproved{AuthorityID: "a1", PolicyID: "p1", Action: "restart"}),
+	"SelfImproveTaskCreated": roundTrip(SelfImproveTaskCreated{TaskID: "t1", AuthorityID: "a1", Subject: "s"}),
+	"SelfImproveRejected":    roundTrip(SelfImproveRejected{AuthorityID: "a1"}),
+	"ClaudeInvoked":          roundTrip(ClaudeInvoked{TaskID: "t1", Model: "m", Prompt: "p"}),
+	"ClaudeCompleted":        roundTrip(ClaudeCompleted{TaskID: "t1", ExitCode: 1, Duration: "2s", Result: "r"}),
+	"ClaudeFailed":           roundTrip(ClaudeFailed{TaskID: "t1", Error: "e"}),
+	"ClaudeRetry":            roundTrip(ClaudeRetry{TaskID: "t1"}),
+	"PlanStarted":            roundTrip(PlanStarted{TaskID: "t1", Subject: "s"}),
+	"PlanAlreadyDone":        roundTrip(PlanAlreadyDone{TaskID: "t1", Subject: "s"}),
+	"PlanFailed":             roundTrip(PlanFailed{TaskID: "t1", Error: "e"}),
+	"PlanCompleted":          roundTrip(PlanCompleted{TaskID: "t1", SubtaskCount: 3}),
+	"SubtaskStarted":         roundTrip(SubtaskStarted{TaskID: "t2", ParentID: "t1", Subject: "s"}),
+	"SubtaskCompleted":       roundTrip(SubtaskCompleted{TaskID: "t2", ParentID: "t1", Subject: "s"}),
+	"ReviewStarted":          roundTrip(ReviewStarted{TaskID: "t1", Round: 1}),
+	"ReviewFailed":           roundTrip(ReviewFailed{TaskID: "t1", Error: "e"}),
+	"ReviewCompleted":        roundTrip(ReviewCompleted{TaskID: "t1", Round: 1, IssueCount: 2}),
+	"AssessStarted":          roundTrip(AssessStarted{}),
+	"AssessFailed":           roundTrip(AssessFailed{Error: "e"}),
+	"AssessCompleted":        roundTrip(AssessCompleted{Result: "proposal", Subject: "s"}),
+	"RecoveryStarted":        roundTrip(RecoveryStarted{TaskID: "t1", ErrorType: "build.failed", Reason: "r"}),
+	"RecoveryFailed":         roundTrip(RecoveryFailed{TaskID: "t1", Stage: "claude_exit", ExitCode: 1, Result: "r"}),
+	"RecoverySucceeded":      roundTrip(RecoverySucceeded{TaskID: "t1", ErrorType: "build.failed", Result: "r"}),
+	"DirtyTreeCleaned":       roundTrip(DirtyTreeCleaned{FileCount: 1, Files: []string{"a.go"}}),
+	"DirtyTreeFailed":        roundTrip(DirtyTreeFailed{Error: "e", Files: []string{"a.go"}}),
+	"StateRecovered":         roundTrip(StateRecovered{RecoveredIDs: []string{"a1"}}),
+	"MindError":              roundTrip(MindError{Operation: "op", TaskID: "t1", Error: "e"}),
+	"MindPanic":              roundTrip(MindPanic{Error: "e"}),
+	"PreflightFailed":        roundTrip(PreflightFailed{Missing: []string{"go"}, Error: "e"}),
+	"PreflightRestored":      roundTrip(PreflightRestored{}),
+	"BuildFailed":            roundTrip(BuildFailed{TaskID: "t1", Error: "e"}),
+	"BuildCompleted":         roundTrip(BuildCompleted{TaskID: "t1"}),
+	"BuildDeployFailed":      roundTrip(BuildDeployFailed{TaskID: "t1", Error: "e"}),
+	"CodeCommitted":          roundTrip(CodeCommitted{TaskID: "t1", Message: "m"}),
+	"CommitPushFailed":       roundTrip(CommitPushFailed{TaskID: "t1", Error: "e"}),
+	"DeployStarted":          roundTrip(DeployStarted{AuthorityID: "a1"}),
+	"DeployFailed":           roundTrip(DeployFailed{AuthorityID: "a1", Error: "e"}),
+}
+
+// checkSchema fails unless the registered schema typ describes exactly the
+// fields of the payload struct st: the same names, with matching JSON types,
+// and only fields that are always encoded required.
+func checkSchema(t *testing.T, st reflect.Type, typ eventgraph.EventType) {
+	t.Helper()
+	var schema struct {
+		Required   []string
+		Properties map[string]struct{ Type string }
+	}
+	if err := json.Unmarshal(typ.Schema, &schema); err != nil {
+		t.Fatalf("%s schema: %v", typ.Name, err)
+	}
+	fields := map[string]bool{}
+	for i := range st.NumField() {
+		f := st.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fields[name] = true
+		prop, ok := schema.Properties[name]
+		if !ok {
+			t.Errorf("%s schema lacks %s.%s (%s)", typ.Name, st.Name(), f.Name, name)
+			continue
+		}
+		if want := jsonType(f.Type); prop.Type != want {
+			t.Errorf("%s schema: %s is %s, want %s", typ.Name, name, prop.Type, want)
+		}
+		if opts == "omitempty" && slices.Contains(schema.Required, name) {
+			t.Errorf("%s schema requires %s, which %s omits when empty", typ.Name, name, st.Name())
+		}
+	}
+	for name := range schema.Properties {
+		if !fields[name] {
+			t.Errorf("%s schema has %s, which %s lacks", typ.Name, name, st.Name())
+		}
+	}
+	for _, name := range schema.Required {
+		if !fields[name] {
+			t.Errorf("%s schema requires %s, which %s lacks", typ.Name, name, st.Name())
+		}
+	}
+}
+
+// jsonType is the JSON Schema type a field of type t encodes as.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "array"
+	}
+	return t.Kind().String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	store := eventgraph.NewMemStore(nil, eventgraph.CausesStrict)
+	for name, check := range samples {
+		t.Run(name, func(t *testing.T) { check(t, store) })
+	}
+	types, _ := store.DistinctTypes(context.Background())
+	if len(types) != len(samples) {
+		t.Errorf("%d samples appended %d distinct types; two payloads share a type", len(samples), len(types))
+	}
+	// Every built-in schema describes a payload here, so checkSchema saw it.
+	for _, typ := range eventgraph.DefaultRegistry.Types() {
+		if typ.Name != eventgraph.EventRedacted && !slices.Contains(types, typ.Name) {
+			t.Errorf("registered type %s has no payload", typ.Name)
+		}
+	}
+}
+
+func TestDecodeWrongType(t *testing.T) {
+	store := eventgraph.NewMemStore(nil, eventgraph.CausesStrict)
+	e, err := Append(context.Background(), store, "tester", TaskClaimed{TaskID: "t1"}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := Decode[TaskCompleted](e); !errors.Is(err, ErrWrongType) {
+		t.Errorf("decode as TaskCompleted: err = %v, want ErrWrongType", err)
+	}
+}
+
+func TestDecodeNumbers(t *testing.T) {
+	// Content read back from a store holds numbers as float64.
+	e := &eventgraph.Event{Type: "task.retried", Content: map[string]any{"task_id": "t1", "retry_count": float64(3)}}
+	p, err := Decode[TaskRetried](e)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if p.RetryCount != 3 || p.TaskID != "t1" {
+		t.Errorf("decoded %+v", p)
+	}
+}
+
+func TestTaskMeta(t *testing.T) {
+	// Metadata read back from a store holds numbers as float64.
+	stored := map[string]any{"retry_count": float64(2), "blocked_reason": "boom", "recovered": "yes", "model": "m"}
+	m := ReadTaskMeta(stored)
+	if want := (TaskMeta{RetryCount: 2, BlockedReason: "boom"}); m != want {
+		t.Errorf("read %+v, want %+v", m, want)
+	}
+
+	m.BlockedReason, m.PrevFailureReason = "", m.BlockedReason
+	got := m.Merge(stored)
+	want := map[string]any{"retry_count": 2, "prev_failure_reason": "boom", "model": "m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged %v, want %v", got, want)
+	}
+	if stored["blocked_reason"] != "boom" {
+		t.Error("merge modified the metadata it was given")
+	}
+	if got := (TaskMeta{}).Merge(nil); !reflect.DeepEqual(got, map[string]any{"retry_count": 0}) {
+		t.Errorf("merge into nil = %v", got)
+	}
+}
@@ -0,0 +1,360 @@
+package payload
+
+// Tasks.
+
+// TaskCreated is the content of task.created.
+type TaskCreated struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+	Source  string `json:"source,omitempty"`
+}
+
+// TaskClaimed is the content of task.claimed.
+type TaskClaimed struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+}
+
+// TaskCompleted is the content of task.completed.
+type TaskCompleted struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+}
+
+// TaskBlocked is the content of task.blocked.
+type TaskBlocked struct {
+	TaskID string `json:"task_id"`
+	Reason string `json:"reason"`
+}
+
+// TaskRetried is the content of task.retried.
+type TaskRetried struct {
+	TaskID     string `json:"task_id"`
+	Subject    string `json:"subject"`
+	RetryCount int    `json:"retry_count"`
+}
+
+// TaskRecovered is the content of task.recovered.
+type TaskRecovered struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+}
+
+// TaskStaleRecovered is the content of task.stale.recovered.
+type TaskStaleRecovered struct {
+	TaskID   string `json:"task_id"`
+	Subject  string `json:"subject"`
+	StaleFor string `json:"stale_for"` // a time.Duration's String
+}
+
+func (TaskCreated) EventType() string        { return "task.created" }
+func (TaskClaimed) EventType() string        { return "task.claimed" }
+func (TaskCompleted) EventType() string      { return "task.completed" }
+func (TaskBlocked) EventType() string        { return "task.blocked" }
+func (TaskRetried) EventType() string        { return "task.retried" }
+func (TaskRecovered) EventType() string      { return "task.recovered" }
+func (TaskStaleRecovered) EventType() string { return "task.stale.recovered" }
+
+// Authority.
+
+// AuthorityRequested is the content of authority.requested.
+type AuthorityRequested struct {
+	AuthorityID string `json:"authority_id"`
+	Action      string `json:"action"`
+	TaskID      string `json:"task_id,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+}
+
+// AuthorityResolved is the content of authority.resolved.
+type AuthorityResolved struct {
+	AuthorityID string `json:"authority_id"`
+	Action      string `json:"action"`
+	Approved    bool   `json:"approved"`
+}
+
+// AuthorityAutoApproved is the content of authority.auto_approved.
+type AuthorityAutoApproved struct {
+	AuthorityID string `json:"authority_id"`
+	Action      string `json:"action"`
+	Timeout     string `json:"timeout"` // a time.Duration's String
+}
+
+// AuthoritySelfApproved is the content of authority.self_approved.
+type AuthoritySelfApproved struct {
+	AuthorityID string `json:"authority_id"`
+	PolicyID    string `json:"policy_id"`
+	Action      string `json:"action"`
+}
+
+// SelfImproveTaskCreated is the content of self-improve.task.created.
+type SelfImproveTaskCreated struct {
+	TaskID      string `json:"task_id"`
+	AuthorityID string `json:"authority_id"`
+	Subject     string `json:"subject"`
+}
+
+// SelfImproveRejected is the content of self-improve.rejected.
+type SelfImproveRejected struct {
+	AuthorityID string `json:"authority_id"`
+}
+
+func (AuthorityRequested) EventType() string     { return "authority.requested" }
+func (AuthorityResolved) EventType() string      { return "authority.resolved" }
+func (AuthorityAutoApproved) EventType() string  { return "authority.auto_approved" }
+func (AuthoritySelfApproved) EventType() string  { return "authority.self_approved" }
+func (SelfImproveTaskCreated) EventType() string { return "self-improve.task.created" }
+func (SelfImproveRejected) EventType() string    { return "self-improve.rejected" }
+
+// Claude invocations.
+
+// ClaudeInvoked is the content of mind.claude.invoked.
+type ClaudeInvoked struct {
+	TaskID string `json:"task_id"`
+	Model  string `json:"model,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	Prompt string `json:"prompt"` // truncated
+}
+
+// ClaudeCompleted is the content of mind.claude.completed.
+type ClaudeCompleted struct {
+	TaskID   string `json:"task_id"`
+	ExitCode int    `json:"exit_code"`
+	Duration string `json:"duration"` // a time.Duration's String
+	Result   string `json:"result"`   // truncated
+}
+
+// ClaudeFailed is the content of mind.claude.failed.
+type ClaudeFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// ClaudeRetry is the content of mind.claude.retry.
+type ClaudeRetry struct {
+	TaskID string `json:"task_id"`
+}
+
+func (ClaudeInvoked) EventType() string   { return "mind.claude.invoked" }
+func (ClaudeCompleted) EventType() string { return "mind.claude.completed" }
+func (ClaudeFailed) EventType() string    { return "mind.claude.failed" }
+func (ClaudeRetry) EventType() string     { return "mind.claude.retry" }
+
+// Planning, subtasks and review.
+
+// PlanStarted is the content of mind.plan.started.
+type PlanStarted struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+}
+
+// PlanAlreadyDone is the content of mind.plan.already_done.
+type PlanAlreadyDone struct {
+	TaskID  string `json:"task_id"`
+	Subject string `json:"subject"`
+}
+
+// PlanFailed is the content of mind.plan.failed.
+type PlanFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// PlanCompleted is the content of mind.plan.completed.
+type PlanCompleted struct {
+	TaskID       string `json:"task_id"`
+	SubtaskCount int    `json:"subtask_count"`
+}
+
+// SubtaskStarted is the content of mind.subtask.started.
+type SubtaskStarted struct {
+	TaskID   string `json:"task_id"`
+	ParentID string `json:"parent_id"`
+	Subject  string `json:"subject"`
+}
+
+// SubtaskCompleted is the content of mind.subtask.completed.
+type SubtaskCompleted struct {
+	TaskID   string `json:"task_id"`
+	ParentID string `json:"parent_id"`
+	Subject  string `json:"subject"`
+}
+
+// ReviewStarted is the content of mind.review.started.
+type ReviewStarted struct {
+	TaskID string `json:"task_id"`
+	Round  int    `json:"round"` // from 1
+}
+
+// ReviewFailed is the content of mind.review.failed.
+type ReviewFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// ReviewCompleted is the content of mind.review.completed.
+type ReviewCompleted struct {
+	TaskID     string `json:"task_id"`
+	Round      int    `json:"round"` // from 1
+	IssueCount int    `json:"issue_count"`
+	Clean      bool   `json:"clean"`
+}
+
+func (PlanStarted) EventType() string      { return "mind.plan.started" }
+func (PlanAlreadyDone) EventType() string  { return "mind.plan.already_done" }
+func (PlanFailed) EventType() string       { return "mind.plan.failed" }
+func (PlanCompleted) EventType() string    { return "mind.plan.completed" }
+func (SubtaskStarted) EventType() string   { return "mind.subtask.started" }
+func (SubtaskCompleted) EventType() string { return "mind.subtask.completed" }
+func (ReviewStarted) EventType() string    { return "mind.review.started" }
+func (ReviewFailed) EventType() string     { return "mind.review.failed" }
+func (ReviewCompleted) EventType() string  { return "mind.review.completed" }
+
+// Self-assessment.
+
+// AssessStarted is the content of mind.assess.started.
+type AssessStarted struct{}
+
+// AssessFailed is the content of mind.assess.failed.
+type AssessFailed struct {
+	Error string `json:"error"`
+}
+
+// AssessCompleted is the content of mind.assess.completed. Result is "ok"
+// when nothing needs improving, or "proposal" with the proposal's Subject.
+type AssessCompleted struct {
+	Result  string `json:"result"`
+	Subject string `json:"subject,omitempty"`
+}
+
+func (AssessStarted) EventType() string   { return "mind.assess.started" }
+func (AssessFailed) EventType() string    { return "mind.assess.failed" }
+func (AssessCompleted) EventType() string { return "mind.assess.completed" }
+
+// Recovery.
+
+// RecoveryStarted is the content of mind.recovery.started.
+type RecoveryStarted struct {
+	TaskID    string `json:"task_id"`
+	ErrorType string `json:"error_type"`
+	Reason    string `json:"reason"` // truncated
+}
+
+// RecoveryFailed is the content of mind.recovery.failed. Stage is where it
+// failed: "invocation", "claude_exit" or "build_test".
+type RecoveryFailed struct {
+	TaskID   string `json:"task_id"`
+	Stage    string `json:"stage"`
+	Error    string `json:"error,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	Result   string `json:"result,omitempty"` // truncated
+}
+
+// RecoverySucceeded is the content of mind.recovery.succeeded.
+type RecoverySucceeded struct {
+	TaskID    string `json:"task_id"`
+	ErrorType string `json:"error_type"`
+	Result    string `json:"result"` // truncated
+}
+
+// DirtyTreeCleaned is the content of mind.recovery.dirty_tree_cleaned.
+type DirtyTreeCleaned struct {
+	FileCount int      `json:"file_count"`
+	Files     []string `json:"files"`
+}
+
+// DirtyTreeFailed is the content of mind.recovery.dirty_tree_failed.
+type DirtyTreeFailed struct {
+	Error string   `json:"error"`
+	Files []string `json:"files"`
+}
+
+// StateRecovered is the content of mind.state.recovered.
+type StateRecovered struct {
+	RecoveredIDs []string `json:"recovered_ids"` // authority request IDs
+}
+
+func (RecoveryStarted) EventType() string   { return "mind.recovery.started" }
+func (RecoveryFailed) EventType() string    { return "mind.recovery.failed" }
+func (RecoverySucceeded) EventType() string { return "mind.recovery.succeeded" }
+func (DirtyTreeCleaned) EventType() string  { return "mind.recovery.dirty_tree_cleaned" }
+func (DirtyTreeFailed) EventType() string   { return "mind.recovery.dirty_tree_failed" }
+func (StateRecovered) EventType() string    { return "mind.state.recovered" }
+
+// The mind's health.
+
+// MindError is the content of mind.error. Operation names what failed.
+type MindError struct {
+	Operation   string `json:"operation"`
+	TaskID      string `json:"task_id,omitempty"`
+	AuthorityID string `json:"authority_id,omitempty"`
+	Error       string `json:"error"`
+}
+
+// MindPanic is the content of mind.panic.
+type MindPanic struct {
+	Error string `json:"error"`
+}
+
+// PreflightFailed is the content of mind.preflight.failed.
+type PreflightFailed struct {
+	Missing []string `json:"missing"` // binaries not on PATH
+	Error   string   `json:"error"`
+}
+
+// PreflightRestored is the content of mind.preflight.restored.
+type PreflightRestored struct{}
+
+func (MindError) EventType() string         { return "mind.error" }
+func (MindPanic) EventType() string         { return "mind.panic" }
+func (PreflightFailed) EventType() string   { return "mind.preflight.failed" }
+func (PreflightRestored) EventType() string { return "mind.preflight.restored" }
+
+// Building, committing and deploying.
+
+// BuildFailed is the content of build.failed.
+type BuildFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"` // truncated
+}
+
+// BuildCompleted is the content of build.completed.
+type BuildCompleted struct {
+	TaskID string `json:"task_id"`
+}
+
+// BuildDeployFailed is the content of build.deploy.failed.
+type BuildDeployFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"` // truncated
+}
+
+// CodeCommitted is the content of code.committed.
+type CodeCommitted struct {
+	TaskID  string `json:"task_id"`
+	Message string `json:"message"`
+}
+
+// CommitPushFailed is the content of git.commit_push.failed.
+type CommitPushFailed struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"` // truncated
+}
+
+// DeployStarted is the content of deploy.started.
+type DeployStarted struct {
+	AuthorityID string `json:"authority_id"`
+}
+
+// DeployFailed is the content of deploy.failed.
+type DeployFailed struct {
+	AuthorityID string `json:"authority_id"`
+	Error       string `json:"error"`
+}
+
+func (BuildFailed) EventType() string       { return "build.failed" }
+func (BuildCompleted) EventType() string    { return "build.completed" }
+func (BuildDeployFailed) EventType() string { return "build.deploy.failed" }
+func (CodeCommitted) EventType() string     { return "code.committed" }
+func (CommitPushFailed) EventType() string  { return "git.commit_push.failed" }
+func (DeployStarted) EventType() string     { return "deploy.started" }
+func (DeployFailed) EventType() string      { return "deploy.failed" }
@@ -7,12 +7,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mind-zero-five/pkg/page"
 )
 
 // PgStore is a PostgreSQL-backed task store.
 type PgStore struct {
-	pool *pgxpool.Pool
+	pool pgConn
 }
 
 // NewPgStore creates a PgStore.
@@ -20,33 +24,20 @@ func NewPgStore(pool *pgxpool.Pool) *PgStore {
 	return &PgStore{pool: pool}
 }
 
-// EnsureTable creates the tasks table if it doesn't exist.
-func (s *PgStore) EnsureTable(ctx context.Context) error {
-	_, err := s.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS tasks (
-			id           TEXT PRIMARY KEY,
-			subject      TEXT NOT NULL,
-			description  TEXT NOT NULL DEFAULT '',
-			status       TEXT NOT NULL DEFAULT 'pending',
-			priority     INTEGER NOT NULL DEFAULT 0,
-			source       TEXT NOT NULL DEFAULT '',
-			assignee     TEXT NOT NULL DEFAULT '',
-			parent_id    TEXT NOT NULL DEFAULT '',
-			blocked_by   TEXT[] DEFAULT '{}',
-			metadata     JSONB NOT NULL DEFAULT '{}',
-			created_at   TIMESTAMPTZ DEFAULT NOW(),
-			updated_at   TIMESTAMPTZ DEFAULT NOW(),
-			completed_at TIMESTAMPTZ
-		)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status)`)
-	if err != nil {
-		return err
-	}
-	_, err = s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id) WHERE parent_id != ''`)
-	return err
+// NewPgStoreTx creates a PgStore whose statements all run in tx, so its
+// writes commit or roll back with the caller's. It is only usable until tx
+// ends.
+func NewPgStoreTx(tx pgx.Tx) *PgStore {
+	return &PgStore{pool: tx}
+}
+
+// pgConn is the part of *pgxpool.Pool the store uses. pgx.Tx has it too, so
+// a store can run inside a caller's transaction (see NewPgStoreTx).
+type pgConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 // Create inserts a new task.
@@ -201,6 +192,37 @@ func (s *PgStore) List(ctx context.Context, status string, limit int) ([]Task, e
 	return scanTaskRows(rows)
 }
 
+// ListPage returns a page of tasks, newest first.
+func (s *PgStore) ListPage(ctx context.Context, status, cursor string, limit int) (*page.Page[Task], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	where, args := "TRUE", []any{}
+	if status != "" {
+		args = append(args, status)
+		where = fmt.Sprintf("status = $%d", len(args))
+	}
+	cmp, order := c.Keyset(true)
+	if !c.IsZero() {
+		args = append(args, c.Time, c.ID)
+		where += fmt.Sprintf(" AND (created_at, id) %s ($%d, $%d)", cmp, len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, subject, description, status, priority, source, assignee, parent_id, blocked_by, metadata, created_at, updated_at, completed_at
+		FROM tasks WHERE `+where+fmt.Sprintf(` ORDER BY created_at %s, id %s LIMIT $%d`, order, order, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+	tasks, err := scanTaskRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return page.Build(tasks, limit, c, pageKey), nil
+}
+
 // ByParent returns all subtasks of a parent task.
 func (s *PgStore) ByParent(ctx context.Context, parentID string) ([]Task, error) {
 	rows, err := s.pool.Query(ctx, `
@@ -227,6 +249,22 @@ func (s *PgStore) PendingCount(ctx context.Context) (int, error) {
 	return n, err
 }
 
+// Import inserts a task as given.
+func (s *PgStore) Import(ctx context.Context, t *Task) error {
+	metaJSON, err := json.Marshal(t.Metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO tasks (id, subject, description, status, priority, source, assignee, parent_id, blocked_by, metadata, created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11, $12, $13)`,
+		t.ID, t.Subject, t.Description, t.Status, t.Priority, t.Source, t.Assignee, t.ParentID, nonNil(t.BlockedBy), string(metaJSON), t.CreatedAt, t.UpdatedAt, t.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("import task %s: %w", t.ID, err)
+	}
+	return nil
+}
+
 func scanTaskRows(rows interface {
 	Next() bool
 	Scan(dest ...any) error
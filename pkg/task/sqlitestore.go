@@ -0,0 +1,263 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mind-zero-five/pkg/page"
+)
+
+// SQLiteStore is a SQLite-backed task store. Timestamps are stored as Unix
+// microseconds; blocked_by and metadata as JSON text.
+type SQLiteStore struct {
+	db sqlConn
+}
+
+// NewSQLiteStore creates a SQLiteStore. The caller registers the driver.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// NewSQLiteStoreTx creates a SQLiteStore whose statements all run in tx, so
+// its writes commit or roll back with the caller's. It is only usable until
+// tx ends.
+func NewSQLiteStoreTx(tx *sql.Tx) *SQLiteStore {
+	return &SQLiteStore{db: tx}
+}
+
+// sqlConn is the part of *sql.DB the store uses. *sql.Tx has it too, so a
+// store can run inside a caller's transaction (see NewSQLiteStoreTx).
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const sqliteTaskColumns = `id, subject, description, status, priority, source, assignee, parent_id, blocked_by, metadata, created_at, updated_at, completed_at`
+
+// Create inserts a new task.
+func (s *SQLiteStore) Create(ctx context.Context, t *Task) (*Task, error) {
+	t.ID = uuid.Must(uuid.NewV7()).String()
+	now := time.Now().Truncate(time.Microsecond)
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	if t.Status == "" {
+		t.Status = "pending"
+	}
+	if t.BlockedBy == nil {
+		t.BlockedBy = []string{}
+	}
+	if t.Metadata == nil {
+		t.Metadata = map[string]any{}
+	}
+
+	metaJSON, err := json.Marshal(t.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("marshal metadata: %w", err)
+	}
+	blockedJSON, err := json.Marshal(t.BlockedBy)
+	if err != nil {
+		return nil, fmt.Errorf("marshal blocked_by: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tasks (id, subject, description, status, priority, source, assignee, parent_id, blocked_by, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Subject, t.Description, t.Status, t.Priority, t.Source, t.Assignee, t.ParentID, string(blockedJSON), string(metaJSON), t.CreatedAt.UnixMicro(), t.UpdatedAt.UnixMicro())
+	if err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	return t, nil
+}
+
+// Get retrieves a single task by ID.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Task, error) {
+	t, err := scanSQLiteTask(s.db.QueryRowContext(ctx, `SELECT `+sqliteTaskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("get task %s: %w", id, err)
+	}
+	return t, nil
+}
+
+// Update modifies task fields. Supported keys: status, subject, description, assignee, priority, blocked_by, metadata.
+func (s *SQLiteStore) Update(ctx context.Context, id string, updates map[string]any) (*Task, error) {
+	now := time.Now().Truncate(time.Microsecond)
+
+	setClauses := "updated_at = ?"
+	args := []any{now.UnixMicro()}
+
+	for k, v := range updates {
+		switch k {
+		case "status", "subject", "description", "assignee", "priority":
+			setClauses += fmt.Sprintf(", %s = ?", k)
+			args = append(args, v)
+		case "blocked_by", "metadata":
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s: %w", k, err)
+			}
+			setClauses += fmt.Sprintf(", %s = ?", k)
+			args = append(args, string(raw))
+		}
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE tasks SET %s WHERE id = ? RETURNING %s", setClauses, sqliteTaskColumns)
+
+	t, err := scanSQLiteTask(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("update task %s: %w", id, err)
+	}
+	return t, nil
+}
+
+// Complete marks a task as completed.
+func (s *SQLiteStore) Complete(ctx context.Context, id string) (*Task, error) {
+	now := time.Now().Truncate(time.Microsecond).UnixMicro()
+	t, err := scanSQLiteTask(s.db.QueryRowContext(ctx, `
+		UPDATE tasks SET status = 'completed', updated_at = ?1, completed_at = ?1
+		WHERE id = ?2
+		RETURNING `+sqliteTaskColumns, now, id))
+	if err != nil {
+		return nil, fmt.Errorf("complete task %s: %w", id, err)
+	}
+	return t, nil
+}
+
+// List returns tasks filtered by status (empty = all), ordered by priority desc then created_at asc.
+func (s *SQLiteStore) List(ctx context.Context, status string, limit int) ([]Task, error) {
+	var rows *sql.Rows
+	var err error
+	if status != "" {
+		rows, err = s.db.QueryContext(ctx, `SELECT `+sqliteTaskColumns+`
+			FROM tasks WHERE status = ? ORDER BY priority DESC, created_at ASC LIMIT ?`, status, limit)
+	} else {
+		rows, err = s.db.QueryContext(ctx, `SELECT `+sqliteTaskColumns+`
+			FROM tasks ORDER BY priority DESC, created_at ASC LIMIT ?`, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+	return scanSQLiteTaskRows(rows)
+}
+
+// ListPage returns a page of tasks, newest first.
+func (s *SQLiteStore) ListPage(ctx context.Context, status, cursor string, limit int) (*page.Page[Task], error) {
+	c, err := page.Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+	where, args := "1 = 1", []any{}
+	if status != "" {
+		where, args = "status = ?", append(args, status)
+	}
+	cmp, order := c.Keyset(true)
+	if !c.IsZero() {
+		where += " AND (created_at, id) " + cmp + " (?, ?)"
+		args = append(args, c.Time.UnixMicro(), c.ID)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sqliteTaskColumns+`
+		FROM tasks WHERE `+where+` ORDER BY created_at `+order+`, id `+order+` LIMIT ?`, append(args, limit+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+	tasks, err := scanSQLiteTaskRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return page.Build(tasks, limit, c, pageKey), nil
+}
+
+// ByParent returns all subtasks of a parent task.
+func (s *SQLiteStore) ByParent(ctx context.Context, parentID string) ([]Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sqliteTaskColumns+`
+		FROM tasks WHERE parent_id = ? ORDER BY priority DESC, created_at ASC`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("tasks by parent: %w", err)
+	}
+	defer rows.Close()
+	return scanSQLiteTaskRows(rows)
+}
+
+// Count returns total task count.
+func (s *SQLiteStore) Count(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks`).Scan(&n)
+	return n, err
+}
+
+// PendingCount returns count of pending tasks.
+func (s *SQLiteStore) PendingCount(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE status = 'pending'`).Scan(&n)
+	return n, err
+}
+
+// Import inserts a task as given.
+func (s *SQLiteStore) Import(ctx context.Context, t *Task) error {
+	metaJSON, err := json.Marshal(t.Metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	blockedJSON, err := json.Marshal(nonNil(t.BlockedBy))
+	if err != nil {
+		return fmt.Errorf("marshal blocked_by: %w", err)
+	}
+	var completed sql.NullInt64
+	if t.CompletedAt != nil {
+		completed = sql.NullInt64{Int64: t.CompletedAt.UnixMicro(), Valid: true}
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tasks (`+sqliteTaskColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Subject, t.Description, t.Status, t.Priority, t.Source, t.Assignee, t.ParentID, string(blockedJSON), string(metaJSON), t.CreatedAt.UnixMicro(), t.UpdatedAt.UnixMicro(), completed)
+	if err != nil {
+		return fmt.Errorf("import task %s: %w", t.ID, err)
+	}
+	return nil
+}
+
+func scanSQLiteTask(row interface{ Scan(dest ...any) error }) (*Task, error) {
+	var t Task
+	var blockedJSON, metaJSON string
+	var created, updated int64
+	var completed sql.NullInt64
+	if err := row.Scan(&t.ID, &t.Subject, &t.Description, &t.Status, &t.Priority, &t.Source, &t.Assignee, &t.ParentID, &blockedJSON, &metaJSON, &created, &updated, &completed); err != nil {
+		return nil, err
+	}
+	t.CreatedAt = time.UnixMicro(created)
+	t.UpdatedAt = time.UnixMicro(updated)
+	if completed.Valid {
+		c := time.UnixMicro(completed.Int64)
+		t.CompletedAt = &c
+	}
+	if err := json.Unmarshal([]byte(blockedJSON), &t.BlockedBy); err != nil {
+		t.BlockedBy = []string{}
+	}
+	if err := json.Unmarshal([]byte(metaJSON), &t.Metadata); err != nil {
+		t.Metadata = map[string]any{}
+	}
+	return &t, nil
+}
+
+func scanSQLiteTaskRows(rows *sql.Rows) ([]Task, error) {
+	var tasks []Task
+	for rows.Next() {
+		t, err := scanSQLiteTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration: %w", err)
+	}
+	return tasks, nil
+}
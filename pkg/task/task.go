@@ -3,6 +3,8 @@ package task
 import (
 	"context"
 	"time"
+
+	"mind-zero-five/pkg/page"
 )
 
 // Task represents a unit of work in the system.
@@ -29,8 +31,27 @@ type Store interface {
 	Update(ctx context.Context, id string, updates map[string]any) (*Task, error)
 	Complete(ctx context.Context, id string) (*Task, error)
 	List(ctx context.Context, status string, limit int) ([]Task, error)
+	// ListPage pages through tasks newest first, optionally by status,
+	// starting from a cursor of a previous page ("" for the first).
+	ListPage(ctx context.Context, status, cursor string, limit int) (*page.Page[Task], error)
 	ByParent(ctx context.Context, parentID string) ([]Task, error)
 	Count(ctx context.Context) (int, error)
 	PendingCount(ctx context.Context) (int, error)
-	EnsureTable(ctx context.Context) error
+	// Import inserts t exactly as given, ID and timestamps included, for
+	// moving tasks between databases.
+	Import(ctx context.Context, t *Task) error
+}
+
+// nonNil returns ids, or an empty slice if it is nil, since blocked_by is
+// never null.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
+
+// pageKey is a task's position in ListPage order.
+func pageKey(t *Task) (time.Time, string) {
+	return t.CreatedAt, t.ID
 }